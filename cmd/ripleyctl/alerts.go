@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// alertsCmd shows the state of every alert the daemon tracks and what it
+// last notified about each, or evaluates the alert rules now.
+func alertsCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("alerts", flag.ExitOnError)
+	firing := fs.Bool("firing", false, "only show alerts that are firing or flapping")
+	check := fs.Bool("check", false, "evaluate the alert rules against the stored results, without notifying")
+	asJSON := fs.Bool("json", false, "print the alerts as JSON")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("alerts takes no arguments")
+	}
+	if *check && *asJSON {
+		return fmt.Errorf("-json cannot be combined with -check")
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if *check {
+		return checkRules(cfg, db, *firing)
+	}
+
+	all, err := db.ListAlerts(cfg.Claude.Model)
+	if err != nil {
+		return err
+	}
+	var alerts []storage.Alert
+	for _, a := range all {
+		if !*firing || a.State == storage.AlertFiring || a.Flapping {
+			alerts = append(alerts, a)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(alerts)
+	}
+
+	if len(alerts) == 0 {
+		fmt.Println("No alerts; the daemon evaluates them after each cycle.")
+		return nil
+	}
+
+	const timeFormat = "2006-01-02 15:04"
+	fmt.Printf("%-22s %-20s %-9s %-17s %s\n", "Rule", "Benchmark", "State", "Since", "Last notified")
+	for _, a := range alerts {
+		state := a.State
+		if a.Flapping {
+			state = "flapping"
+		}
+		benchmark := a.Benchmark
+		if benchmark == "" {
+			benchmark = "-"
+		}
+		notified := "never"
+		if !a.NotifiedAt.IsZero() {
+			notified = fmt.Sprintf("%s (%s)", a.NotifiedAt.Local().Format(timeFormat), a.Notified)
+		}
+		fmt.Printf("%-22s %-20s %-9s %-17s %s\n", a.Rule, benchmark, state, a.Since.Local().Format(timeFormat), notified)
+		if a.State == storage.AlertFiring {
+			fmt.Printf("  %s\n", a.Summary)
+		}
+	}
+	return nil
+}
+
+// checkRules prints the outcome of every alert rule at this moment.
+func checkRules(cfg *config.Config, db storage.Store, firingOnly bool) error {
+	rules, err := analysis.NewRules(cfg)
+	if err != nil {
+		return err
+	}
+	quarantined, err := analysis.Quarantined(db, cfg.Claude.Model)
+	if err != nil {
+		return err
+	}
+	results, err := analysis.EvaluateRules(db, cfg.Claude.Model, rules, checker.BenchmarkNames(), quarantined, timeNow())
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No rule has enough results to evaluate yet.")
+		return nil
+	}
+	for _, res := range results {
+		status := "✓"
+		switch {
+		case res.Quarantined:
+			status = "⊘"
+		case res.Firing:
+			status = "⚠"
+		}
+		if firingOnly && !res.Firing {
+			continue
+		}
+		fmt.Printf("%s [%s] %s\n", status, res.Rule.Name, analysis.DescribeRuleResult(res))
+	}
+	return nil
+}
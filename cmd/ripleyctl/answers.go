@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// answersCmd shows the distinct answers of each benchmark and how they drifted.
+func answersCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("answers", flag.ExitOnError)
+	benchmark := fs.String("benchmark", "", "only show this benchmark")
+	since := fs.String("since", "30d", "how far back to look (e.g. 12h, 7d)")
+	fs.Parse(args)
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+	from := timeNow().Add(-window)
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	names := checker.BenchmarkNames()
+	if *benchmark != "" {
+		names = []string{*benchmark}
+	}
+
+	fmt.Printf("=== Answer Forms (Last %s) ===\n", *since)
+	for _, name := range names {
+		records, err := db.ListRecords(storage.RecordFilter{Model: cfg.Claude.Model, Name: name, Since: from})
+		if err != nil {
+			return err
+		}
+		forms := analysis.AnswerForms(records)
+		if len(forms) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s (%d forms)\n", name, len(forms))
+		for _, f := range forms {
+			fmt.Printf("  %4.0f%% %4d runs | %s - %s | %q\n", f.Share*100, f.Runs,
+				f.FirstSeen.Local().Format("2006-01-02"), f.LastSeen.Local().Format("2006-01-02"), f.Answer)
+		}
+	}
+
+	drift, err := db.ListAnswerDrift(cfg.Claude.Model, *benchmark, from)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n=== Answer Drift (Last %s) ===\n", *since)
+	if len(drift) == 0 {
+		fmt.Println("No answer drift detected.")
+		return nil
+	}
+	for _, d := range drift {
+		fmt.Printf("⚠ %s\n", analysis.DescribeDrift(d))
+	}
+	return nil
+}
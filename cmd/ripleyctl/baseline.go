@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+const baselineUsageText = `Usage: ripleyctl baseline <command> [flags]
+
+Commands:
+  pin [flags] <name>      Pin a baseline: a time range (-since, or -from/-to) or -runs
+  list                    List pinned baselines
+  compare [flags] <name>  Compare a recent window against a baseline
+`
+
+// baselineCmd pins named baselines and compares recent results against them.
+func baselineCmd(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		fmt.Print(baselineUsageText)
+		return fmt.Errorf("baseline takes a command")
+	}
+
+	switch args[0] {
+	case "pin":
+		return baselinePin(cfg, args[1:])
+	case "list":
+		return baselineList(cfg)
+	case "compare":
+		return baselineCompare(cfg, args[1:])
+	default:
+		fmt.Print(baselineUsageText)
+		return fmt.Errorf("unknown baseline command %q", args[0])
+	}
+}
+
+// baselinePin saves a baseline under a name, replacing any previous one.
+func baselinePin(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("baseline pin", flag.ExitOnError)
+	since := fs.String("since", "", "use the period up to now (e.g. 7d)")
+	from := fs.String("from", "", "start of the period (YYYY-MM-DD or RFC 3339)")
+	to := fs.String("to", "", "end of the period, exclusive (YYYY-MM-DD or RFC 3339)")
+	runs := fs.String("runs", "", "comma-separated run IDs instead of a period")
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl baseline pin [-since 7d | -from date [-to date] | -runs id,...] <name>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("baseline pin takes exactly one name")
+	}
+
+	now := timeNow()
+	b := storage.Baseline{Name: fs.Arg(0), CreatedAt: now}
+	switch {
+	case *runs != "" && (*since != "" || *from != "" || *to != ""):
+		return fmt.Errorf("-runs cannot be combined with a period")
+	case *runs != "":
+		for _, id := range strings.Split(*runs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				b.RunIDs = append(b.RunIDs, id)
+			}
+		}
+		if len(b.RunIDs) == 0 {
+			return fmt.Errorf("-runs lists no run IDs")
+		}
+	case *since != "" && (*from != "" || *to != ""):
+		return fmt.Errorf("-since cannot be combined with -from or -to")
+	case *since != "":
+		window, err := parseSince(*since)
+		if err != nil {
+			return err
+		}
+		b.Since, b.Until = now.Add(-window), now
+	case *from != "":
+		var err error
+		if b.Since, err = parseDate(*from); err != nil {
+			return err
+		}
+		if *to != "" {
+			if b.Until, err = parseDate(*to); err != nil {
+				return err
+			}
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("baseline pin needs -since, -from or -runs")
+	}
+
+	db, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	records, err := b.Records(db, cfg.Claude.Model)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("baseline %s would contain no results", b.Name)
+	}
+
+	if err := db.PinBaseline(b); err != nil {
+		return err
+	}
+	fmt.Printf("Pinned baseline %s (%s, %d results)\n", b.Name, describeBaseline(b), len(records))
+	return nil
+}
+
+// baselineList prints all pinned baselines.
+func baselineList(cfg *config.Config) error {
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	baselines, err := db.ListBaselines()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("=== Baselines ===")
+	if len(baselines) == 0 {
+		fmt.Println("No baselines pinned.")
+		return nil
+	}
+	for _, b := range baselines {
+		fmt.Printf("%s | %s | pinned %s\n", b.Name, describeBaseline(b), b.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// baselineCompare reports which benchmarks changed significantly since the baseline.
+func baselineCompare(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("baseline compare", flag.ExitOnError)
+	since := fs.String("since", "7d", "window to compare against the baseline (e.g. 12h, 7d)")
+	alpha := fs.Float64("alpha", 0.05, "significance level across all tests (Holm-corrected)")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl baseline compare [-since 7d] [-alpha 0.05] [-json] <name>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("baseline compare takes exactly one name")
+	}
+	if *alpha <= 0 || *alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1")
+	}
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	b, err := db.GetBaseline(fs.Arg(0))
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("no baseline named %s", fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	baseline, err := b.Records(db, cfg.Claude.Model)
+	if err != nil {
+		return err
+	}
+	current, err := db.ListRecords(storage.RecordFilter{Model: cfg.Claude.Model, Since: timeNow().Add(-window)})
+	if err != nil {
+		return err
+	}
+
+	comparisons := analysis.Compare(baseline, current, *alpha)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Baseline    storage.Baseline
+			Since       time.Time
+			Alpha       float64
+			Tests       int
+			Comparisons []analysis.BenchmarkComparison
+		}{b, timeNow().Add(-window), *alpha, analysis.Tests(comparisons), comparisons})
+	}
+
+	fmt.Printf("=== Last %s vs Baseline %s (%s) ===\n", *since, b.Name, describeBaseline(b))
+	if len(comparisons) == 0 {
+		fmt.Println("No benchmarks with results in both periods.")
+		return nil
+	}
+	fmt.Printf("%d tests, Holm-corrected at alpha %g\n", analysis.Tests(comparisons), *alpha)
+	for _, c := range comparisons {
+		fmt.Printf("%s (%d vs %d runs)\n", c.Benchmark, c.BaselineRuns, c.CurrentRuns)
+		for _, m := range c.Metrics {
+			status := " "
+			switch {
+			case m.Regressed():
+				status = "⚠"
+			case m.Significant:
+				status = "✓"
+			}
+			fmt.Printf("  %s %s\n", status, analysis.DescribeComparison(m))
+		}
+	}
+	return nil
+}
+
+// describeBaseline renders what a baseline selects.
+func describeBaseline(b storage.Baseline) string {
+	if len(b.RunIDs) > 0 {
+		return "runs " + strings.Join(b.RunIDs, ", ")
+	}
+	until := "now"
+	if !b.Until.IsZero() {
+		until = b.Until.Local().Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("%s to %s", b.Since.Local().Format("2006-01-02 15:04"), until)
+}
+
+// parseDate parses a date (YYYY-MM-DD, local time) or an RFC 3339 timestamp.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (e.g. 2025-03-01)", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+const dbUsageText = `Usage: ripleyctl db <command> <path>
+
+Commands:
+  backup <path>    Write a consistent snapshot of the database to path
+  restore <path>   Replace the database with the backup at path (stop ripleyd first)
+`
+
+// dbCmd backs up and restores the SQLite database.
+func dbCmd(cfg *config.Config, args []string) error {
+	if len(args) != 2 {
+		fmt.Print(dbUsageText)
+		return fmt.Errorf("db takes a command and a path")
+	}
+
+	switch args[0] {
+	case "backup":
+		db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		if err := storage.Backup(db, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Database backed up to %s\n", args[1])
+	case "restore":
+		previous, err := storage.Restore(args[1], cfg.Daemon.DBPath)
+		if err != nil {
+			return err
+		}
+		if previous == "" {
+			fmt.Printf("Database restored from %s\n", args[1])
+		} else {
+			fmt.Printf("Database restored from %s (previous copy kept at %s)\n", args[1], previous)
+		}
+	default:
+		fmt.Print(dbUsageText)
+		return fmt.Errorf("unknown db command %q", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// digestCmd prints the digest of the last day or week, or emails it to every
+// email destination.
+func digestCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("digest", flag.ExitOnError)
+	weekly := fs.Bool("weekly", false, "cover the last 7 days instead of the last 24 hours")
+	send := fs.Bool("send", false, "email the digest to every email destination instead of printing it")
+	fs.Parse(args)
+
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	to := timeNow()
+	from := to.Add(-24 * time.Hour)
+	if *weekly {
+		from = to.AddDate(0, 0, -7)
+	}
+	digest, err := analysis.BuildDigest(db, cfg.Claude.Model, from, to)
+	if err != nil {
+		return err
+	}
+	subject, text := notify.DigestSubject(digest, loc), notify.DigestText(digest, loc)
+
+	if !*send {
+		fmt.Println(subject)
+		fmt.Println()
+		fmt.Print(text)
+		return nil
+	}
+
+	if len(cfg.Notify.Email) == 0 {
+		return fmt.Errorf("no email destinations configured; add them under notify.email in config.yaml")
+	}
+	failed := 0
+	for _, c := range cfg.Notify.Email {
+		m, err := notify.NewEmail(c)
+		if err == nil {
+			err = m.Send(context.Background(), subject, text)
+		}
+		if err != nil {
+			fmt.Printf("⚠ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s\n", c.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d digests failed", failed, len(cfg.Notify.Email))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// flakyCmd shows the alternation rate, outcome entropy and status of every
+// benchmark as last assessed after a cycle.
+func flakyCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("flaky", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the assessments as JSON")
+	fs.Parse(args)
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	assessments, err := db.ListFlakiness(cfg.Claude.Model)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(assessments)
+	}
+
+	if len(assessments) == 0 {
+		fmt.Println("No flakiness assessments yet; the daemon assesses benchmarks after each cycle.")
+		return nil
+	}
+
+	fmt.Printf("=== Flakiness (Last %d Runs, Outages Excluded) ===\n", cfg.Flaky.Window)
+	fmt.Printf("%-20s %5s %12s %8s  %s\n", "Benchmark", "Runs", "Alternation", "Entropy", "Status")
+	for _, f := range assessments {
+		status := "stable"
+		switch {
+		case f.Quarantined:
+			status = "quarantined"
+		case f.Flaky:
+			status = "flaky"
+		}
+		if !f.Since.IsZero() && status != "stable" {
+			status += " since " + f.Since.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-20s %5d %11.0f%% %8.2f  %s\n", f.Benchmark, f.Runs, f.Alternation*100, f.Entropy, status)
+	}
+
+	if !cfg.Flaky.Quarantine {
+		fmt.Println("\nQuarantine is off; set flaky.quarantine in config.yaml to exclude flaky benchmarks from alerts.")
+	}
+	return nil
+}
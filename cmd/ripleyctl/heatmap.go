@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// heatmapShades go from best to worst.
+var heatmapShades = []string{"░░", "▒▒", "▓▓", "██"}
+
+// heatmapWeekdays lists the heatmap rows, Monday first.
+var heatmapWeekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// heatmapCmd shows how results vary with the hour of day and weekday.
+func heatmapCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	since := fs.String("since", "30d", "how far back to look (e.g. 7d, 4w)")
+	benchmark := fs.String("benchmark", "", "only include this benchmark")
+	metricName := fs.String("metric", "pass_rate", "metric to shade: pass_rate, effort or latency")
+	minRuns := fs.Int("min-runs", 3, "hide buckets with fewer runs")
+	asJSON := fs.Bool("json", false, "print all buckets as JSON")
+	fs.Parse(args)
+
+	metric := analysis.Metric(*metricName)
+	if metric != analysis.MetricPassRate && metric != analysis.MetricEffort && metric != analysis.MetricLatency {
+		return fmt.Errorf("unknown metric %q (use pass_rate, effort or latency)", *metricName)
+	}
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	records, err := db.ListRecords(storage.RecordFilter{Model: cfg.Claude.Model, Name: *benchmark, Since: timeNow().Add(-window)})
+	if err != nil {
+		return err
+	}
+
+	h := analysis.BuildHeatmap(records, loc)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(h)
+	}
+
+	fmt.Printf("=== %s by Hour and Weekday (Last %s, %s) ===\n", metric, *since, h.Timezone)
+	if h.Overall.Runs == 0 {
+		fmt.Println("No results in this period.")
+		return nil
+	}
+	printHeatmapGrid(h, metric, *minRuns)
+
+	overall, lo, hi := h.Overall.Interval(metric)
+	fmt.Printf("\nOverall: %s [%s, %s] over %d runs\n", metric.Format(overall), metric.Format(lo), metric.Format(hi), h.Overall.Runs)
+
+	fmt.Println("\nBy weekday:")
+	for _, day := range heatmapWeekdays {
+		printBucket(day.String()[:3], h.ByWeekday[day], metric, overall, *minRuns)
+	}
+	fmt.Println("\nBy hour:")
+	for hour, b := range h.ByHour {
+		printBucket(fmt.Sprintf("%02d:00", hour), b, metric, overall, *minRuns)
+	}
+	return nil
+}
+
+// printHeatmapGrid draws one row per weekday and one column per hour, shaded
+// from the best to the worst bucket shown.
+func printHeatmapGrid(h analysis.Heatmap, metric analysis.Metric, minRuns int) {
+	var best, worst float64
+	first := true
+	for _, c := range h.Cells {
+		if !enoughRuns(c.Bucket, metric, minRuns) {
+			continue
+		}
+		v, _, _ := c.Interval(metric)
+		if first || metric.Adverse(best-v) {
+			best = v
+		}
+		if first || metric.Adverse(v-worst) {
+			worst = v
+		}
+		first = false
+	}
+
+	// Columns are two characters wide; label every third hour
+	fmt.Print("    ")
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Printf("%-6s", fmt.Sprintf("%02d", hour))
+	}
+	fmt.Println()
+
+	for _, day := range heatmapWeekdays {
+		var row strings.Builder
+		for hour := 0; hour < 24; hour++ {
+			b := h.Cell(day, hour)
+			if !enoughRuns(b, metric, minRuns) {
+				row.WriteString(" ·")
+				continue
+			}
+			v, _, _ := b.Interval(metric)
+			row.WriteString(shade(v, best, worst))
+		}
+		fmt.Printf("%s %s\n", day.String()[:3], row.String())
+	}
+
+	fmt.Printf("\n%s best (%s) … %s worst (%s), · fewer than %d runs\n",
+		heatmapShades[0], metric.Format(best), heatmapShades[len(heatmapShades)-1], metric.Format(worst), minRuns)
+}
+
+// enoughRuns reports whether a bucket has enough runs to show metric.
+func enoughRuns(b analysis.Bucket, metric analysis.Metric, minRuns int) bool {
+	runs := b.Runs
+	if metric == analysis.MetricEffort {
+		runs = b.Scored
+	}
+	return runs > 0 && runs >= minRuns
+}
+
+// shade maps v onto the shades between best and worst.
+func shade(v, best, worst float64) string {
+	if best == worst {
+		return heatmapShades[0]
+	}
+	i := int((v - best) / (worst - best) * float64(len(heatmapShades)))
+	return heatmapShades[max(0, min(i, len(heatmapShades)-1))]
+}
+
+// printBucket prints a marginal bucket, flagging it when it is
+// significantly worse than overall.
+func printBucket(label string, b analysis.Bucket, metric analysis.Metric, overall float64, minRuns int) {
+	if !enoughRuns(b, metric, minRuns) {
+		return
+	}
+	v, lo, hi := b.Interval(metric)
+	status := " "
+	if b.WorseThan(metric, overall) {
+		status = "⚠"
+	}
+	fmt.Printf("  %s %-5s %s [%s, %s] (%d runs)\n", status, label, metric.Format(v), metric.Format(lo), metric.Format(hi), b.Runs)
+}
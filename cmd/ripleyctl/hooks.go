@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/hooks"
+	"github.com/cryptopatrick/ripley/internal/notify"
+)
+
+// hooksCmd runs every configured hook, or the ones named, once with a test
+// event and shows how each exited.
+func hooksCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("hooks", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl hooks [name...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	runner, err := hooks.NewRunner(cfg)
+	if err != nil {
+		return err
+	}
+	var selected []hooks.Hook
+	for _, h := range runner.Hooks {
+		if fs.NArg() == 0 || slices.Contains(fs.Args(), h.Name) {
+			selected = append(selected, h)
+		}
+	}
+	for _, name := range fs.Args() {
+		if !slices.ContainsFunc(selected, func(h hooks.Hook) bool { return h.Name == name }) {
+			return fmt.Errorf("no hook named %q", name)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no hooks configured; add them under hooks.commands in config.yaml")
+	}
+
+	event := notify.Event{
+		Kind:     notify.KindTest,
+		Severity: notify.SeverityInfo,
+		Time:     timeNow(),
+		Summary:  "Test event from ripleyctl",
+		Model:    cfg.Claude.Model,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// Test events run every hook, whatever its events
+	failed := 0
+	for _, h := range selected {
+		res := hooks.Run(context.Background(), h, payload, hooks.EventEnv("test", event))
+		status := "✓"
+		if res.Err != nil {
+			status = "⚠"
+			failed++
+		}
+		fmt.Printf("%s %s: exit status %d after %s\n", status, h.Name, res.ExitCode, res.Duration.Round(time.Millisecond))
+		if res.Err != nil && res.ExitCode < 0 {
+			fmt.Printf("  %v\n", res.Err)
+		}
+		if output := strings.TrimSpace(res.Output); output != "" {
+			fmt.Printf("  %s\n", strings.ReplaceAll(output, "\n", "\n  "))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hooks failed", failed, len(selected))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// incidentsCmd lists incidents with MTTR, frequency and uptime per month, or
+// prints the timeline of one incident.
+func incidentsCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("incidents", flag.ExitOnError)
+	since := fs.String("since", "90d", "how far back to report (e.g. 30d, 12w)")
+	asJSON := fs.Bool("json", false, "print the incidents and report as JSON")
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl incidents [-since 90d] [-json] [incident-id]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("incidents takes at most one incident ID")
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if fs.NArg() == 1 {
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid incident ID %q", fs.Arg(0))
+		}
+		return showIncident(db, id)
+	}
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		return err
+	}
+	now := timeNow()
+	from := now.Add(-window)
+
+	incidents, err := db.ListIncidents(cfg.Claude.Model, from)
+	if err != nil {
+		return err
+	}
+	report := analysis.ReportIncidents(incidents, from, now, loc)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Incidents []storage.Incident
+			Report    analysis.IncidentReport
+		}{incidents, report})
+	}
+
+	fmt.Printf("=== Incidents (Last %s) ===\n", *since)
+	if len(incidents) == 0 {
+		fmt.Println("No incidents.")
+	}
+	for _, inc := range incidents {
+		status := "✓"
+		if inc.Open() {
+			status = "⚠"
+		}
+		fmt.Printf("%s %s\n", status, analysis.DescribeIncident(inc, now))
+	}
+
+	fmt.Printf("\n=== By Month (%s) ===\n", loc)
+	fmt.Printf("%-8s %9s %8s %10s %8s %8s\n", "Month", "Incidents", "Resolved", "MTTR", "Uptime", "Healthy")
+	for _, m := range report.Months {
+		printIncidentPeriod(m.Start.In(loc).Format("2006-01"), m)
+	}
+	printIncidentPeriod("Total", report.Total)
+
+	days := report.Total.End.Sub(report.Total.Start).Hours() / 24
+	if days > 0 {
+		fmt.Printf("\nFrequency: %.1f incidents per 30 days\n", float64(report.Total.Started)/days*30)
+	}
+	return nil
+}
+
+func printIncidentPeriod(label string, p analysis.IncidentPeriod) {
+	mttr := "-"
+	if p.Resolved > 0 {
+		mttr = p.MTTR.Round(time.Second).String()
+	}
+	fmt.Printf("%-8s %9d %8d %10s %7.2f%% %7.2f%%\n", label, p.Started, p.Resolved, mttr, p.Uptime()*100, p.Healthy()*100)
+}
+
+// showIncident prints one incident and its timeline.
+func showIncident(db storage.Store, id int64) error {
+	inc, err := db.GetIncident(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("no incident with ID %d", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	events, err := db.ListIncidentEvents(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(analysis.DescribeIncident(inc, timeNow()))
+	fmt.Println("\nTimeline:")
+	for _, e := range events {
+		fmt.Printf("  %s  %-8s %s\n", e.At.Local().Format("2006-01-02 15:04:05"), e.Kind, e.Message)
+	}
+	return nil
+}
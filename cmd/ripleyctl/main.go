@@ -0,0 +1,118 @@
+// Command ripleyctl runs Ripley benchmarks on demand and reports on the
+// results stored by the daemon.
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+const usageText = `Usage: ripleyctl [command] [flags]
+
+Commands:
+  run          Run all benchmarks once and warn about degraded benchmarks (default)
+  stats        Show rolling statistics and recent runs from the database
+  show         Show a stored result with its transcript, or the results of a run
+  db           Back up or restore the database
+  regressions  List statistically significant shifts in benchmark metrics
+  baseline     Pin a baseline period and test recent results against it
+  heatmap      Show pass rate, effort or latency by hour of day and weekday
+  models       Compare every benchmark across models as Markdown or JSON
+  answers      Show the distinct answers of each benchmark and answer drift
+  incidents    List incidents with MTTR and uptime per month, or show a timeline
+  slo          Show SLO compliance, error budgets and burn-rate alerts
+  flaky        Show the flakiness of each benchmark and which are quarantined
+  alerts       Show which alerts are firing, resolved or flapping
+  notify       Send a test notification to the configured destinations
+  digest       Print the digest of the last day or week, or email it
+  hooks        Run the configured hooks once with a test event
+  help         Show this help
+`
+
+func main() {
+	cfg, err := loadConfig("config.yaml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ripleyctl: %v\n", err)
+		os.Exit(1)
+	}
+
+	cmd, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "run":
+		err = runCmd(cfg, args)
+	case "stats":
+		err = statsCmd(cfg, args)
+	case "show":
+		err = showCmd(cfg, args)
+	case "db":
+		err = dbCmd(cfg, args)
+	case "regressions":
+		err = regressionsCmd(cfg, args)
+	case "baseline":
+		err = baselineCmd(cfg, args)
+	case "heatmap":
+		err = heatmapCmd(cfg, args)
+	case "models":
+		err = modelsCmd(cfg, args)
+	case "answers":
+		err = answersCmd(cfg, args)
+	case "incidents":
+		err = incidentsCmd(cfg, args)
+	case "slo":
+		err = sloCmd(cfg, args)
+	case "flaky":
+		err = flakyCmd(cfg, args)
+	case "alerts":
+		err = alertsCmd(cfg, args)
+	case "notify":
+		err = notifyCmd(cfg, args)
+	case "digest":
+		err = digestCmd(cfg, args)
+	case "hooks":
+		err = hooksCmd(cfg, args)
+	case "help", "-h", "--help":
+		fmt.Print(usageText)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "ripleyctl: unknown command %q\n\n%s", cmd, usageText)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ripleyctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// timeNow is the time reports are computed at; tests fix it.
+var timeNow = time.Now
+
+// loadConfig reads path if it exists and falls back to the defaults otherwise.
+func loadConfig(path string) (*config.Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return config.LoadWithDefaults(), nil
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
+
+// parseSince parses a look-back period. In addition to time.ParseDuration
+// units it accepts whole days ("7d") and weeks ("2w").
+func parseSince(s string) (time.Duration, error) {
+	d, err := config.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q (e.g. 12h, 7d, 2w)", s)
+	}
+	return d, nil
+}
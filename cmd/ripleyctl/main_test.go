@@ -0,0 +1,228 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+var testNow = time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	// Output formats times in local time
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// testConfig returns the default configuration with a database in a
+// temporary directory.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := config.LoadWithDefaults()
+	cfg.Daemon.DBPath = filepath.Join(t.TempDir(), "ripley.db")
+	return cfg
+}
+
+// capture runs f and returns what it printed to stdout.
+func capture(t *testing.T, f func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	err = f()
+	w.Close()
+	return <-out, err
+}
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"0", 0, true},
+		{"-1h", 0, true},
+		{"", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q): expected error %v, got %v", tt.in, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSince(%q): expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2025-03-01", time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"2025-03-01T12:30:00Z", time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC), false},
+		{"2025-13-01", time.Time{}, true},
+		{"March", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDate(%q): expected error %v, got %v", tt.in, tt.wantErr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q): expected %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestCommandArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     func(*config.Config, []string) error
+		args    []string
+		wantErr string
+	}{
+		{"db without a path", dbCmd, []string{"restore"}, "db takes a command and a path"},
+		{"db with two paths", dbCmd, []string{"restore", "a.db", "b.db"}, "db takes a command and a path"},
+		{"db unknown command", dbCmd, []string{"copy", "a.db"}, `unknown db command "copy"`},
+		{"db restore missing backup", dbCmd, []string{"restore", "missing.db"}, "missing.db"},
+
+		{"baseline without a command", baselineCmd, nil, "baseline takes a command"},
+		{"baseline unknown command", baselineCmd, []string{"drop"}, `unknown baseline command "drop"`},
+		{"baseline pin without a name", baselineCmd, []string{"pin", "-since", "7d"}, "exactly one name"},
+		{"baseline pin without a period", baselineCmd, []string{"pin", "before"}, "needs -since, -from or -runs"},
+		{"baseline pin runs and period", baselineCmd, []string{"pin", "-runs", "a,b", "-since", "7d", "before"}, "-runs cannot be combined"},
+		{"baseline pin empty runs", baselineCmd, []string{"pin", "-runs", " , ", "before"}, "-runs lists no run IDs"},
+		{"baseline pin since and from", baselineCmd, []string{"pin", "-since", "7d", "-from", "2025-03-01", "before"}, "-since cannot be combined"},
+		{"baseline pin invalid since", baselineCmd, []string{"pin", "-since", "soon", "before"}, `invalid period "soon"`},
+		{"baseline pin invalid from", baselineCmd, []string{"pin", "-from", "March", "before"}, `invalid date "March"`},
+		{"baseline pin invalid to", baselineCmd, []string{"pin", "-from", "2025-03-01", "-to", "April", "before"}, `invalid date "April"`},
+		{"baseline compare without a name", baselineCmd, []string{"compare"}, "exactly one name"},
+		{"baseline compare invalid alpha", baselineCmd, []string{"compare", "-alpha", "1", "before"}, "alpha must be between 0 and 1"},
+		{"baseline compare invalid since", baselineCmd, []string{"compare", "-since", "0", "before"}, `invalid period "0"`},
+
+		{"slo without objectives", sloCmd, nil, "no SLOs defined"},
+		{"slo with arguments", sloCmd, []string{"availability"}, "slo takes no arguments"},
+
+		{"alerts with arguments", alertsCmd, []string{"pass_rate"}, "alerts takes no arguments"},
+		{"alerts check as JSON", alertsCmd, []string{"-check", "-json"}, "-json cannot be combined with -check"},
+
+		{"models unknown format", modelsCmd, []string{"-format", "csv"}, `unknown format "csv"`},
+		{"models invalid alpha", modelsCmd, []string{"-alpha", "0"}, "alpha must be between 0 and 1"},
+		{"models invalid since", modelsCmd, []string{"-since", "never"}, `invalid period "never"`},
+
+		{"incidents with two IDs", incidentsCmd, []string{"1", "2"}, "at most one incident ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			_, err := capture(t, func() error { return tt.cmd(cfg, tt.args) })
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBaselinePinRuns(t *testing.T) {
+	cfg := testConfig(t)
+	db, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.InsertRecord(storage.BenchmarkRecord{RunID: "run-1", Name: "Sum1to100", Model: "Sonnet", Passed: true, Timestamp: testNow})
+	db.InsertRecord(storage.BenchmarkRecord{RunID: "run-2", Name: "Sum1to100", Model: "Sonnet", Passed: true, Timestamp: testNow})
+	db.Close()
+
+	out, err := capture(t, func() error { return baselineCmd(cfg, []string{"pin", "-runs", " run-1, run-2,", "golden"}) })
+	if err != nil {
+		t.Fatalf("baseline pin failed: %v", err)
+	}
+	if !strings.Contains(out, "runs run-1, run-2, 2 results") {
+		t.Errorf("Expected both trimmed runs pinned, got %q", out)
+	}
+}
+
+func TestDBBackupRestore(t *testing.T) {
+	cfg := testConfig(t)
+	db, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.InsertRecord(storage.BenchmarkRecord{RunID: "run-1", Name: "Sum1to100", Model: "Sonnet", Passed: true, Timestamp: testNow})
+	db.Close()
+
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	if _, err := capture(t, func() error { return dbCmd(cfg, []string{"backup", backup}) }); err != nil {
+		t.Fatalf("db backup failed: %v", err)
+	}
+	db, err = storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.InsertRecord(storage.BenchmarkRecord{RunID: "run-2", Name: "Sum1to100", Model: "Sonnet", Passed: false, Timestamp: testNow})
+	db.Close()
+
+	out, err := capture(t, func() error { return dbCmd(cfg, []string{"restore", backup}) })
+	if err != nil {
+		t.Fatalf("db restore failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "Database restored from "+backup) || !strings.Contains(out, cfg.Daemon.DBPath+".pre-restore") {
+		t.Errorf("Unexpected output: %q", out)
+	}
+
+	db, err = storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		t.Fatalf("OpenReadOnly failed: %v", err)
+	}
+	defer db.Close()
+	if records, _ := db.ListRecords(storage.RecordFilter{}); len(records) != 1 {
+		t.Errorf("Expected the backed up record, got %d records", len(records))
+	}
+}
+
+func TestDBRestoreWithoutDatabase(t *testing.T) {
+	cfg := testConfig(t)
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	db, err := storage.Open(backup)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	db.Close()
+
+	out, err := capture(t, func() error { return dbCmd(cfg, []string{"restore", backup}) })
+	if err != nil {
+		t.Fatalf("db restore failed: %v", err)
+	}
+	if out != "Database restored from "+backup+"\n" {
+		t.Errorf("Expected no previous copy mentioned, got %q", out)
+	}
+}
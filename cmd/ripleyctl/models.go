@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// modelsCmd lines up every benchmark across the models it was run against.
+func modelsCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("models", flag.ExitOnError)
+	since := fs.String("since", "7d", "window to report on (e.g. 24h, 7d)")
+	reference := fs.String("reference", cfg.Claude.Model, "model the others are tested against")
+	alpha := fs.Float64("alpha", 0.05, "significance level of each test")
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	fs.Parse(args)
+
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unknown format %q (use markdown or json)", *format)
+	}
+	if *alpha <= 0 || *alpha >= 1 {
+		return fmt.Errorf("alpha must be between 0 and 1")
+	}
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	records, err := db.ListRecords(storage.RecordFilter{Since: timeNow().Add(-window)})
+	if err != nil {
+		return err
+	}
+
+	reports := analysis.CompareModels(records, *reference, cfg.Pricing, *alpha)
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Since     time.Time
+			Reference string
+			Alpha     float64
+			Reports   []analysis.ModelReport
+		}{timeNow().Add(-window), *reference, *alpha, reports})
+	}
+
+	fmt.Printf("# Model Comparison (Last %s)\n\n", *since)
+	if len(reports) == 0 {
+		fmt.Println("No results in this period.")
+		return nil
+	}
+	fmt.Printf("Each model is tested against %s at α = %g: Fisher's exact test for pass rate, "+
+		"Mann-Whitney for the medians of effort, tokens and latency. **Bold** values differ "+
+		"significantly; ⚠ marks the worse side.\n", *reference, *alpha)
+
+	for _, r := range reports {
+		fmt.Printf("\n## %s\n\n", r.Benchmark)
+		fmt.Println("| Model | Runs | Pass rate | Effort | Tokens | Latency | Cost/run |")
+		fmt.Println("|---|---:|---:|---:|---:|---:|---:|")
+		for _, m := range r.Models {
+			name := m.Model
+			if name == "" {
+				name = "(unknown)"
+			}
+			if m.Model == r.Reference {
+				name += " (reference)"
+			}
+
+			cost := "-"
+			if m.Priced {
+				cost = fmt.Sprintf("$%.6f", m.CostPerRun)
+			}
+
+			cells := []string{name, fmt.Sprint(m.Runs)}
+			for _, metric := range analysis.ModelMetrics {
+				cells = append(cells, modelCell(r, m, metric))
+			}
+			cells = append(cells, cost)
+			fmt.Printf("| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return nil
+}
+
+// modelCell renders a metric of a model, highlighting significant differences
+// from the reference. The reference itself is marked when any model is
+// significantly better.
+func modelCell(r analysis.ModelReport, m analysis.ModelSummary, metric analysis.Metric) string {
+	var value float64
+	switch metric {
+	case analysis.MetricPassRate:
+		value = m.PassRate
+	case analysis.MetricEffort:
+		value = m.Effort
+	case analysis.MetricTokens:
+		value = m.Tokens
+	case analysis.MetricLatency:
+		value = m.Latency
+	}
+	cell := metric.Format(value)
+
+	if m.Model == r.Reference {
+		for _, other := range r.Models {
+			if d, ok := other.Difference(metric); ok && d.Significant && metric.Adverse(-d.Change()) {
+				return cell + " ⚠"
+			}
+		}
+		return cell
+	}
+
+	d, ok := m.Difference(metric)
+	if !ok || !d.Significant {
+		return cell
+	}
+	cell = fmt.Sprintf("**%s** (p=%.3f)", cell, d.PValue)
+	if d.Regressed() {
+		cell += " ⚠"
+	}
+	return cell
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/ripley"
+)
+
+// notifyCmd sends a test event to every configured destination, or to the
+// ones named, to check URLs, secrets and firewalls.
+func notifyCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl notify [name...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	all, err := notify.Destinations(cfg)
+	if err != nil {
+		return err
+	}
+	var destinations []notify.Destination
+	for _, d := range all {
+		if fs.NArg() == 0 || slices.Contains(fs.Args(), d.Name) {
+			destinations = append(destinations, d)
+		}
+	}
+	for _, name := range fs.Args() {
+		if !slices.ContainsFunc(destinations, func(d notify.Destination) bool { return d.Name == name }) {
+			return fmt.Errorf("no notification destination named %q", name)
+		}
+	}
+	if len(destinations) == 0 {
+		return fmt.Errorf("no notifications configured; add destinations under notify in config.yaml")
+	}
+
+	event := notify.Event{
+		Kind:     notify.KindTest,
+		Severity: notify.SeverityInfo,
+		Time:     timeNow(),
+		Summary:  "Test notification from ripleyctl",
+		Model:    cfg.Claude.Model,
+		Quote:    ripley.RandomQuoteByEffort("good"),
+	}
+
+	// Test events skip routing so every destination can be checked
+	failed := 0
+	for _, d := range destinations {
+		n := d.Notifier
+		if m, ok := n.(*notify.Email); ok && m.DigestOnly {
+			alerting := *m
+			alerting.DigestOnly = false
+			n = &alerting
+		}
+		if err := n.Notify(context.Background(), event); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ %s\n", d.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d notifications failed", failed, len(destinations))
+	}
+	return nil
+}
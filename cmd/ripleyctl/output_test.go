@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// seed fills the database of cfg with a week of hourly cycles on Sonnet and
+// Opus, and incidents for both, and fixes the clock at testNow.
+func seed(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	db, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 7*24; i++ {
+		at := testNow.Add(-time.Duration(7*24-i) * time.Hour)
+		for _, model := range []string{"Sonnet", "Opus"} {
+			runID := model + "-" + at.Format("2006010215")
+			// Opus fails ListReverse most of the time and is slower
+			slow := time.Duration(0)
+			if model == "Opus" {
+				slow = time.Second
+			}
+			for _, r := range []storage.BenchmarkRecord{
+				{Name: "Sum1to100", Passed: i%20 != 0, TokensUsed: 4 + i%3, Duration: 800*time.Millisecond + slow + time.Duration(i%5)*100*time.Millisecond},
+				{Name: "ListReverse", Passed: model == "Sonnet" || i%4 == 0, TokensUsed: 12 + i%4, Duration: 1500*time.Millisecond + slow + time.Duration(i%3)*200*time.Millisecond},
+			} {
+				r.RunID, r.Model, r.Timestamp = runID, model, at
+				r.EffortScore, r.Effort = 90, "good"
+				if !r.Passed {
+					r.ErrorClass, r.EffortScore, r.Effort = "wrong_answer", 40, "poor"
+				}
+				if err := db.InsertRecord(r); err != nil {
+					t.Fatalf("InsertRecord failed: %v", err)
+				}
+			}
+		}
+	}
+
+	for _, inc := range []storage.Incident{
+		{Model: "Sonnet", Scope: storage.ScopeSuite, StartedAt: testNow.Add(-75 * time.Hour), EndedAt: testNow.Add(-72 * time.Hour),
+			Benchmarks: []string{"ListReverse", "Sum1to100"}, ErrorClasses: map[string]int{"timeout": 4, "rate_limited": 2}},
+		{Model: "Sonnet", Scope: "Sum1to100", StartedAt: testNow.Add(-90 * time.Minute),
+			Benchmarks: []string{"Sum1to100"}, ErrorClasses: map[string]int{"wrong_answer": 3}},
+		{Model: "Opus", Scope: "ListReverse", StartedAt: testNow.Add(-48 * time.Hour),
+			Benchmarks: []string{"ListReverse"}, ErrorClasses: map[string]int{"wrong_answer": 30}},
+	} {
+		if _, err := db.InsertIncident(inc); err != nil {
+			t.Fatalf("InsertIncident failed: %v", err)
+		}
+	}
+
+	timeNow = func() time.Time { return testNow }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+// golden compares got with testdata/name.golden, or rewrites the file with
+// -update.
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if got != string(want) {
+		t.Errorf("Output differs from %s (rerun with -update if intended):\n%s", path, got)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Claude.Model = "Sonnet"
+	cfg.Pricing = map[string]float64{"Sonnet": 15}
+	cfg.Analysis.Timezone = "UTC"
+	cfg.SLO.Objectives = []config.Objective{
+		{Name: "availability", Target: 0.95, Window: "7d"},
+		{Name: "sum-latency", Benchmark: "Sum1to100", Target: 0.9, Window: "1d", Latency: "1s"},
+	}
+	seed(t, cfg)
+
+	tests := []struct {
+		name string
+		cmd  func(*config.Config, []string) error
+		args []string
+	}{
+		{"models", modelsCmd, nil},
+		{"models_json", modelsCmd, []string{"-format", "json"}},
+		{"models_opus", modelsCmd, []string{"-reference", "Opus", "-since", "1d"}},
+		{"incidents", incidentsCmd, []string{"-since", "30d"}},
+		{"incidents_json", incidentsCmd, []string{"-since", "30d", "-json"}},
+		{"slo", sloCmd, nil},
+		{"slo_json", sloCmd, []string{"-json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := capture(t, func() error { return tt.cmd(cfg, tt.args) })
+			if err != nil {
+				t.Fatalf("%s failed: %v", tt.name, err)
+			}
+			golden(t, tt.name, out)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// regressionsCmd lists the change points detected by the daemon.
+func regressionsCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("regressions", flag.ExitOnError)
+	benchmark := fs.String("benchmark", "", "only show this benchmark")
+	since := fs.String("since", "30d", "how far back to look (e.g. 12h, 7d)")
+	fs.Parse(args)
+
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	points, err := db.ListChangePoints(cfg.Claude.Model, *benchmark, timeNow().Add(-window))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== Detected Shifts (Last %s) ===\n", *since)
+	if len(points) == 0 {
+		fmt.Println("No significant shifts detected.")
+		return nil
+	}
+	for _, cp := range points {
+		status := "✓"
+		if analysis.Metric(cp.Metric).Adverse(cp.Magnitude()) {
+			status = "⚠"
+		}
+		fmt.Printf("%s %s\n", status, analysis.DescribeChangePoint(cp))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// runCmd runs every benchmark once, stores the results and prints the
+// rolling statistics with a warning for each degraded benchmark.
+func runCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	model := fs.String("model", cfg.Claude.Model, "model to benchmark (e.g. Sonnet, Opus, Haiku)")
+	fs.Parse(args)
+
+	store, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer store.Close()
+
+	db, err := storage.NewSpool(store, cfg.Spool.Path)
+	if err != nil {
+		return fmt.Errorf("failed to initialize spool: %w", err)
+	}
+
+	fmt.Println("=== Running Claude Code Liveness & Effort Check ===")
+	results := checker.RunBenchmarks(db, *model, checker.NewEffortScorer(cfg))
+	checker.PrintResults(results)
+
+	quarantined, err := analysis.Quarantined(db, *model)
+	if err != nil {
+		return fmt.Errorf("failed to load quarantined benchmarks: %w", err)
+	}
+	if len(results) > 0 {
+		classifier := analysis.CycleClassifier{OutageShare: *cfg.Cycles.OutageShare, Quarantined: quarantined}
+		cycle, err := analysis.ClassifyRun(db, classifier, results[0].RunID)
+		if err != nil {
+			fmt.Printf("WARNING: failed to classify the cycle: %v\n\n", err)
+		} else if cycle.Label != storage.CycleHealthy {
+			fmt.Printf("WARNING: %s\n\n", analysis.DescribeCycle(cycle))
+		}
+	}
+
+	flaky, err := analysis.UpdateFlakiness(db, *model, analysis.NewFlakyDetector(cfg), checker.BenchmarkNames(), timeNow())
+	if err != nil {
+		fmt.Printf("WARNING: failed to assess flakiness: %v\n\n", err)
+	}
+	for _, f := range flaky {
+		fmt.Printf("NOTE: %s\n\n", analysis.DescribeFlakiness(f))
+	}
+
+	if pending := db.Pending(); pending > 0 {
+		fmt.Printf("WARNING: %d results spooled in %s, waiting for the database\n\n", pending, cfg.Spool.Path)
+	}
+
+	return printRollingStats(cfg, db, *model)
+}
+
+// printRollingStats prints the rolling statistics of every benchmark on model and
+// warns about the ones whose pass rate is below the configured threshold.
+// Runs from outage cycles are left out, and quarantined benchmarks are
+// marked instead of warned about.
+func printRollingStats(cfg *config.Config, db storage.Store, model string) error {
+	fmt.Printf("=== Rolling Statistics (Last %d Runs, Outages Excluded) ===\n", cfg.Monitoring.RollingWindow)
+
+	quarantined, err := analysis.Quarantined(db, model)
+	if err != nil {
+		return fmt.Errorf("failed to load quarantined benchmarks: %w", err)
+	}
+
+	var degraded []string
+	for _, b := range checker.Benchmarks {
+		avgTokens, avgDuration, passRate, err := db.GetRollingStats(model, b.Name, cfg.Monitoring.RollingWindow)
+		if err != nil {
+			return fmt.Errorf("failed to get stats for %s: %w", b.Name, err)
+		}
+
+		status, note := "✓", ""
+		switch {
+		case quarantined[b.Name]:
+			status, note = "⊘", " (quarantined)"
+		case passRate < cfg.Monitoring.WarningThreshold:
+			status = "⚠"
+			degraded = append(degraded, b.Name)
+		}
+
+		fmt.Printf("%s %s | Avg Tokens: %.1f | Avg Duration: %.2fs | Pass Rate: %.0f%%%s\n",
+			status, b.Name, avgTokens, avgDuration, passRate*100, note)
+	}
+
+	for _, name := range degraded {
+		fmt.Printf("\nWARNING: %s pass rate is below %.0f%%\n", name, cfg.Monitoring.WarningThreshold*100)
+	}
+	return nil
+}
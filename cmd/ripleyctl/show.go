@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// showCmd prints a stored result with its full transcript, or the results of
+// a run when given a run ID.
+func showCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Println("Usage: ripleyctl show <record-id | run-id>")
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("show takes exactly one argument")
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return showRun(db, fs.Arg(0))
+	}
+	return showRecord(db, id)
+}
+
+// showRun lists the records of a run.
+func showRun(db storage.Store, runID string) error {
+	records, err := db.GetRun(runID)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no run with ID %s", runID)
+	}
+
+	fmt.Printf("=== Run %s ===\n", runID)
+	for _, rec := range records {
+		fmt.Printf("#%d [%s] %s | Effort: %s | Tokens: %d | Duration: %s | Output: %s\n",
+			rec.ID, passFail(rec.Passed), rec.Name, effortLabel(rec), rec.TokensUsed, rec.Duration, firstLine(rec.Output))
+	}
+	return nil
+}
+
+// showRecord prints one record and its transcript.
+func showRecord(db storage.Store, id int64) error {
+	rec, err := db.GetRecord(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("=== Record #%d ===\n", rec.ID)
+	fmt.Printf("Benchmark: %s\nRun: %s\nTime: %s\nStatus: %s\nEffort: %s\nTokens: %d\nDuration: %s\nQuote: %s\n",
+		rec.Name, rec.RunID, rec.Timestamp.Local().Format("2006-01-02 15:04:05"),
+		statusLabel(rec), effortLabel(rec), rec.TokensUsed, rec.Duration, rec.Quote)
+
+	t := rec.Transcript
+	if t == nil {
+		fmt.Printf("\nOutput:\n%s\n\n(no transcript stored)\n", rec.Output)
+		return nil
+	}
+
+	fmt.Printf("\n--- Command ---\n%s\n", strings.Join(t.Argv, " "))
+	fmt.Printf("\n--- Prompt ---\n%s\n", t.Prompt)
+	fmt.Printf("\n--- Stdout ---\n%s\n", t.Stdout)
+	fmt.Printf("\n--- Stderr ---\n%s\n", t.Stderr)
+	if len(t.Events) > 0 {
+		fmt.Printf("\n--- Events (%d) ---\n", len(t.Events))
+		for _, e := range t.Events {
+			fmt.Println(string(e))
+		}
+	}
+	return nil
+}
+
+func passFail(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+// statusLabel returns PASS or FAIL with the error class of a failed record.
+func statusLabel(rec storage.BenchmarkRecord) string {
+	if rec.Passed || rec.ErrorClass == "" {
+		return passFail(rec.Passed)
+	}
+	return fmt.Sprintf("FAIL (%s)", rec.ErrorClass)
+}
+
+// firstLine returns the first line of s, marking any truncation.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
+
+// effortLabel renders the effort category and score of a record, or "-" for
+// records stored before effort was scored.
+func effortLabel(rec storage.BenchmarkRecord) string {
+	if rec.Effort == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%.0f)", rec.Effort, rec.EffortScore)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// sloCmd reports the compliance, error budget and burn rates of every SLO.
+func sloCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("slo", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return fmt.Errorf("slo takes no arguments")
+	}
+
+	evaluator, err := analysis.NewSLOEvaluator(cfg)
+	if err != nil {
+		return err
+	}
+	if len(evaluator.Objectives) == 0 {
+		return fmt.Errorf("no SLOs defined; add them under slo.objectives in config.yaml")
+	}
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	statuses, err := evaluator.Evaluate(db, cfg.Claude.Model, timeNow())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	fmt.Println("=== SLO Status ===")
+	for _, s := range statuses {
+		status := "✓"
+		if !s.Met() || len(s.Firing()) > 0 {
+			status = "⚠"
+		}
+		fmt.Printf("%s %s: %s\n", status, s.Name, s.Objective)
+		fmt.Printf("    Compliance: %.2f%% (%d of %d runs bad) | Error budget: %.0f%% left\n",
+			s.Compliance*100, s.Bad, s.Runs, s.BudgetRemaining()*100)
+
+		for _, a := range s.Alerts {
+			mark := " "
+			if a.Firing {
+				mark = "!"
+			}
+			fmt.Printf("  %s %-6s burn %5.1fx over %-4s %5.1fx over %-4s (alert at %.1fx)\n", mark, a.Severity,
+				a.Long.BurnRate, analysis.FormatWindow(a.Long.Window), a.Short.BurnRate, analysis.FormatWindow(a.Short.Window), a.BurnRate)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// statsCmd prints rolling statistics and the most recent runs. The database
+// is opened read-only so it is safe to run while ripleyd is writing.
+func statsCmd(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	runs := fs.Int("runs", 10, "number of recent runs to list")
+	fs.Parse(args)
+
+	db, err := storage.OpenReadOnly(cfg.Daemon.DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	if err := printRollingStats(cfg, db, cfg.Claude.Model); err != nil {
+		return err
+	}
+
+	summaries, err := db.ListRuns(cfg.Claude.Model, *runs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n=== Recent Runs ===\n")
+	labels := make(map[string]int)
+	for _, run := range summaries {
+		label := run.Label
+		if label == "" {
+			label = "unclassified"
+		}
+		labels[label]++
+		fmt.Printf("%s | %s | %d/%d passed | %s\n",
+			run.RunID, run.StartedAt.Local().Format("2006-01-02 15:04:05"), run.Passed, run.Total, label)
+	}
+
+	if len(summaries) > 0 {
+		fmt.Printf("\nCycles: %d healthy, %d partial, %d quality, %d outage",
+			labels[storage.CycleHealthy], labels[storage.CyclePartial], labels[storage.CycleQuality], labels[storage.CycleOutage])
+		if n := labels["unclassified"]; n > 0 {
+			fmt.Printf(", %d unclassified", n)
+		}
+		fmt.Println()
+	}
+	return nil
+}
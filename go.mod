@@ -3,6 +3,7 @@ module github.com/cryptopatrick/ripley
 go 1.22
 
 require (
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.32
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,191 @@
+// Package alert decides which of the conditions checked after every cycle are
+// worth a notification. It keeps the state of each alert across cycles and
+// daemon restarts, so a problem is announced when it starts, repeated at long
+// intervals while it lasts and announced again when it resolves. Alerts that
+// keep flipping between firing and resolved are announced once as flapping and
+// then held quiet until they settle.
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// What an alert last notified, stored in storage.Alert.Notified
+const (
+	notifiedFiring   = "firing"
+	notifiedResolved = "resolved"
+	notifiedFlapping = "flapping"
+)
+
+// Signal is the outcome of checking one alert condition in a cycle.
+type Signal struct {
+	Rule      string // e.g. "pass_rate" or "slo/availability"
+	Benchmark string // Empty for suite-wide conditions
+	Firing    bool
+
+	// Sent when the alert fires, repeats, flaps or resolves. Its summary
+	// should describe the current state, firing or not.
+	Event notify.Event
+}
+
+// Manager turns signals into notifications.
+type Manager struct {
+	DB             storage.Store
+	Model          string // Model whose alerts are managed; others sharing DB are left alone
+	Notifier       notify.Notifier
+	Cooldown       time.Duration // Minimum time between notifications of one alert
+	RepeatInterval time.Duration // Re-notify alerts still firing this often; zero never
+	FlapWindow     time.Duration // Period over which state changes are counted
+	FlapThreshold  int           // State changes within FlapWindow that make an alert flapping; zero never
+}
+
+// NewManager returns a manager configured by cfg that keeps alert state in
+// db and notifies n.
+func NewManager(cfg *config.Config, db storage.Store, n notify.Notifier) (*Manager, error) {
+	cooldown, err := config.ParseDuration(cfg.Alerts.Cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alerts.cooldown: %w", err)
+	}
+	repeat, err := config.ParseDuration(cfg.Alerts.RepeatInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alerts.repeat_interval: %w", err)
+	}
+	flapWindow, err := config.ParseDuration(cfg.Alerts.FlapWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alerts.flap_window: %w", err)
+	}
+	return &Manager{
+		DB:             db,
+		Model:          cfg.Claude.Model,
+		Notifier:       n,
+		Cooldown:       cooldown,
+		RepeatInterval: repeat,
+		FlapWindow:     flapWindow,
+		FlapThreshold:  *cfg.Alerts.FlapThreshold,
+	}, nil
+}
+
+// Process updates the alerts that signals were evaluated for at now and sends
+// the notifications they call for; alerts without a signal keep their state.
+// It returns the events sent. A notification that fails is not retried, so a
+// destination that is down does not hold the others to repeats.
+func (m *Manager) Process(ctx context.Context, signals []Signal, now time.Time) ([]notify.Event, error) {
+	stored, err := m.DB.ListAlerts(m.Model)
+	if err != nil {
+		return nil, err
+	}
+	alerts := make(map[[2]string]storage.Alert, len(stored))
+	for _, a := range stored {
+		alerts[[2]string{a.Rule, a.Benchmark}] = a
+	}
+
+	var sent []notify.Event
+	var errs []error
+	for _, s := range signals {
+		a, ok := alerts[[2]string{s.Rule, s.Benchmark}]
+		if !ok {
+			a = storage.Alert{Model: m.Model, Rule: s.Rule, Benchmark: s.Benchmark, State: storage.AlertOK, Since: now}
+		}
+
+		event, due := m.update(&a, s, now)
+		if due {
+			if event.Time.IsZero() {
+				event.Time = now
+			}
+			if err := m.Notifier.Notify(ctx, event); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", Describe(a), err))
+			}
+			sent = append(sent, event)
+		}
+		if err := m.DB.SaveAlert(a); err != nil {
+			errs = append(errs, err)
+		}
+		alerts[[2]string{s.Rule, s.Benchmark}] = a
+	}
+	return sent, errors.Join(errs...)
+}
+
+// update applies s to a and returns the event to send, if any.
+func (m *Manager) update(a *storage.Alert, s Signal, now time.Time) (notify.Event, bool) {
+	state := storage.AlertOK
+	if s.Firing {
+		state = storage.AlertFiring
+		a.Severity = s.Event.Severity
+	}
+	if state != a.State {
+		a.State, a.Since = state, now
+		a.Flips = append(a.Flips, now)
+	}
+	a.Summary = s.Event.Summary
+
+	// Count the state changes within the flap window; an alert stops
+	// flapping once it has held its state for a whole window
+	for len(a.Flips) > 0 && now.Sub(a.Flips[0]) >= m.FlapWindow {
+		a.Flips = a.Flips[1:]
+	}
+	if m.FlapThreshold > 0 && len(a.Flips) >= m.FlapThreshold {
+		a.Flapping = true
+	} else if len(a.Flips) == 0 {
+		a.Flapping = false
+	}
+
+	want := a.Notified
+	switch {
+	case a.Flapping:
+		want = notifiedFlapping
+	case a.State == storage.AlertFiring:
+		want = notifiedFiring
+	case a.Notified == notifiedFiring || a.Notified == notifiedFlapping:
+		want = notifiedResolved
+	}
+
+	repeat := false
+	switch {
+	case want != a.Notified:
+	case want == notifiedFiring && notify.SeverityRank(a.Severity) > notify.SeverityRank(a.NotifiedSeverity):
+		// Escalated
+	case want == notifiedFiring && m.RepeatInterval > 0 && now.Sub(a.NotifiedAt) >= m.RepeatInterval:
+		repeat = true
+	default:
+		return notify.Event{}, false
+	}
+	// Changes within the cooldown wait, and are dropped if they change back
+	if !a.NotifiedAt.IsZero() && now.Sub(a.NotifiedAt) < m.Cooldown {
+		return notify.Event{}, false
+	}
+
+	e := s.Event
+	e.Alert = &notify.Alert{Rule: a.Rule, State: want, Since: a.Since, Repeat: repeat}
+	switch want {
+	case notifiedResolved:
+		// Resolutions go wherever the alert went
+		e.Kind, e.Severity = notify.KindAlertResolved, a.NotifiedSeverity
+	case notifiedFlapping:
+		e.Kind, e.Severity = notify.KindAlertFlapping, a.Severity
+		if e.Severity == "" {
+			e.Severity = notify.SeverityWarning
+		}
+		e.Summary = fmt.Sprintf("%s is flapping: %d state changes in %s, notifications paused until it is stable | %s",
+			Describe(*a), len(a.Flips), analysis.FormatWindow(m.FlapWindow), s.Event.Summary)
+		e.Alert.Since = a.Flips[0]
+	}
+
+	a.Notified, a.NotifiedSeverity, a.NotifiedAt = want, e.Severity, now
+	return e, true
+}
+
+// Describe names an alert, e.g. "pass_rate alert for Sum1to100".
+func Describe(a storage.Alert) string {
+	if a.Benchmark == "" {
+		return a.Rule + " alert"
+	}
+	return a.Rule + " alert for " + a.Benchmark
+}
@@ -0,0 +1,227 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// recorder is a notifier that keeps the events it gets.
+type recorder struct {
+	events []notify.Event
+	err    error
+}
+
+func (r *recorder) Notify(ctx context.Context, e notify.Event) error {
+	r.events = append(r.events, e)
+	return r.err
+}
+
+var start = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func degraded(firing bool, severity string) Signal {
+	summary := "Sum1to100 pass rate 90% is above 70%"
+	if firing {
+		summary = "Sum1to100 pass rate 40% is below 70%"
+	}
+	return Signal{
+		Rule:      "pass_rate",
+		Benchmark: "Sum1to100",
+		Firing:    firing,
+		Event:     notify.Event{Kind: notify.KindDegraded, Severity: severity, Summary: summary},
+	}
+}
+
+func testManager(db storage.Store, n notify.Notifier) *Manager {
+	return &Manager{
+		DB:             db,
+		Notifier:       n,
+		Cooldown:       time.Hour,
+		RepeatInterval: 24 * time.Hour,
+		FlapWindow:     6 * time.Hour,
+		FlapThreshold:  4,
+	}
+}
+
+// run processes one signal per cycle, half an hour apart, and returns the
+// state of each event sent, "" for cycles that sent nothing.
+func run(t *testing.T, m *Manager, from time.Time, signals []Signal) []string {
+	t.Helper()
+	var got []string
+	for i, s := range signals {
+		sent, err := m.Process(context.Background(), []Signal{s}, from.Add(time.Duration(i)*30*time.Minute))
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		switch len(sent) {
+		case 0:
+			got = append(got, "")
+		case 1:
+			state := sent[0].Alert.State
+			if sent[0].Alert.Repeat {
+				state = "repeat"
+			}
+			got = append(got, state)
+		default:
+			t.Fatalf("Expected at most one event per signal, got %+v", sent)
+		}
+	}
+	return got
+}
+
+func TestManagerTransitions(t *testing.T) {
+	on := degraded(true, notify.SeverityWarning)
+	off := degraded(false, notify.SeverityWarning)
+	critical := degraded(true, notify.SeverityCritical)
+
+	tests := []struct {
+		name    string
+		signals []Signal
+		want    []string
+	}{
+		{"healthy alerts stay quiet", []Signal{off, off, off}, []string{"", "", ""}},
+		{"firing is notified once", []Signal{on, on, on, on}, []string{"firing", "", "", ""}},
+		{"resolve is notified", []Signal{on, on, off, off}, []string{"firing", "", "resolved", ""}},
+		{"resolve waits for the cooldown", []Signal{on, off, off, off}, []string{"firing", "", "resolved", ""}},
+		{"blips within the cooldown are dropped", []Signal{on, off, on, on}, []string{"firing", "", "", ""}},
+		{"escalation is notified", []Signal{on, on, critical, critical}, []string{"firing", "", "firing", ""}},
+		{"flapping is notified once", []Signal{on, on, off, on, off, on, off, on}, []string{"firing", "", "resolved", "", "flapping", "", "", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testManager(storage.NewMemory(), &recorder{})
+			got := run(t, m, start, tt.signals)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestManagerRepeat(t *testing.T) {
+	on := degraded(true, notify.SeverityWarning)
+	db := storage.NewMemory()
+	m := testManager(db, &recorder{})
+
+	run(t, m, start, []Signal{on})
+	if got := run(t, m, start.Add(23*time.Hour), []Signal{on}); got[0] != "" {
+		t.Errorf("Expected no repeat before the repeat interval, got %q", got)
+	}
+	if got := run(t, m, start.Add(24*time.Hour), []Signal{on}); got[0] != "repeat" {
+		t.Errorf("Expected a repeat after the repeat interval, got %q", got)
+	}
+
+	m.RepeatInterval = 0
+	if got := run(t, m, start.Add(72*time.Hour), []Signal{on}); got[0] != "" {
+		t.Errorf("Expected no repeat with repeat interval 0, got %q", got)
+	}
+}
+
+func TestManagerFlappingSettles(t *testing.T) {
+	on := degraded(true, notify.SeverityWarning)
+	off := degraded(false, notify.SeverityWarning)
+	n := &recorder{}
+	m := testManager(storage.NewMemory(), n)
+	m.Cooldown = 0
+
+	got := run(t, m, start, []Signal{on, off, on, off})
+	if strings.Join(got, ",") != "firing,resolved,firing,flapping" {
+		t.Fatalf("Expected the fourth change to flap, got %q", got)
+	}
+	flapping := n.events[3]
+	if flapping.Kind != notify.KindAlertFlapping || flapping.Severity != notify.SeverityWarning || !strings.Contains(flapping.Summary, "4 state changes in 6h") {
+		t.Errorf("Unexpected flapping event: %+v", flapping)
+	}
+
+	// Quiet while the flips stay within the window, then resolved once the
+	// alert has held its state for a whole window
+	got = run(t, m, start.Add(2*time.Hour), []Signal{off, off, off, off, off, off, off, off, off, off, off, off})
+	if strings.Join(got, ",") != ",,,,,,,,,,,resolved" {
+		t.Errorf("Expected one resolve after the flap window, got %q", got)
+	}
+	resolved := n.events[len(n.events)-1]
+	if resolved.Kind != notify.KindAlertResolved || resolved.Severity != notify.SeverityWarning {
+		t.Errorf("Expected a resolve with the alert's severity, got %+v", resolved)
+	}
+}
+
+func TestManagerPersists(t *testing.T) {
+	on := degraded(true, notify.SeverityWarning)
+	db := storage.NewMemory()
+
+	// A restart builds a new manager on the same store
+	run(t, testManager(db, &recorder{}), start, []Signal{on})
+	n := &recorder{}
+	got := run(t, testManager(db, n), start.Add(2*time.Hour), []Signal{on, degraded(false, notify.SeverityWarning)})
+	if strings.Join(got, ",") != ",resolved" {
+		t.Errorf("Expected the firing alert to be remembered, got %q", got)
+	}
+
+	alerts, err := db.ListAlerts("")
+	if err != nil {
+		t.Fatalf("ListAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].State != storage.AlertOK || alerts[0].Notified != "resolved" {
+		t.Errorf("Expected one resolved alert, got %+v", alerts)
+	}
+}
+
+func TestManagerModels(t *testing.T) {
+	db := storage.NewMemory()
+	sonnet, opus := testManager(db, &recorder{}), testManager(db, &recorder{})
+	sonnet.Model, opus.Model = "Sonnet", "Opus"
+
+	// Opus firing must not make Sonnet's healthy cycle a resolve, nor the reverse
+	if got := run(t, opus, start, []Signal{degraded(true, notify.SeverityWarning)}); got[0] != "firing" {
+		t.Fatalf("Expected Opus to fire, got %q", got)
+	}
+	if got := run(t, sonnet, start.Add(time.Hour), []Signal{degraded(false, notify.SeverityWarning)}); got[0] != "" {
+		t.Errorf("Expected nothing sent for Sonnet, got %q", got)
+	}
+
+	alerts, _ := db.ListAlerts("Opus")
+	if len(alerts) != 1 || alerts[0].State != storage.AlertFiring {
+		t.Errorf("Expected Opus's alert to stay firing, got %+v", alerts)
+	}
+	alerts, _ = db.ListAlerts("Sonnet")
+	if len(alerts) != 1 || alerts[0].State != storage.AlertOK {
+		t.Errorf("Expected a separate OK alert for Sonnet, got %+v", alerts)
+	}
+}
+
+func TestManagerNotifyFailure(t *testing.T) {
+	n := &recorder{err: errors.New("connection refused")}
+	m := testManager(storage.NewMemory(), n)
+
+	on := degraded(true, notify.SeverityWarning)
+	if _, err := m.Process(context.Background(), []Signal{on}, start); err == nil {
+		t.Error("Expected the notification error, got nil")
+	}
+	// Not retried every cycle
+	if _, err := m.Process(context.Background(), []Signal{on}, start.Add(time.Hour)); err != nil || len(n.events) != 1 {
+		t.Errorf("Expected no retry, got %d events and error %v", len(n.events), err)
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	cfg := config.LoadWithDefaults()
+	m, err := NewManager(cfg, storage.NewMemory(), notify.Multi{})
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	if m.Cooldown != time.Hour || m.RepeatInterval != 24*time.Hour || m.FlapWindow != 6*time.Hour || m.FlapThreshold != 4 {
+		t.Errorf("Unexpected manager: %+v", m)
+	}
+
+	cfg.Alerts.FlapWindow = "often"
+	if _, err := NewManager(cfg, storage.NewMemory(), notify.Multi{}); err == nil {
+		t.Error("Expected error for invalid flap_window, got nil")
+	}
+}
@@ -0,0 +1,173 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cryptopatrick/ripley/internal/stats"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// MetricComparison compares one metric between a baseline and a later window.
+// Pass rates are compared with Fisher's exact test; tokens and latency with
+// the Mann-Whitney U test, summarized by their medians.
+type MetricComparison struct {
+	Metric      Metric
+	Baseline    float64 // Pass rate, or median for tokens and latency
+	Current     float64
+	PValue      float64
+	Significant bool // Significant at the comparison's alpha after Holm's correction
+}
+
+// Change returns the difference between the current and baseline values.
+func (c MetricComparison) Change() float64 {
+	return c.Current - c.Baseline
+}
+
+// Regressed reports whether the metric got significantly worse.
+func (c MetricComparison) Regressed() bool {
+	return c.Significant && c.Metric.Adverse(c.Change())
+}
+
+// BenchmarkComparison holds the metric comparisons of one benchmark.
+type BenchmarkComparison struct {
+	Benchmark    string
+	BaselineRuns int
+	CurrentRuns  int
+	Metrics      []MetricComparison
+}
+
+// Changed reports whether any metric changed significantly.
+func (c BenchmarkComparison) Changed() bool {
+	for _, m := range c.Metrics {
+		if m.Significant {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare tests every benchmark with runs on both sides for a significant
+// difference between baseline and current records. The family of tests is
+// held at level alpha with Holm's step-down correction, so adding benchmarks
+// does not add false positives; results are ordered by benchmark name.
+func Compare(baseline, current []storage.BenchmarkRecord, alpha float64) []BenchmarkComparison {
+	before, after := groupByName(baseline), groupByName(current)
+
+	var names []string
+	for name := range before {
+		if _, ok := after[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	comparisons := make([]BenchmarkComparison, len(names))
+	var tests []*MetricComparison
+	for i, name := range names {
+		b, c := before[name], after[name]
+		comparisons[i] = BenchmarkComparison{Benchmark: name, BaselineRuns: len(b), CurrentRuns: len(c)}
+		for _, metric := range Metrics {
+			comparisons[i].Metrics = append(comparisons[i].Metrics, compareMetric(metric, b, c))
+		}
+		for j := range comparisons[i].Metrics {
+			tests = append(tests, &comparisons[i].Metrics[j])
+		}
+	}
+	holm(tests, alpha)
+	return comparisons
+}
+
+// Tests returns the number of tests in a comparison, the size of the family
+// Holm's correction is applied to.
+func Tests(comparisons []BenchmarkComparison) int {
+	n := 0
+	for _, c := range comparisons {
+		n += len(c.Metrics)
+	}
+	return n
+}
+
+// holm marks the tests significant under Holm's step-down procedure: the
+// k-th smallest of n p-values must fall below alpha/(n-k+1), and testing
+// stops at the first that does not.
+func holm(tests []*MetricComparison, alpha float64) {
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].PValue < tests[j].PValue })
+	for k, m := range tests {
+		if m.PValue >= alpha/float64(len(tests)-k) {
+			return
+		}
+		m.Significant = true
+	}
+}
+
+// compareMetric runs the test suited to metric on two samples.
+func compareMetric(metric Metric, baseline, current []storage.BenchmarkRecord) MetricComparison {
+	if metric == MetricEffort {
+		baseline, current = scored(baseline), scored(current)
+	}
+	xs, ys := values(metric, baseline), values(metric, current)
+
+	if metric == MetricPassRate {
+		passedX, passedY := int(sum(xs)), int(sum(ys))
+		return MetricComparison{
+			Metric:   metric,
+			Baseline: stats.Mean(xs),
+			Current:  stats.Mean(ys),
+			PValue:   stats.FisherExact(passedX, len(xs)-passedX, passedY, len(ys)-passedY),
+		}
+	}
+
+	_, p := stats.MannWhitney(xs, ys)
+	return MetricComparison{
+		Metric:   metric,
+		Baseline: stats.Median(xs),
+		Current:  stats.Median(ys),
+		PValue:   p,
+	}
+}
+
+// DescribeComparison renders a metric comparison as a one-line summary.
+func DescribeComparison(c MetricComparison) string {
+	change := ""
+	if c.Baseline != 0 {
+		change = fmt.Sprintf(" (%+.0f%%)", c.Change()/c.Baseline*100)
+	}
+	return fmt.Sprintf("%s: %s → %s%s, p=%.3f",
+		c.Metric, c.Metric.Format(c.Baseline), c.Metric.Format(c.Current), change, c.PValue)
+}
+
+func groupByName(records []storage.BenchmarkRecord) map[string][]storage.BenchmarkRecord {
+	groups := make(map[string][]storage.BenchmarkRecord)
+	for _, r := range records {
+		groups[r.Name] = append(groups[r.Name], r)
+	}
+	return groups
+}
+
+func values(metric Metric, records []storage.BenchmarkRecord) []float64 {
+	xs := make([]float64, len(records))
+	for i, r := range records {
+		xs[i] = metric.Value(r)
+	}
+	return xs
+}
+
+// scored returns the records that have an effort score.
+func scored(records []storage.BenchmarkRecord) []storage.BenchmarkRecord {
+	var out []storage.BenchmarkRecord
+	for _, r := range records {
+		if r.Effort != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func sum(xs []float64) float64 {
+	var total float64
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
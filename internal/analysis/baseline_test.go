@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := series("Sum1to100", MetricTokens, []float64{7, 8, 7, 6, 7, 8, 7, 7, 6, 7})
+	current := series("Sum1to100", MetricTokens, []float64{12, 11, 13, 12, 12, 11, 13, 12, 12, 14})
+
+	// Pass rate drops from 10/10 to 3/10 in a second benchmark
+	baseline = append(baseline, series("ListReverse", MetricPassRate, []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1})...)
+	current = append(current, series("ListReverse", MetricPassRate, []float64{1, 0, 0, 1, 0, 0, 0, 1, 0, 0})...)
+
+	// Only in the baseline, so not compared
+	baseline = append(baseline, series("Retired", MetricTokens, []float64{1})...)
+
+	comparisons := Compare(baseline, current, 0.05)
+	if len(comparisons) != 2 {
+		t.Fatalf("Expected 2 benchmarks compared, got %+v", comparisons)
+	}
+
+	reverse, sum1to100 := comparisons[0], comparisons[1]
+	if reverse.Benchmark != "ListReverse" || sum1to100.Benchmark != "Sum1to100" {
+		t.Fatalf("Expected benchmarks ordered by name, got %s, %s", reverse.Benchmark, sum1to100.Benchmark)
+	}
+
+	for _, m := range sum1to100.Metrics {
+		switch m.Metric {
+		case MetricTokens:
+			if !m.Regressed() || m.Baseline != 7 || m.Current != 12 {
+				t.Errorf("Expected significant token regression 7 → 12, got %+v", m)
+			}
+		default:
+			if m.Significant {
+				t.Errorf("Expected %s unchanged, got %+v", m.Metric, m)
+			}
+		}
+	}
+
+	passRate := reverse.Metrics[0]
+	if passRate.Metric != MetricPassRate || !passRate.Regressed() {
+		t.Errorf("Expected significant pass rate regression, got %+v", passRate)
+	}
+	if passRate.PValue > 0.01 {
+		t.Errorf("Expected p < 0.01 for 10/10 vs 3/10, got %.4f", passRate.PValue)
+	}
+
+	if !strings.Contains(DescribeComparison(passRate), "100% → 30%") {
+		t.Errorf("Unexpected description: %s", DescribeComparison(passRate))
+	}
+}
+
+func TestCompareHolm(t *testing.T) {
+	// Each shift alone is significant at 0.05, but not across the family of
+	// six tests; a strong shift still is
+	baseline := series("ListReverse", MetricPassRate, []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+	current := series("ListReverse", MetricPassRate, []float64{1, 0, 0, 1, 0, 1, 0, 1, 0, 1})
+	baseline = append(baseline, series("Sum1to100", MetricTokens, []float64{7, 8, 7, 6, 7, 8, 7, 7, 6, 7})...)
+	current = append(current, series("Sum1to100", MetricTokens, []float64{12, 11, 13, 12, 12, 11, 13, 12, 12, 14})...)
+
+	comparisons := Compare(baseline, current, 0.05)
+	if n := Tests(comparisons); n != 2*len(Metrics) {
+		t.Fatalf("Expected %d tests, got %d", 2*len(Metrics), n)
+	}
+
+	passRate := comparisons[0].Metrics[0]
+	if passRate.PValue >= 0.05 || passRate.Significant {
+		t.Errorf("Expected 10/10 vs 5/10 to be nominally but not jointly significant, got %+v", passRate)
+	}
+	tokens := comparisons[1].Metrics[1]
+	if tokens.Metric != MetricTokens || !tokens.Regressed() {
+		t.Errorf("Expected significant token regression, got %+v", tokens)
+	}
+}
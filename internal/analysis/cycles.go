@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// infrastructureErrors are the error classes that point at the CLI or the
+// service rather than at the model: the CLI did not start, did not finish in
+// time, exited with an error or answered too slowly. A run over its token
+// budget is a quality failure.
+var infrastructureErrors = map[string]bool{
+	"exec_error":   true,
+	"timeout":      true,
+	"nonzero_exit": true,
+	"too_slow":     true,
+}
+
+// qualityErrors are the error classes caused by the answer itself.
+var qualityErrors = map[string]bool{
+	"over_budget": true,
+}
+
+// CycleClassifier labels benchmark cycles by correlating their failures.
+type CycleClassifier struct {
+	// OutageShare is the share (0.0-1.0) of a cycle's benchmarks that must
+	// fail on infrastructure errors for the cycle to be an outage.
+	OutageShare float64
+
+	// Quarantined benchmarks are left out of the classification.
+	Quarantined map[string]bool
+}
+
+// Classify labels the cycle runID from its records:
+//   - healthy: every benchmark passed
+//   - outage: at least OutageShare of the benchmarks failed on infrastructure errors
+//   - quality: most failures were caused by the answers themselves
+//   - partial: anything else, e.g. one benchmark timing out
+func (c CycleClassifier) Classify(runID string, records []storage.BenchmarkRecord) storage.Cycle {
+	cycle := storage.Cycle{RunID: runID, ErrorClasses: make(map[string]int)}
+	for i, r := range records {
+		if i == 0 || r.Timestamp.Before(cycle.StartedAt) {
+			cycle.StartedAt = r.Timestamp
+		}
+		cycle.Model = r.Model
+	}
+	records = withoutQuarantined(records, c.Quarantined)
+	cycle.Total = len(records)
+
+	var infra, quality int
+	for _, r := range records {
+		if r.Passed {
+			continue
+		}
+		cycle.Failed++
+		class := errorClass(r)
+		cycle.ErrorClasses[class]++
+		switch {
+		case infrastructureErrors[class]:
+			infra++
+		case qualityErrors[class]:
+			quality++
+		}
+	}
+
+	classes := DescribeErrorClasses(cycle.ErrorClasses)
+	switch {
+	case cycle.Failed == 0:
+		cycle.Label = storage.CycleHealthy
+		cycle.Reason = fmt.Sprintf("all %d benchmarks passed", cycle.Total)
+	case infra > 0 && float64(infra) >= c.OutageShare*float64(cycle.Total):
+		cycle.Label = storage.CycleOutage
+		cycle.Reason = fmt.Sprintf("%d/%d benchmarks failed on infrastructure errors (%s)", infra, cycle.Total, classes)
+	case quality*2 > cycle.Failed:
+		cycle.Label = storage.CycleQuality
+		cycle.Reason = fmt.Sprintf("%d/%d benchmarks failed on their answers (%s)", quality, cycle.Total, classes)
+	default:
+		cycle.Label = storage.CyclePartial
+		cycle.Reason = fmt.Sprintf("%d/%d benchmarks failed (%s)", cycle.Failed, cycle.Total, classes)
+	}
+	return cycle
+}
+
+// ClassifyRun classifies the cycle runID, stores the result and returns it.
+func ClassifyRun(db storage.Store, c CycleClassifier, runID string) (storage.Cycle, error) {
+	records, err := db.GetRun(runID)
+	if err != nil {
+		return storage.Cycle{}, err
+	}
+	if len(records) == 0 {
+		return storage.Cycle{}, fmt.Errorf("no results for run %s", runID)
+	}
+
+	cycle := c.Classify(runID, records)
+	if err := db.SaveCycle(cycle); err != nil {
+		return storage.Cycle{}, err
+	}
+	return cycle, nil
+}
+
+// DescribeCycle returns a one-line summary of a cycle classification.
+func DescribeCycle(c storage.Cycle) string {
+	var summary string
+	switch c.Label {
+	case storage.CycleOutage:
+		summary = "Outage, the CLI or service is likely down"
+	case storage.CycleQuality:
+		summary = "Quality regression"
+	case storage.CyclePartial:
+		summary = "Partial degradation"
+	default:
+		summary = "Healthy"
+	}
+	return fmt.Sprintf("%s in cycle %s (%s): %s", summary, c.RunID, c.StartedAt.Local().Format("2006-01-02 15:04:05"), c.Reason)
+}
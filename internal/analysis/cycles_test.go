@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestClassifyCycle(t *testing.T) {
+	c := CycleClassifier{OutageShare: 1}
+
+	tests := []struct {
+		name     string
+		classes  []string // Error class per benchmark; "" for a pass
+		expected string
+	}{
+		{"all passed", []string{"", "", "", ""}, storage.CycleHealthy},
+		{"everything timed out", []string{"timeout", "timeout", "exec_error", "timeout"}, storage.CycleOutage},
+		{"one timeout", []string{"", "timeout", "", ""}, storage.CyclePartial},
+		{"over budget", []string{"over_budget", "over_budget", "", "timeout"}, storage.CycleQuality},
+		{"all failed, mixed causes", []string{"over_budget", "timeout", "timeout", "timeout"}, storage.CyclePartial},
+		{"old failures", []string{"", "unknown", "", ""}, storage.CyclePartial},
+	}
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []storage.BenchmarkRecord
+			for i, class := range tt.classes {
+				records = append(records, storage.BenchmarkRecord{
+					Name:       fmt.Sprintf("B%d", i),
+					Passed:     class == "",
+					ErrorClass: class,
+					Timestamp:  base.Add(time.Duration(i) * time.Second),
+				})
+			}
+
+			cycle := c.Classify("run", records)
+			if cycle.Label != tt.expected {
+				t.Errorf("Expected %s, got %s (%s)", tt.expected, cycle.Label, cycle.Reason)
+			}
+			if cycle.Total != len(tt.classes) || !cycle.StartedAt.Equal(base) {
+				t.Errorf("Unexpected cycle totals: %+v", cycle)
+			}
+		})
+	}
+
+	// A lower share treats most benchmarks failing on infrastructure as an outage
+	lenient := CycleClassifier{OutageShare: 0.75}
+	records := []storage.BenchmarkRecord{
+		{Name: "A", ErrorClass: "timeout"}, {Name: "B", ErrorClass: "timeout"},
+		{Name: "C", ErrorClass: "nonzero_exit"}, {Name: "D", Passed: true},
+	}
+	if cycle := lenient.Classify("run", records); cycle.Label != storage.CycleOutage {
+		t.Errorf("Expected an outage at 3/4 failing, got %s", cycle.Label)
+	}
+}
+
+func TestOutageSuppressesBenchmarkIncidents(t *testing.T) {
+	db := storage.NewMemory()
+	tracker := IncidentTracker{ConsecutiveFailures: 2, SuiteFailureRate: 0.5}
+	classifier := CycleClassifier{OutageShare: 1}
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		runID := cycle(db, base.Add(time.Duration(i)*time.Hour), nil)
+		if _, err := ClassifyRun(db, classifier, runID); err != nil {
+			t.Fatalf("ClassifyRun failed: %v", err)
+		}
+		if _, err := tracker.Update(db, runID); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+
+	incidents, _ := db.ListIncidents("", time.Time{})
+	if len(incidents) != 1 || incidents[0].Scope != storage.ScopeSuite {
+		t.Fatalf("Expected only a suite incident during an outage, got %+v", incidents)
+	}
+	events, _ := db.ListIncidentEvents(incidents[0].ID)
+	if len(events) == 0 || !strings.HasPrefix(events[0].Message, "outage: ") {
+		t.Errorf("Expected the timeline to carry the cycle label, got %+v", events)
+	}
+
+	// Outage cycles are left out of rolling stats
+	if _, _, passRate, _ := db.GetRollingStats("", "A", 10); passRate != 0 {
+		t.Errorf("Expected no runs left in rolling stats, got pass rate %f", passRate)
+	}
+	if _, err := ClassifyRun(db, classifier, "missing"); err == nil {
+		t.Error("Expected error for an unknown run, got nil")
+	}
+}
@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Digest summarizes the results stored over a period, for periodic reports.
+type Digest struct {
+	From       time.Time
+	To         time.Time
+	Results    int
+	Passed     int
+	Benchmarks []BenchmarkDigest // By name
+	Effort     map[string]int    // Results per effort category; "" for unscored results
+	Cycles     map[string]int    // Cycles per label
+	Incidents  IncidentPeriod
+	Opened     []storage.Incident // Incidents started in the period, oldest first
+
+	// The results with the highest and the lowest effort score, for their
+	// quotes; nil without scored results
+	Best  *storage.BenchmarkRecord
+	Worst *storage.BenchmarkRecord
+}
+
+// BenchmarkDigest summarizes one benchmark over a digest period and the
+// period of the same length before it.
+type BenchmarkDigest struct {
+	Name            string
+	Results         int
+	Passed          int
+	AvgEffort       float64
+	PreviousResults int
+	PreviousPassed  int
+}
+
+// PassRate returns the share (0.0-1.0) of passed results in the period.
+func (b BenchmarkDigest) PassRate() float64 {
+	if b.Results == 0 {
+		return 0
+	}
+	return float64(b.Passed) / float64(b.Results)
+}
+
+// PreviousPassRate returns the pass rate over the previous period; ok is
+// false if there were no results then.
+func (b BenchmarkDigest) PreviousPassRate() (passRate float64, ok bool) {
+	if b.PreviousResults == 0 {
+		return 0, false
+	}
+	return float64(b.PreviousPassed) / float64(b.PreviousResults), true
+}
+
+// PassRate returns the share (0.0-1.0) of passed results in the period.
+func (d Digest) PassRate() float64 {
+	if d.Results == 0 {
+		return 0
+	}
+	return float64(d.Passed) / float64(d.Results)
+}
+
+// BuildDigest summarizes the results, cycles and incidents of model in [from, to).
+func BuildDigest(db storage.Store, model string, from, to time.Time) (Digest, error) {
+	d := Digest{From: from, To: to, Effort: make(map[string]int), Cycles: make(map[string]int)}
+
+	records, err := db.ListRecords(storage.RecordFilter{Model: model, Since: from, Until: to})
+	if err != nil {
+		return Digest{}, err
+	}
+	previous, err := db.ListRecords(storage.RecordFilter{Model: model, Since: from.Add(-to.Sub(from)), Until: from})
+	if err != nil {
+		return Digest{}, err
+	}
+
+	benchmarks := make(map[string]*BenchmarkDigest)
+	benchmark := func(name string) *BenchmarkDigest {
+		if benchmarks[name] == nil {
+			benchmarks[name] = &BenchmarkDigest{Name: name}
+		}
+		return benchmarks[name]
+	}
+
+	for i, r := range records {
+		b := benchmark(r.Name)
+		b.Results++
+		b.AvgEffort += r.EffortScore
+		d.Results++
+		if r.Passed {
+			b.Passed++
+			d.Passed++
+		}
+		d.Effort[r.Effort]++
+
+		if r.Effort == "" {
+			continue
+		}
+		if d.Best == nil || r.EffortScore > d.Best.EffortScore {
+			d.Best = &records[i]
+		}
+		if d.Worst == nil || r.EffortScore < d.Worst.EffortScore {
+			d.Worst = &records[i]
+		}
+	}
+	for _, r := range previous {
+		b := benchmark(r.Name)
+		b.PreviousResults++
+		if r.Passed {
+			b.PreviousPassed++
+		}
+	}
+
+	for _, b := range benchmarks {
+		if b.Results > 0 {
+			b.AvgEffort /= float64(b.Results)
+		}
+		d.Benchmarks = append(d.Benchmarks, *b)
+	}
+	slices.SortFunc(d.Benchmarks, func(a, b BenchmarkDigest) int { return strings.Compare(a.Name, b.Name) })
+
+	cycles, err := db.ListCycles(model, from)
+	if err != nil {
+		return Digest{}, err
+	}
+	for _, c := range cycles {
+		if c.StartedAt.Before(to) {
+			d.Cycles[c.Label]++
+		}
+	}
+
+	incidents, err := db.ListIncidents(model, from)
+	if err != nil {
+		return Digest{}, err
+	}
+	var inPeriod []storage.Incident
+	for _, inc := range incidents {
+		if !inc.StartedAt.Before(to) {
+			continue
+		}
+		inPeriod = append(inPeriod, inc)
+		if !inc.StartedAt.Before(from) {
+			d.Opened = append(d.Opened, inc)
+		}
+	}
+	slices.Reverse(d.Opened)
+	d.Incidents = summarizeIncidents(inPeriod, from, to)
+	return d, nil
+}
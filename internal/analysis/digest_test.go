@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestBuildDigest(t *testing.T) {
+	db := storage.NewMemory()
+	from := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	records := []storage.BenchmarkRecord{
+		// The day before: A passed once out of two
+		{Name: "A", Passed: true, Timestamp: from.Add(-10 * time.Hour)},
+		{Name: "A", Passed: false, Timestamp: from.Add(-5 * time.Hour)},
+		// The digest period
+		{RunID: "r1", Name: "A", Passed: true, EffortScore: 90, Effort: "good", Quote: "best", Timestamp: from.Add(time.Hour)},
+		{RunID: "r1", Name: "B", Passed: true, EffortScore: 70, Effort: "medium", Timestamp: from.Add(time.Hour)},
+		{RunID: "r2", Name: "A", Passed: true, EffortScore: 85, Effort: "good", Timestamp: from.Add(2 * time.Hour)},
+		{RunID: "r2", Name: "B", Passed: false, EffortScore: 20, Effort: "poor", Quote: "worst", Timestamp: from.Add(2 * time.Hour)},
+		// The day after
+		{Name: "B", Passed: false, Timestamp: to.Add(time.Hour)},
+	}
+	for _, r := range records {
+		db.InsertRecord(r)
+	}
+	db.SaveCycle(storage.Cycle{RunID: "r1", StartedAt: from.Add(time.Hour), Label: storage.CycleHealthy})
+	db.SaveCycle(storage.Cycle{RunID: "r2", StartedAt: from.Add(2 * time.Hour), Label: storage.CyclePartial})
+	db.InsertIncident(storage.Incident{Scope: "B", StartedAt: from.Add(2 * time.Hour), EndedAt: from.Add(3 * time.Hour)})
+	db.InsertIncident(storage.Incident{Scope: storage.ScopeSuite, StartedAt: from.Add(-48 * time.Hour), EndedAt: from.Add(-47 * time.Hour)})
+
+	d, err := BuildDigest(db, "", from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Results != 4 || d.Passed != 3 {
+		t.Errorf("Expected 3 of 4 results passed, got %d of %d", d.Passed, d.Results)
+	}
+	if d.Effort["good"] != 2 || d.Effort["medium"] != 1 || d.Effort["poor"] != 1 {
+		t.Errorf("Unexpected effort distribution: %v", d.Effort)
+	}
+	if d.Cycles[storage.CycleHealthy] != 1 || d.Cycles[storage.CyclePartial] != 1 {
+		t.Errorf("Unexpected cycles: %v", d.Cycles)
+	}
+	if len(d.Opened) != 1 || d.Opened[0].Scope != "B" || d.Incidents.Started != 1 || d.Incidents.MTTR != time.Hour {
+		t.Errorf("Expected one incident of B resolved in an hour, got %+v and %+v", d.Opened, d.Incidents)
+	}
+	if d.Best == nil || d.Best.Quote != "best" || d.Worst == nil || d.Worst.Quote != "worst" {
+		t.Errorf("Unexpected notable results: best %+v, worst %+v", d.Best, d.Worst)
+	}
+
+	if len(d.Benchmarks) != 2 || d.Benchmarks[0].Name != "A" {
+		t.Fatalf("Expected benchmarks A and B, got %+v", d.Benchmarks)
+	}
+	a := d.Benchmarks[0]
+	if previous, ok := a.PreviousPassRate(); !ok || previous != 0.5 || a.PassRate() != 1 || a.AvgEffort != 87.5 {
+		t.Errorf("Expected A at 100%% after 50%% with effort 87.5, got %+v", a)
+	}
+	if _, ok := d.Benchmarks[1].PreviousPassRate(); ok {
+		t.Error("Expected no previous pass rate for B")
+	}
+}
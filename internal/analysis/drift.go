@@ -0,0 +1,216 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// ansiEscape matches terminal color and cursor sequences.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// NormalizeAnswer removes the parts of an output that carry no meaning:
+// terminal escape sequences, line ending style, surrounding whitespace and
+// runs of whitespace. Case and punctuation are kept, since "105" and "105."
+// are different answer forms.
+func NormalizeAnswer(output string) string {
+	output = ansiEscape.ReplaceAllString(output, "")
+	return strings.Join(strings.Fields(output), " ")
+}
+
+// AnswerFingerprint identifies the normalized form of an output.
+func AnswerFingerprint(output string) string {
+	sum := sha256.Sum256([]byte(NormalizeAnswer(output)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// AnswerForm is one distinct normalized answer of a benchmark.
+type AnswerForm struct {
+	Hash      string
+	Answer    string // Normalized
+	Runs      int
+	Share     float64 // Fraction of the answers considered (0.0-1.0)
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// AnswerForms returns the distribution of answers in records, most common
+// first. Only passed runs are considered: failures are timeouts, errors or
+// over-budget answers rather than answer forms.
+func AnswerForms(records []storage.BenchmarkRecord) []AnswerForm {
+	byHash := make(map[string]*AnswerForm)
+	total := 0
+	for _, r := range records {
+		if !r.Passed {
+			continue
+		}
+		total++
+
+		hash := AnswerFingerprint(r.Output)
+		form, ok := byHash[hash]
+		if !ok {
+			form = &AnswerForm{Hash: hash, Answer: NormalizeAnswer(r.Output), FirstSeen: r.Timestamp, LastSeen: r.Timestamp}
+			byHash[hash] = form
+		}
+		form.Runs++
+		if r.Timestamp.Before(form.FirstSeen) {
+			form.FirstSeen = r.Timestamp
+		}
+		if r.Timestamp.After(form.LastSeen) {
+			form.LastSeen = r.Timestamp
+		}
+	}
+
+	forms := make([]AnswerForm, 0, len(byHash))
+	for _, form := range byHash {
+		form.Share = float64(form.Runs) / float64(total)
+		forms = append(forms, *form)
+	}
+	sort.Slice(forms, func(i, j int) bool {
+		if forms[i].Runs != forms[j].Runs {
+			return forms[i].Runs > forms[j].Runs
+		}
+		return forms[i].FirstSeen.Before(forms[j].FirstSeen)
+	})
+	return forms
+}
+
+// DriftDetector flags changes in the answers of a benchmark.
+type DriftDetector struct {
+	// Window is the number of recent answers the dominant form is taken
+	// from. New forms are only flagged once a full window has been seen, so
+	// the forms present from the start of the history are not reported.
+	Window int
+}
+
+// Detect returns the answer drift in records, which must belong to one
+// benchmark and be ordered oldest first. The forms of earlier, the records
+// of the benchmark before those, are known and never new. A dominant form
+// only changes when another form is strictly more common in the window, so
+// ties do not flap.
+func (d DriftDetector) Detect(benchmark string, earlier, records []storage.BenchmarkRecord, now time.Time) []storage.AnswerDrift {
+	window := max(d.Window, 1)
+
+	seen := make(map[string]string) // Hash to normalized answer
+	known := 0                      // Answers seen before records
+	for _, r := range earlier {
+		if r.Passed {
+			seen[AnswerFingerprint(r.Output)] = NormalizeAnswer(r.Output)
+			known++
+		}
+	}
+
+	var hashes, answers []string
+	var times []time.Time
+	for _, r := range records {
+		if r.Passed {
+			hashes = append(hashes, AnswerFingerprint(r.Output))
+			answers = append(answers, NormalizeAnswer(r.Output))
+			times = append(times, r.Timestamp)
+		}
+	}
+
+	var drift []storage.AnswerDrift
+	counts := make(map[string]int) // Hashes in the current window
+	dominant := ""
+	for i, hash := range hashes {
+		if _, ok := seen[hash]; !ok && known+i >= window {
+			drift = append(drift, storage.AnswerDrift{
+				Benchmark:    benchmark,
+				Kind:         storage.DriftNewForm,
+				Hash:         hash,
+				Answer:       answers[i],
+				PreviousHash: dominant,
+				Previous:     seen[dominant],
+				Share:        1 / float64(window),
+				OccurredAt:   times[i],
+				DetectedAt:   now,
+			})
+		}
+		seen[hash] = answers[i]
+
+		counts[hash]++
+		if i >= window {
+			counts[hashes[i-window]]--
+		}
+		if i+1 < window {
+			continue
+		}
+
+		mode := dominant
+		for h, n := range counts {
+			if n > counts[mode] || (n == counts[mode] && mode != dominant && h < mode) {
+				mode = h
+			}
+		}
+		switch {
+		case dominant == "":
+			dominant = mode
+		case mode != dominant:
+			drift = append(drift, storage.AnswerDrift{
+				Benchmark:    benchmark,
+				Kind:         storage.DriftDominantChanged,
+				Hash:         mode,
+				Answer:       seen[mode],
+				PreviousHash: dominant,
+				Previous:     seen[dominant],
+				Share:        float64(counts[mode]) / float64(window),
+				OccurredAt:   times[i],
+				DetectedAt:   now,
+			})
+			dominant = mode
+		}
+	}
+	return drift
+}
+
+// DetectAnswerDrift runs d over the latest history records of each benchmark
+// on model and stores the drift found. Forms from the history records before
+// those are known, so an old form coming back is not new, while the lookback
+// stays bounded as results pile up. It returns the drift that was not stored
+// before.
+func DetectAnswerDrift(db storage.Store, model string, d DriftDetector, benchmarks []string, history int, now time.Time) ([]storage.AnswerDrift, error) {
+	var found []storage.AnswerDrift
+	for _, name := range benchmarks {
+		records, err := db.ListRecords(storage.RecordFilter{Name: name, Model: model, Limit: history})
+		if err != nil {
+			return found, err
+		}
+
+		if len(records) == 0 {
+			continue
+		}
+		earlier, err := db.ListRecords(storage.RecordFilter{Name: name, Model: model, Until: records[0].Timestamp, Limit: history})
+		if err != nil {
+			return found, err
+		}
+
+		for _, drift := range d.Detect(name, earlier, records, now) {
+			drift.Model = model
+			inserted, err := db.InsertAnswerDrift(drift)
+			if err != nil {
+				return found, err
+			}
+			if inserted {
+				found = append(found, drift)
+			}
+		}
+	}
+	return found, nil
+}
+
+// DescribeDrift renders answer drift as a one-line summary.
+func DescribeDrift(d storage.AnswerDrift) string {
+	at := d.OccurredAt.Local().Format("2006-01-02 15:04")
+	if d.Kind == storage.DriftNewForm {
+		return fmt.Sprintf("%s new answer form %q at %s (dominant: %q)", d.Benchmark, d.Answer, at, d.Previous)
+	}
+	return fmt.Sprintf("%s dominant answer changed: %q → %q at %s (%.0f%% of recent answers)",
+		d.Benchmark, d.Previous, d.Answer, at, d.Share*100)
+}
@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// answers builds one passed record per output, an hour apart.
+func answers(name string, outputs ...string) []storage.BenchmarkRecord {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]storage.BenchmarkRecord, len(outputs))
+	for i, out := range outputs {
+		records[i] = storage.BenchmarkRecord{Name: name, Passed: true, Output: out, Timestamp: base.Add(time.Duration(i) * time.Hour)}
+	}
+	return records
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"105", "105"},
+		{"  105\r\n", "105"},
+		{"\x1b[32m105\x1b[0m", "105"},
+		{"The answer  is\n105", "The answer is 105"},
+		{"105.", "105."},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.output); got != tt.expected {
+			t.Errorf("NormalizeAnswer(%q) = %q, want %q", tt.output, got, tt.expected)
+		}
+	}
+
+	if AnswerFingerprint("105\n") != AnswerFingerprint(" 105") {
+		t.Error("Expected equal fingerprints for outputs that normalize alike")
+	}
+	if AnswerFingerprint("105") == AnswerFingerprint("105.") {
+		t.Error("Expected different fingerprints for different answer forms")
+	}
+}
+
+func TestAnswerForms(t *testing.T) {
+	records := answers("SimpleArithmetic", "105", "105", "105.", "105\n")
+	records = append(records, storage.BenchmarkRecord{Name: "SimpleArithmetic", Passed: false, Output: "Timed out"})
+
+	forms := AnswerForms(records)
+	if len(forms) != 2 {
+		t.Fatalf("Expected 2 answer forms, got %+v", forms)
+	}
+	if forms[0].Answer != "105" || forms[0].Runs != 3 || forms[0].Share != 0.75 {
+		t.Errorf("Unexpected dominant form: %+v", forms[0])
+	}
+	if !forms[0].LastSeen.Equal(records[3].Timestamp) {
+		t.Errorf("Expected last seen at run 3, got %v", forms[0].LastSeen)
+	}
+}
+
+func TestDetectAnswerDrift(t *testing.T) {
+	d := DriftDetector{Window: 4}
+
+	// Stable, then a one-off new form, then the answer format changes
+	records := answers("SimpleArithmetic",
+		"105", "105", "105", "105", "105", "105.", "105", "105",
+		"The answer is 105", "The answer is 105", "The answer is 105", "The answer is 105")
+
+	drift := d.Detect("SimpleArithmetic", nil, records, time.Now())
+	if len(drift) != 3 {
+		t.Fatalf("Expected 3 drift events, got %d: %+v", len(drift), drift)
+	}
+
+	if drift[0].Kind != storage.DriftNewForm || drift[0].Answer != "105." || !drift[0].OccurredAt.Equal(records[5].Timestamp) {
+		t.Errorf("Expected new form 105. at run 5, got %+v", drift[0])
+	}
+	if drift[1].Kind != storage.DriftNewForm || drift[1].Answer != "The answer is 105" {
+		t.Errorf("Expected new form 'The answer is 105', got %+v", drift[1])
+	}
+
+	changed := drift[2]
+	if changed.Kind != storage.DriftDominantChanged || changed.Previous != "105" || changed.Answer != "The answer is 105" {
+		t.Errorf("Expected dominant change 105 → 'The answer is 105', got %+v", changed)
+	}
+	// Ties keep the current dominant form, so the change lands on run 10
+	if !changed.OccurredAt.Equal(records[10].Timestamp) || changed.Share != 0.75 {
+		t.Errorf("Expected the change at run 10 with 75%% share, got %v with %.2f", changed.OccurredAt, changed.Share)
+	}
+
+	// Forms present from the start are not new
+	if drift := d.Detect("SimpleArithmetic", nil, answers("SimpleArithmetic", "105", "105.", "105", "105"), time.Now()); len(drift) != 0 {
+		t.Errorf("Expected no drift within the first window, got %+v", drift)
+	}
+
+	db := storage.NewMemory()
+	for _, r := range records {
+		db.InsertRecord(r)
+	}
+	found, err := DetectAnswerDrift(db, "", d, []string{"SimpleArithmetic"}, 100, time.Now())
+	if err != nil || len(found) != 3 {
+		t.Fatalf("Expected 3 new drift events, got %d (err %v)", len(found), err)
+	}
+	found, _ = DetectAnswerDrift(db, "", d, []string{"SimpleArithmetic"}, 100, time.Now())
+	if len(found) != 0 {
+		t.Errorf("Expected stored drift not to be reported again, got %+v", found)
+	}
+
+	if desc := DescribeDrift(changed); !strings.Contains(desc, `"105" → "The answer is 105"`) {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+}
+
+func TestDetectAnswerDriftRemembersOldForms(t *testing.T) {
+	d := DriftDetector{Window: 3}
+	db := storage.NewMemory()
+	// 105. was answered once, long before the last 5 runs
+	for _, r := range answers("SimpleArithmetic", "105.", "105", "105", "105", "105", "105", "105.", "The answer is 105") {
+		db.InsertRecord(r)
+	}
+
+	found, err := DetectAnswerDrift(db, "", d, []string{"SimpleArithmetic"}, 5, time.Now())
+	if err != nil {
+		t.Fatalf("DetectAnswerDrift failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Answer != "The answer is 105" {
+		t.Errorf("Expected only 'The answer is 105' to be new, got %+v", found)
+	}
+}
+
+func TestDetectAnswerDriftBoundsLookback(t *testing.T) {
+	d := DriftDetector{Window: 3}
+	db := storage.NewMemory()
+	// 105. was last answered more than history runs before the last 2
+	for _, r := range answers("SimpleArithmetic", "105.", "105", "105", "105", "105.") {
+		db.InsertRecord(r)
+	}
+
+	found, err := DetectAnswerDrift(db, "", d, []string{"SimpleArithmetic"}, 2, time.Now())
+	if err != nil {
+		t.Fatalf("DetectAnswerDrift failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Answer != "105." {
+		t.Errorf("Expected 105. to be new again beyond the lookback, got %+v", found)
+	}
+}
@@ -0,0 +1,220 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Alternation returns the share (0.0-1.0) of consecutive records whose
+// outcomes differ. A benchmark that passes and fails at random alternates
+// often; one that regressed once alternates only at the change.
+func Alternation(records []storage.BenchmarkRecord) float64 {
+	if len(records) < 2 {
+		return 0
+	}
+	flips := 0
+	for i := 1; i < len(records); i++ {
+		if records[i].Passed != records[i-1].Passed {
+			flips++
+		}
+	}
+	return float64(flips) / float64(len(records)-1)
+}
+
+// OutcomeEntropy returns the binary entropy of the outcomes in records, in
+// bits: 0 when they all pass or all fail, 1 when half of them pass.
+func OutcomeEntropy(records []storage.BenchmarkRecord) float64 {
+	if len(records) == 0 {
+		return 0
+	}
+	passed := 0
+	for _, r := range records {
+		if r.Passed {
+			passed++
+		}
+	}
+
+	p := float64(passed) / float64(len(records))
+	if p == 0 || p == 1 {
+		return 0
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p)
+}
+
+// FlakyDetector marks benchmarks as flaky when their recent outcomes both
+// alternate often and are mixed. A flaky benchmark stabilizes once either
+// measure drops below half its threshold, so it does not flap in and out.
+type FlakyDetector struct {
+	Window      int     // Recent results assessed per benchmark
+	MinRuns     int     // Benchmarks with fewer results are not flaky
+	Alternation float64 // Minimum alternation rate (0.0-1.0)
+	Entropy     float64 // Minimum outcome entropy in bits (0.0-1.0)
+	Quarantine  bool    // Quarantine flaky benchmarks
+}
+
+// NewFlakyDetector returns the detector configured in cfg.
+func NewFlakyDetector(cfg *config.Config) FlakyDetector {
+	return FlakyDetector{
+		Window:      cfg.Flaky.Window,
+		MinRuns:     cfg.Flaky.MinRuns,
+		Alternation: *cfg.Flaky.Alternation,
+		Entropy:     *cfg.Flaky.Entropy,
+		Quarantine:  cfg.Flaky.Quarantine,
+	}
+}
+
+// Assess returns the flakiness of a benchmark from its recent records,
+// oldest first, given its previous assessment (zero for none).
+func (d FlakyDetector) Assess(name string, records []storage.BenchmarkRecord, previous storage.Flakiness, now time.Time) storage.Flakiness {
+	f := storage.Flakiness{
+		Benchmark:   name,
+		Runs:        len(records),
+		Alternation: Alternation(records),
+		Entropy:     OutcomeEntropy(records),
+		Flaky:       previous.Flaky,
+		Since:       previous.Since,
+		UpdatedAt:   now,
+	}
+
+	switch {
+	case f.Runs < d.MinRuns:
+		f.Flaky = false
+	case previous.Flaky:
+		f.Flaky = f.Alternation >= d.Alternation/2 && f.Entropy >= d.Entropy/2
+	default:
+		f.Flaky = f.Alternation >= d.Alternation && f.Entropy >= d.Entropy
+	}
+
+	if f.Flaky != previous.Flaky || f.Since.IsZero() {
+		f.Since = now
+	}
+	f.Quarantined = f.Flaky && d.Quarantine
+	return f
+}
+
+// UpdateFlakiness assesses each benchmark on model, stores the assessments
+// and returns the ones that became flaky or stabilized. Results from outage
+// cycles are left out: an outage fails every benchmark at once and says
+// nothing about any one of them.
+func UpdateFlakiness(db storage.Store, model string, d FlakyDetector, benchmarks []string, now time.Time) ([]storage.Flakiness, error) {
+	assessments, err := db.ListFlakiness(model)
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]storage.Flakiness)
+	for _, f := range assessments {
+		previous[f.Benchmark] = f
+	}
+
+	outages := make(map[string]bool)
+	var changed []storage.Flakiness
+	for _, name := range benchmarks {
+		records, err := db.ListRecords(storage.RecordFilter{Name: name, Model: model, Limit: d.Window})
+		if err != nil {
+			return changed, err
+		}
+		records, err = withoutOutages(db, records, outages)
+		if err != nil {
+			return changed, err
+		}
+
+		f := d.Assess(name, records, previous[name], now)
+		f.Model = model
+		if err := db.SaveFlakiness(f); err != nil {
+			return changed, err
+		}
+		if f.Flaky != previous[name].Flaky {
+			changed = append(changed, f)
+		}
+	}
+	return changed, nil
+}
+
+// withoutOutages drops the records of outage cycles, caching the labels
+// looked up in outages by run ID.
+func withoutOutages(db storage.Store, records []storage.BenchmarkRecord, outages map[string]bool) ([]storage.BenchmarkRecord, error) {
+	var kept []storage.BenchmarkRecord
+	for _, r := range records {
+		if r.RunID == "" {
+			kept = append(kept, r)
+			continue
+		}
+		outage, ok := outages[r.RunID]
+		if !ok {
+			label, err := cycleLabel(db, r.RunID)
+			if err != nil {
+				return nil, err
+			}
+			outage = label == storage.CycleOutage
+			outages[r.RunID] = outage
+		}
+		if !outage {
+			kept = append(kept, r)
+		}
+	}
+	return kept, nil
+}
+
+// Quarantined returns the benchmarks currently quarantined on model.
+func Quarantined(db storage.Store, model string) (map[string]bool, error) {
+	assessments, err := db.ListFlakiness(model)
+	if err != nil {
+		return nil, err
+	}
+	quarantined := make(map[string]bool)
+	for _, f := range assessments {
+		if f.Quarantined {
+			quarantined[f.Benchmark] = true
+		}
+	}
+	return quarantined, nil
+}
+
+// Unquarantined returns the benchmarks in names that are not quarantined.
+func Unquarantined(names []string, quarantined map[string]bool) []string {
+	var kept []string
+	for _, name := range names {
+		if !quarantined[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
+
+// withoutQuarantined drops the records of quarantined benchmarks.
+func withoutQuarantined(records []storage.BenchmarkRecord, quarantined map[string]bool) []storage.BenchmarkRecord {
+	if len(quarantined) == 0 {
+		return records
+	}
+	var kept []storage.BenchmarkRecord
+	for _, r := range records {
+		if !quarantined[r.Name] {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// DescribeFlakiness returns a one-line summary of a flakiness assessment.
+func DescribeFlakiness(f storage.Flakiness) string {
+	state := "has stabilized"
+	if f.Flaky {
+		state = "is flaky"
+		if f.Quarantined {
+			state += " and quarantined"
+		}
+	}
+	return fmt.Sprintf("%s %s: alternation %.0f%%, entropy %.2f over %d runs",
+		f.Benchmark, state, f.Alternation*100, f.Entropy, f.Runs)
+}
+
+// outageSlack returns how many records (or runs) to fetch to be left with n
+// once outages are dropped by withoutOutages. Extra ones are fetched so a
+// few outage cycles do not leave the window short.
+func outageSlack(n int) int {
+	return 3 * n
+}
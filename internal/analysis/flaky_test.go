@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// outcomes builds one record per character of pattern, an hour apart:
+// "P" for a pass and "F" for a failure.
+func outcomes(name, pattern string) []storage.BenchmarkRecord {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]storage.BenchmarkRecord, len(pattern))
+	for i, c := range pattern {
+		records[i] = storage.BenchmarkRecord{
+			RunID:     fmt.Sprintf("run-%02d", i),
+			Name:      name,
+			Passed:    c == 'P',
+			Timestamp: base.Add(time.Duration(i) * time.Hour),
+		}
+	}
+	return records
+}
+
+func TestFlakinessMeasures(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		alternation float64
+		entropy     float64
+	}{
+		{"PPPPPPPPPP", 0, 0},
+		{"PPPPPFFFFF", 1.0 / 9, 1},
+		{"PFPFPFPFPF", 1, 1},
+		{"PPPF", 1.0 / 3, 0.8112781244591328},
+	}
+
+	for _, tt := range tests {
+		records := outcomes("A", tt.pattern)
+		if got := Alternation(records); math.Abs(got-tt.alternation) > 1e-9 {
+			t.Errorf("Expected alternation %f for %s, got %f", tt.alternation, tt.pattern, got)
+		}
+		if got := OutcomeEntropy(records); math.Abs(got-tt.entropy) > 1e-9 {
+			t.Errorf("Expected entropy %f for %s, got %f", tt.entropy, tt.pattern, got)
+		}
+	}
+}
+
+func TestFlakyDetector(t *testing.T) {
+	d := FlakyDetector{Window: 10, MinRuns: 6, Alternation: 0.3, Entropy: 0.7, Quarantine: true}
+	now := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	// A regression is not flakiness
+	if f := d.Assess("A", outcomes("A", "PPPPPFFFFF"), storage.Flakiness{}, now); f.Flaky {
+		t.Errorf("Expected a single shift not to be flaky, got %+v", f)
+	}
+	if f := d.Assess("A", outcomes("A", "PFPF"), storage.Flakiness{}, now); f.Flaky {
+		t.Errorf("Expected too few runs not to be flaky, got %+v", f)
+	}
+
+	flaky := d.Assess("A", outcomes("A", "PFPPFPFFPP"), storage.Flakiness{}, now)
+	if !flaky.Flaky || !flaky.Quarantined || !flaky.Since.Equal(now) {
+		t.Fatalf("Expected a flaky, quarantined benchmark, got %+v", flaky)
+	}
+
+	// Below the thresholds but above half of them, a flaky benchmark stays flaky
+	later := now.Add(time.Hour)
+	if f := d.Assess("A", outcomes("A", "PPPFPPPPFP"), flaky, later); !f.Flaky || !f.Since.Equal(now) {
+		t.Errorf("Expected the benchmark to stay flaky since %v, got %+v", now, f)
+	}
+	if f := d.Assess("A", outcomes("A", "PPPPPPPPPP"), flaky, later); f.Flaky || f.Quarantined || !f.Since.Equal(later) {
+		t.Errorf("Expected the benchmark to stabilize at %v, got %+v", later, f)
+	}
+
+	db := storage.NewMemory()
+	for _, r := range outcomes("A", "PFPPFPFFPP") {
+		db.InsertRecord(r)
+	}
+	// The outage fails B once; it is not flaky for it
+	for _, r := range outcomes("B", "PPPPPPFPPP") {
+		db.InsertRecord(r)
+	}
+	db.SaveCycle(storage.Cycle{RunID: "run-06", Label: storage.CycleOutage})
+
+	changed, err := UpdateFlakiness(db, "", d, []string{"A", "B"}, now)
+	if err != nil {
+		t.Fatalf("UpdateFlakiness failed: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Benchmark != "A" || changed[0].Runs != 9 {
+		t.Fatalf("Expected A to become flaky over 9 runs, got %+v", changed)
+	}
+	if changed, _ := UpdateFlakiness(db, "", d, []string{"A", "B"}, later); len(changed) != 0 {
+		t.Errorf("Expected no changes on reassessment, got %+v", changed)
+	}
+
+	quarantined, err := Quarantined(db, "")
+	if err != nil || !quarantined["A"] || quarantined["B"] {
+		t.Errorf("Expected only A quarantined, got %v (err %v)", quarantined, err)
+	}
+	if names := Unquarantined([]string{"A", "B"}, quarantined); len(names) != 1 || names[0] != "B" {
+		t.Errorf("Expected only B unquarantined, got %v", names)
+	}
+	if desc := DescribeFlakiness(changed[0]); !strings.Contains(desc, "A is flaky and quarantined") {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+}
+
+func TestQuarantineExcludesBenchmarks(t *testing.T) {
+	quarantined := map[string]bool{"B": true}
+
+	// B failing alone is not a degradation of the suite
+	records := []storage.BenchmarkRecord{{Name: "A", Passed: true}, {Name: "B", ErrorClass: "timeout"}}
+	c := CycleClassifier{OutageShare: 1, Quarantined: quarantined}
+	if cycle := c.Classify("run", records); cycle.Label != storage.CycleHealthy || cycle.Total != 1 {
+		t.Errorf("Expected a healthy cycle of 1 benchmark, got %+v", cycle)
+	}
+
+	db := storage.NewMemory()
+	tracker := IncidentTracker{ConsecutiveFailures: 2, SuiteFailureRate: 0.5, Quarantined: quarantined}
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		runID := cycle(db, base.Add(time.Duration(i)*time.Hour), map[string]bool{"A": true})
+		if changes, err := tracker.Update(db, runID); err != nil || len(changes) != 0 {
+			t.Errorf("Expected no incidents for a quarantined benchmark, got %+v (err %v)", changes, err)
+		}
+	}
+
+	e := SLOEvaluator{
+		Objectives: []Objective{
+			{Name: "suite", Target: 0.9, Window: 24 * time.Hour},
+			{Name: "b", Benchmark: "B", Target: 0.9, Window: 24 * time.Hour},
+		},
+		Quarantined: quarantined,
+	}
+	statuses, err := e.Evaluate(db, "", base.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if statuses[0].Runs != 3 || statuses[0].Bad != 0 {
+		t.Errorf("Expected the suite objective to leave B out, got %+v", statuses[0])
+	}
+	if statuses[1].Runs != 3 || statuses[1].Bad != 3 {
+		t.Errorf("Expected B's own objective to keep its runs, got %+v", statuses[1])
+	}
+}
@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/stats"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Bucket summarizes the results in one time bucket, with 95% confidence
+// intervals: Wilson intervals for the pass rate and normal intervals for
+// the means.
+type Bucket struct {
+	Runs         int
+	PassRate     float64
+	PassRateLow  float64
+	PassRateHigh float64
+	Scored       int // Runs with an effort score; older results have none
+	Effort       float64
+	EffortLow    float64
+	EffortHigh   float64
+	Latency      float64 // Mean seconds
+	LatencyLow   float64
+	LatencyHigh  float64
+}
+
+// Interval returns the value of metric in the bucket with its confidence
+// interval. Tokens are not summarized and return zeros.
+func (b Bucket) Interval(metric Metric) (value, lo, hi float64) {
+	switch metric {
+	case MetricPassRate:
+		return b.PassRate, b.PassRateLow, b.PassRateHigh
+	case MetricEffort:
+		return b.Effort, b.EffortLow, b.EffortHigh
+	case MetricLatency:
+		return b.Latency, b.LatencyLow, b.LatencyHigh
+	default:
+		return 0, 0, 0
+	}
+}
+
+// WorseThan reports whether the bucket is significantly worse than value:
+// its whole confidence interval lies on the adverse side of value. A single
+// value has no spread to judge it by, so it is never significant.
+func (b Bucket) WorseThan(metric Metric, value float64) bool {
+	n := b.Runs
+	if metric == MetricEffort {
+		n = b.Scored
+	}
+	if n < 2 {
+		return false
+	}
+	_, lo, hi := b.Interval(metric)
+	higherIsBetter := metric.Adverse(-1)
+	if higherIsBetter {
+		return hi < value
+	}
+	return lo > value
+}
+
+// HeatmapCell is the bucket of one hour on one weekday.
+type HeatmapCell struct {
+	Weekday time.Weekday
+	Hour    int
+	Bucket
+}
+
+// Heatmap buckets results by hour of day and weekday in a timezone.
+type Heatmap struct {
+	Timezone  string
+	Cells     []HeatmapCell // Buckets with results, Sunday first, then by hour
+	ByHour    [24]Bucket
+	ByWeekday [7]Bucket // Indexed by time.Weekday
+	Overall   Bucket
+}
+
+// Cell returns the bucket for an hour on a weekday, which is empty if no
+// results fell into it.
+func (h Heatmap) Cell(weekday time.Weekday, hour int) Bucket {
+	for _, c := range h.Cells {
+		if c.Weekday == weekday && c.Hour == hour {
+			return c.Bucket
+		}
+	}
+	return Bucket{}
+}
+
+// BuildHeatmap buckets records by the hour and weekday of their timestamp
+// in loc.
+func BuildHeatmap(records []storage.BenchmarkRecord, loc *time.Location) Heatmap {
+	var cells [7][24][]storage.BenchmarkRecord
+	var byHour [24][]storage.BenchmarkRecord
+	var byWeekday [7][]storage.BenchmarkRecord
+	for _, r := range records {
+		t := r.Timestamp.In(loc)
+		day, hour := t.Weekday(), t.Hour()
+		cells[day][hour] = append(cells[day][hour], r)
+		byHour[hour] = append(byHour[hour], r)
+		byWeekday[day] = append(byWeekday[day], r)
+	}
+
+	h := Heatmap{Timezone: loc.String(), Overall: summarize(records)}
+	for day := range cells {
+		for hour, rs := range cells[day] {
+			if len(rs) > 0 {
+				h.Cells = append(h.Cells, HeatmapCell{Weekday: time.Weekday(day), Hour: hour, Bucket: summarize(rs)})
+			}
+		}
+	}
+	for hour, rs := range byHour {
+		h.ByHour[hour] = summarize(rs)
+	}
+	for day, rs := range byWeekday {
+		h.ByWeekday[day] = summarize(rs)
+	}
+	return h
+}
+
+// summarize computes the bucket statistics of records.
+func summarize(records []storage.BenchmarkRecord) Bucket {
+	b := Bucket{Runs: len(records)}
+	if b.Runs == 0 {
+		return b
+	}
+
+	passed := 0
+	var latencies, efforts []float64
+	for _, r := range records {
+		if r.Passed {
+			passed++
+		}
+		latencies = append(latencies, r.Duration.Seconds())
+		if r.Effort != "" {
+			efforts = append(efforts, r.EffortScore)
+		}
+	}
+
+	b.PassRate = float64(passed) / float64(b.Runs)
+	b.PassRateLow, b.PassRateHigh = stats.Wilson(passed, b.Runs, stats.Z95)
+	b.Latency, b.LatencyLow, b.LatencyHigh = stats.MeanCI(latencies, stats.Z95)
+	b.Scored = len(efforts)
+	if b.Scored > 0 {
+		b.Effort, b.EffortLow, b.EffortHigh = stats.MeanCI(efforts, stats.Z95)
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestBuildHeatmap(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// Mondays at 10:00 New York time (14:00 UTC in March) fail half the
+	// time and are slow; Mondays at 03:00 always pass quickly.
+	var records []storage.BenchmarkRecord
+	base := time.Date(2025, 3, 10, 0, 0, 0, 0, ny) // A Monday after the DST switch
+	for week := 0; week < 10; week++ {
+		day := base.AddDate(0, 0, 7*week)
+		for i := 0; i < 4; i++ {
+			records = append(records, storage.BenchmarkRecord{
+				Name: "A", Passed: true, Duration: time.Second, EffortScore: 100, Effort: "good",
+				Timestamp: day.Add(3 * time.Hour).UTC(),
+			}, storage.BenchmarkRecord{
+				Name: "A", Passed: i%2 == 0, Duration: 3 * time.Second, EffortScore: 40, Effort: "poor",
+				Timestamp: day.Add(10 * time.Hour).UTC(),
+			})
+		}
+	}
+
+	h := BuildHeatmap(records, ny)
+	if h.Timezone != "America/New_York" || len(h.Cells) != 2 {
+		t.Fatalf("Expected 2 cells in America/New_York, got %d in %s", len(h.Cells), h.Timezone)
+	}
+
+	peak := h.Cell(time.Monday, 10)
+	if peak.Runs != 40 || peak.PassRate != 0.5 || peak.Latency != 3 || peak.Effort != 40 {
+		t.Errorf("Unexpected peak bucket: %+v", peak)
+	}
+	if peak.PassRateLow >= 0.5 || peak.PassRateHigh <= 0.5 {
+		t.Errorf("Expected the pass rate interval to contain 0.5, got [%.2f, %.2f]", peak.PassRateLow, peak.PassRateHigh)
+	}
+	if h.Cell(time.Monday, 14).Runs != 0 {
+		t.Error("Expected no results at 14:00 local time")
+	}
+
+	for _, metric := range []Metric{MetricPassRate, MetricEffort, MetricLatency} {
+		overall, _, _ := h.Overall.Interval(metric)
+		if !peak.WorseThan(metric, overall) {
+			t.Errorf("Expected the peak hour to be significantly worse in %s", metric)
+		}
+		if h.Cell(time.Monday, 3).WorseThan(metric, overall) {
+			t.Errorf("Expected the quiet hour not to be worse in %s", metric)
+		}
+	}
+
+	if h.ByHour[10].Runs != 40 || h.ByWeekday[time.Monday].Runs != 80 || h.Overall.Runs != 80 {
+		t.Errorf("Unexpected marginals: hour 10 %d, Monday %d, overall %d",
+			h.ByHour[10].Runs, h.ByWeekday[time.Monday].Runs, h.Overall.Runs)
+	}
+}
+
+func TestBucketWorseThanSingleRun(t *testing.T) {
+	// One slow, failed, poorly scored run
+	h := BuildHeatmap([]storage.BenchmarkRecord{
+		{Name: "A", Passed: false, Duration: 9 * time.Second, EffortScore: 10, Effort: "poor", Timestamp: time.Date(2025, 3, 10, 3, 0, 0, 0, time.UTC)},
+	}, time.UTC)
+	single := h.Cell(time.Monday, 3)
+	if single.Runs != 1 {
+		t.Fatalf("Expected one run in the bucket, got %+v", single)
+	}
+
+	for _, metric := range []Metric{MetricPassRate, MetricEffort, MetricLatency} {
+		baseline := map[Metric]float64{MetricPassRate: 0.9, MetricEffort: 80, MetricLatency: 1}[metric]
+		if single.WorseThan(metric, baseline) {
+			t.Errorf("Expected a single run not to be significantly worse in %s", metric)
+		}
+	}
+}
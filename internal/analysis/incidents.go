@@ -0,0 +1,460 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// IncidentTracker opens an incident when a benchmark fails
+// ConsecutiveFailures runs in a row, or when that many cycles in a row have
+// at least SuiteFailureRate of their benchmarks failing. An incident closes
+// on the first passed run, or the first cycle below the rate. During a cycle
+// classified as an outage (see CycleClassifier) no benchmark incidents are
+// opened: the suite incident covers it. Quarantined benchmarks (see
+// FlakyDetector) open no incidents and do not count towards the suite.
+type IncidentTracker struct {
+	ConsecutiveFailures int     // 0 opens no incidents
+	SuiteFailureRate    float64 // 0.0-1.0
+	Quarantined         map[string]bool
+}
+
+// IncidentChange is an incident opened or resolved by IncidentTracker.Update.
+type IncidentChange struct {
+	Incident storage.Incident
+	Resolved bool
+}
+
+// Update applies the results of the cycle runID to the incidents in db and
+// returns the incidents it opened or resolved. Earlier results are read back
+// from db, so the daemon can be restarted in the middle of an incident. Only
+// the incidents and history of the cycle's model are considered.
+func (t IncidentTracker) Update(db storage.Store, runID string) ([]IncidentChange, error) {
+	cycle, err := db.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cycle) == 0 {
+		return nil, nil
+	}
+	model := cycle[0].Model
+
+	// Incidents ended after the cycle started are listed too; keep the open ones
+	listed, err := db.ListIncidents(model, cycle[0].Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]storage.Incident)
+	for _, inc := range listed {
+		if inc.Open() {
+			open[inc.Scope] = inc
+		}
+	}
+
+	label, err := cycleLabel(db, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []IncidentChange
+	for _, r := range cycle {
+		change, err := t.updateBenchmark(db, open, r, label == storage.CycleOutage || t.Quarantined[r.Name])
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	suite := withoutQuarantined(cycle, t.Quarantined)
+	if len(suite) == 0 {
+		return changes, nil
+	}
+	change, err := t.updateSuite(db, open, runID, label, suite)
+	if err != nil {
+		return changes, err
+	}
+	if change != nil {
+		changes = append(changes, *change)
+	}
+	return changes, nil
+}
+
+// updateBenchmark applies one result to the incident of its benchmark. With
+// suppress set, an open incident is still updated but no new one is opened.
+func (t IncidentTracker) updateBenchmark(db storage.Store, open map[string]storage.Incident, r storage.BenchmarkRecord, suppress bool) (*IncidentChange, error) {
+	if inc, ok := open[r.Name]; ok {
+		if r.Passed {
+			return resolveIncident(db, inc, r.Timestamp, fmt.Sprintf("%s passed again", r.Name))
+		}
+		inc.ErrorClasses[errorClass(r)]++
+		if err := db.UpdateIncident(inc); err != nil {
+			return nil, err
+		}
+		return nil, addEvent(db, inc.ID, r.Timestamp, "failing", describeFailure(r))
+	}
+
+	if r.Passed || suppress || t.ConsecutiveFailures <= 0 {
+		return nil, nil
+	}
+	recent, err := db.ListRecords(storage.RecordFilter{Name: r.Name, Model: r.Model, Limit: t.ConsecutiveFailures})
+	if err != nil {
+		return nil, err
+	}
+	if len(recent) < t.ConsecutiveFailures {
+		return nil, nil
+	}
+	for _, rec := range recent {
+		if rec.Passed {
+			return nil, nil
+		}
+	}
+
+	// The incident starts at the first failure of the streak
+	inc := storage.Incident{
+		Model:        r.Model,
+		Scope:        r.Name,
+		StartedAt:    recent[0].Timestamp,
+		Benchmarks:   []string{r.Name},
+		ErrorClasses: make(map[string]int),
+	}
+	for _, rec := range recent {
+		inc.ErrorClasses[errorClass(rec)]++
+	}
+
+	var failures []storage.IncidentEvent
+	for _, rec := range recent {
+		failures = append(failures, storage.IncidentEvent{At: rec.Timestamp, Kind: "failing", Message: describeFailure(rec)})
+	}
+	message := fmt.Sprintf("%s failed %d runs in a row", r.Name, len(recent))
+	return openIncident(db, inc, failures, r.Timestamp, message)
+}
+
+// updateSuite applies a whole cycle, without quarantined benchmarks, to the
+// suite incident.
+func (t IncidentTracker) updateSuite(db storage.Store, open map[string]storage.Incident, runID, label string, cycle []storage.BenchmarkRecord) (*IncidentChange, error) {
+	end := cycle[len(cycle)-1].Timestamp
+	failed := failedBenchmarks(cycle)
+
+	if inc, ok := open[storage.ScopeSuite]; ok {
+		if !t.cycleFailed(len(failed), len(cycle)) {
+			return resolveIncident(db, inc, end, fmt.Sprintf("%d/%d benchmarks passed", len(cycle)-len(failed), len(cycle)))
+		}
+		addFailures(&inc, cycle)
+		if err := db.UpdateIncident(inc); err != nil {
+			return nil, err
+		}
+		return nil, addEvent(db, inc.ID, end, "failing", labeled(label, describeFailedCycle(failed, len(cycle))))
+	}
+
+	if !t.cycleFailed(len(failed), len(cycle)) || t.ConsecutiveFailures <= 0 {
+		return nil, nil
+	}
+	model := cycle[0].Model
+	runs, err := db.ListRuns(model, t.ConsecutiveFailures)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) < t.ConsecutiveFailures || runs[0].RunID != runID {
+		return nil, nil
+	}
+	history := make([][]storage.BenchmarkRecord, len(runs))
+	for i, run := range runs {
+		records, err := db.GetRun(run.RunID)
+		if err != nil {
+			return nil, err
+		}
+		history[i] = withoutQuarantined(records, t.Quarantined)
+		if !t.cycleFailed(len(failedBenchmarks(history[i])), len(history[i])) {
+			return nil, nil
+		}
+	}
+
+	// runs is newest first; the incident starts with the first failed cycle
+	inc := storage.Incident{
+		Model:        model,
+		Scope:        storage.ScopeSuite,
+		StartedAt:    runs[len(runs)-1].StartedAt,
+		ErrorClasses: make(map[string]int),
+	}
+	var failures []storage.IncidentEvent
+	for i := len(runs) - 1; i >= 0; i-- {
+		records := history[i]
+		addFailures(&inc, records)
+		runLabel, err := cycleLabel(db, runs[i].RunID)
+		if err != nil {
+			return nil, err
+		}
+		message := labeled(runLabel, describeFailedCycle(failedBenchmarks(records), len(records)))
+		failures = append(failures, storage.IncidentEvent{At: runs[i].EndedAt, Kind: "failing", Message: message})
+	}
+	message := fmt.Sprintf("%d cycles in a row with at least %.0f%% of benchmarks failing", len(runs), t.SuiteFailureRate*100)
+	return openIncident(db, inc, failures, end, message)
+}
+
+// cycleFailed reports whether a cycle with failed of total benchmarks failing
+// counts as a suite failure.
+func (t IncidentTracker) cycleFailed(failed, total int) bool {
+	return failed > 0 && float64(failed) >= t.SuiteFailureRate*float64(total)
+}
+
+// openIncident stores inc with the failures that led to it and an "opened" event.
+func openIncident(db storage.Store, inc storage.Incident, failures []storage.IncidentEvent, at time.Time, message string) (*IncidentChange, error) {
+	id, err := db.InsertIncident(inc)
+	if err != nil {
+		return nil, err
+	}
+	inc.ID = id
+
+	for _, e := range failures {
+		if err := addEvent(db, id, e.At, e.Kind, e.Message); err != nil {
+			return nil, err
+		}
+	}
+	if err := addEvent(db, id, at, "opened", message); err != nil {
+		return nil, err
+	}
+	return &IncidentChange{Incident: inc}, nil
+}
+
+// resolveIncident closes inc at at.
+func resolveIncident(db storage.Store, inc storage.Incident, at time.Time, message string) (*IncidentChange, error) {
+	inc.EndedAt = at
+	if err := db.UpdateIncident(inc); err != nil {
+		return nil, err
+	}
+	if err := addEvent(db, inc.ID, at, "resolved", message); err != nil {
+		return nil, err
+	}
+	return &IncidentChange{Incident: inc, Resolved: true}, nil
+}
+
+func addEvent(db storage.Store, incidentID int64, at time.Time, kind, message string) error {
+	return db.InsertIncidentEvent(storage.IncidentEvent{IncidentID: incidentID, At: at, Kind: kind, Message: message})
+}
+
+// addFailures adds the failed benchmarks of records and their error classes to inc.
+func addFailures(inc *storage.Incident, records []storage.BenchmarkRecord) {
+	for _, r := range records {
+		if r.Passed {
+			continue
+		}
+		inc.ErrorClasses[errorClass(r)]++
+		if !slices.Contains(inc.Benchmarks, r.Name) {
+			inc.Benchmarks = append(inc.Benchmarks, r.Name)
+		}
+	}
+	sort.Strings(inc.Benchmarks)
+}
+
+func failedBenchmarks(records []storage.BenchmarkRecord) []string {
+	var failed []string
+	for _, r := range records {
+		if !r.Passed {
+			failed = append(failed, r.Name)
+		}
+	}
+	return failed
+}
+
+// errorClass returns the error class of a failed record; results stored
+// before error classes were recorded are "unknown".
+func errorClass(r storage.BenchmarkRecord) string {
+	if r.ErrorClass == "" {
+		return "unknown"
+	}
+	return r.ErrorClass
+}
+
+func describeFailure(r storage.BenchmarkRecord) string {
+	return fmt.Sprintf("%s failed (%s)", r.Name, errorClass(r))
+}
+
+// cycleLabel returns the label of a classified cycle, or "" if it was not classified.
+func cycleLabel(db storage.Store, runID string) (string, error) {
+	c, err := db.GetCycle(runID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+	return c.Label, err
+}
+
+// labeled prefixes message with a cycle label, if there is one.
+func labeled(label, message string) string {
+	if label == "" {
+		return message
+	}
+	return label + ": " + message
+}
+
+func describeFailedCycle(failed []string, total int) string {
+	return fmt.Sprintf("%d/%d benchmarks failed: %s", len(failed), total, strings.Join(failed, ", "))
+}
+
+// DescribeIncident returns a one-line summary of an incident.
+func DescribeIncident(inc storage.Incident, now time.Time) string {
+	scope := inc.Scope
+	if scope == storage.ScopeSuite {
+		if len(inc.Benchmarks) > 0 {
+			scope += " (" + strings.Join(inc.Benchmarks, ", ") + ")"
+		}
+	}
+
+	state := fmt.Sprintf("resolved after %s", inc.Duration(now).Round(time.Second))
+	if inc.Open() {
+		state = fmt.Sprintf("ongoing for %s", inc.Duration(now).Round(time.Second))
+	}
+	return fmt.Sprintf("Incident #%d %s: since %s, %s | %s", inc.ID, scope,
+		inc.StartedAt.Local().Format("2006-01-02 15:04"), state, DescribeErrorClasses(inc.ErrorClasses))
+}
+
+// DescribeErrorClasses lists error classes by count, most common first.
+func DescribeErrorClasses(classes map[string]int) string {
+	names := make([]string, 0, len(classes))
+	for class := range classes {
+		names = append(names, class)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if classes[names[i]] != classes[names[j]] {
+			return classes[names[i]] > classes[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, class := range names {
+		parts[i] = fmt.Sprintf("%s ×%d", class, classes[class])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// IncidentPeriod summarizes the incidents of a period.
+type IncidentPeriod struct {
+	Start    time.Time
+	End      time.Time
+	Started  int           // Incidents started in the period
+	Resolved int           // Of those, the resolved ones
+	MTTR     time.Duration // Mean time to resolve the resolved ones
+	Downtime time.Duration // Time in the period covered by a suite incident
+	Degraded time.Duration // Time in the period covered by any incident
+}
+
+// Uptime returns the fraction of the period (0.0-1.0) without a suite incident.
+func (p IncidentPeriod) Uptime() float64 {
+	length := p.End.Sub(p.Start)
+	if length <= 0 {
+		return 1
+	}
+	return 1 - float64(p.Downtime)/float64(length)
+}
+
+// Healthy returns the fraction of the period (0.0-1.0) without any incident.
+func (p IncidentPeriod) Healthy() float64 {
+	length := p.End.Sub(p.Start)
+	if length <= 0 {
+		return 1
+	}
+	return 1 - float64(p.Degraded)/float64(length)
+}
+
+// IncidentReport summarizes incidents by calendar month in loc.
+type IncidentReport struct {
+	Months []IncidentPeriod // Oldest first, clipped to the report period
+	Total  IncidentPeriod
+}
+
+// ReportIncidents summarizes incidents between from and to by calendar month
+// in loc. Incidents still open count as lasting until to.
+func ReportIncidents(incidents []storage.Incident, from, to time.Time, loc *time.Location) IncidentReport {
+	report := IncidentReport{Total: summarizeIncidents(incidents, from, to)}
+
+	from, to = from.In(loc), to.In(loc)
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, loc)
+	for month.Before(to) {
+		next := month.AddDate(0, 1, 0)
+		start, end := month, next
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		report.Months = append(report.Months, summarizeIncidents(incidents, start, end))
+		month = next
+	}
+	return report
+}
+
+func summarizeIncidents(incidents []storage.Incident, start, end time.Time) IncidentPeriod {
+	p := IncidentPeriod{Start: start, End: end}
+
+	var suite, all [][2]time.Time
+	var resolveTime time.Duration
+	for _, inc := range incidents {
+		if !inc.StartedAt.Before(start) && inc.StartedAt.Before(end) {
+			p.Started++
+			if !inc.Open() {
+				p.Resolved++
+				resolveTime += inc.EndedAt.Sub(inc.StartedAt)
+			}
+		}
+
+		incEnd := inc.EndedAt
+		if inc.Open() {
+			incEnd = end
+		}
+		span := [2]time.Time{inc.StartedAt, incEnd}
+		all = append(all, span)
+		if inc.Scope == storage.ScopeSuite {
+			suite = append(suite, span)
+		}
+	}
+	if p.Resolved > 0 {
+		p.MTTR = resolveTime / time.Duration(p.Resolved)
+	}
+	p.Downtime = coverage(suite, start, end)
+	p.Degraded = coverage(all, start, end)
+	return p
+}
+
+// coverage returns how much of [start, end) is covered by the union of spans.
+func coverage(spans [][2]time.Time, start, end time.Time) time.Duration {
+	var clipped [][2]time.Time
+	for _, s := range spans {
+		if s[0].Before(start) {
+			s[0] = start
+		}
+		if s[1].After(end) {
+			s[1] = end
+		}
+		if s[0].Before(s[1]) {
+			clipped = append(clipped, s)
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool { return clipped[i][0].Before(clipped[j][0]) })
+
+	var total time.Duration
+	var cur [2]time.Time
+	for i, s := range clipped {
+		if i > 0 && !s[0].After(cur[1]) {
+			if s[1].After(cur[1]) {
+				cur[1] = s[1]
+			}
+			continue
+		}
+		if i > 0 {
+			total += cur[1].Sub(cur[0])
+		}
+		cur = s
+	}
+	if len(clipped) > 0 {
+		total += cur[1].Sub(cur[0])
+	}
+	return total
+}
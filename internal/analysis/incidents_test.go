@@ -0,0 +1,131 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// cycle stores one run of the given benchmarks at t; failing ones time out.
+func cycle(db storage.Store, t time.Time, passed map[string]bool) string {
+	runID := t.UTC().Format(time.RFC3339)
+	for i, name := range []string{"A", "B"} {
+		r := storage.BenchmarkRecord{RunID: runID, Name: name, Passed: passed[name], Timestamp: t.Add(time.Duration(i) * time.Second)}
+		if !r.Passed {
+			r.ErrorClass = "timeout"
+		}
+		db.InsertRecord(r)
+	}
+	return runID
+}
+
+func TestIncidentTracker(t *testing.T) {
+	db := storage.NewMemory()
+	tracker := IncidentTracker{ConsecutiveFailures: 2, SuiteFailureRate: 0.5}
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		passed   map[string]bool
+		expected []string // Scope of each change, prefixed with "+" when opened and "-" when resolved
+	}{
+		{map[string]bool{"A": true, "B": true}, nil},
+		{map[string]bool{"A": true, "B": false}, nil},
+		{map[string]bool{"A": true, "B": false}, []string{"+B", "+suite"}},
+		{map[string]bool{"A": false, "B": false}, nil},
+		{map[string]bool{"A": true, "B": true}, []string{"-B", "-suite"}},
+	}
+
+	for i, step := range steps {
+		runID := cycle(db, base.Add(time.Duration(i)*time.Hour), step.passed)
+		changes, err := tracker.Update(db, runID)
+		if err != nil {
+			t.Fatalf("Update failed at cycle %d: %v", i, err)
+		}
+
+		var got []string
+		for _, c := range changes {
+			prefix := "+"
+			if c.Resolved {
+				prefix = "-"
+			}
+			got = append(got, prefix+c.Incident.Scope)
+		}
+		if strings.Join(got, " ") != strings.Join(step.expected, " ") {
+			t.Errorf("Cycle %d: expected changes %v, got %v", i, step.expected, got)
+		}
+	}
+
+	incidents, _ := db.ListIncidents("", time.Time{})
+	if len(incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %+v", incidents)
+	}
+	// B runs a second into each cycle
+	firstFailure := map[string]time.Time{"B": base.Add(time.Hour + time.Second), storage.ScopeSuite: base.Add(time.Hour)}
+	for _, inc := range incidents {
+		if inc.Open() || !inc.StartedAt.Equal(firstFailure[inc.Scope]) {
+			t.Errorf("Expected a resolved incident starting at the first failure, got %+v", inc)
+		}
+	}
+
+	suite := incidents[0]
+	if suite.Scope != storage.ScopeSuite {
+		suite = incidents[1]
+	}
+	if strings.Join(suite.Benchmarks, ",") != "A,B" || suite.ErrorClasses["timeout"] != 4 {
+		t.Errorf("Expected the suite incident to cover A and B with 4 timeouts, got %+v", suite)
+	}
+	if suite.Duration(time.Now()) != 3*time.Hour+time.Second {
+		t.Errorf("Expected the suite incident to last until the end of the recovered cycle, got %s", suite.Duration(time.Now()))
+	}
+
+	events, _ := db.ListIncidentEvents(suite.ID)
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	if strings.Join(kinds, " ") != "failing failing opened failing resolved" {
+		t.Errorf("Unexpected suite timeline: %v", kinds)
+	}
+
+	if desc := DescribeIncident(suite, time.Now()); !strings.Contains(desc, "suite (A, B)") || !strings.Contains(desc, "timeout ×4") {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+}
+
+func TestReportIncidents(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	incidents := []storage.Incident{
+		// Crosses into February
+		{Scope: storage.ScopeSuite, StartedAt: from.Add(30 * day), EndedAt: from.Add(32 * day)},
+		// Overlaps the suite incident
+		{Scope: "A", StartedAt: from.Add(31 * day), EndedAt: from.Add(33 * day)},
+		// Still open
+		{Scope: "B", StartedAt: to.Add(-day)},
+	}
+
+	report := ReportIncidents(incidents, from, to, time.UTC)
+	if len(report.Months) != 2 {
+		t.Fatalf("Expected 2 months, got %d", len(report.Months))
+	}
+
+	jan, feb := report.Months[0], report.Months[1]
+	if jan.Started != 1 || jan.Resolved != 1 || jan.MTTR != 2*day || jan.Downtime != day {
+		t.Errorf("Unexpected January: %+v", jan)
+	}
+	if feb.Started != 2 || feb.Resolved != 1 || feb.MTTR != 2*day || feb.Downtime != day || feb.Degraded != 3*day {
+		t.Errorf("Unexpected February: %+v", feb)
+	}
+	if uptime := feb.Uptime(); uptime != 27.0/28 {
+		t.Errorf("Expected February uptime 27/28, got %f", uptime)
+	}
+
+	total := report.Total
+	if total.Started != 3 || total.Resolved != 2 || total.Downtime != 2*day || total.Degraded != 4*day {
+		t.Errorf("Unexpected total: %+v", total)
+	}
+}
@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/cryptopatrick/ripley/internal/stats"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// ModelMetrics lists the metrics compared between models.
+var ModelMetrics = []Metric{MetricPassRate, MetricEffort, MetricTokens, MetricLatency}
+
+// ModelSummary describes the results of one model on one benchmark.
+type ModelSummary struct {
+	Model      string
+	Runs       int
+	PassRate   float64
+	Effort     float64 // Median effort score of the scored runs
+	Tokens     float64 // Median
+	Latency    float64 // Median seconds
+	Priced     bool    // Whether a price is configured for the model
+	CostPerRun float64 // USD, from the mean tokens per run
+	Cost       float64 // USD for all runs in the window
+
+	// Differences compares each of ModelMetrics against the reference model;
+	// nil for the reference model itself.
+	Differences []MetricComparison
+}
+
+// Difference returns the comparison of metric against the reference model.
+func (s ModelSummary) Difference(metric Metric) (MetricComparison, bool) {
+	for _, d := range s.Differences {
+		if d.Metric == metric {
+			return d, true
+		}
+	}
+	return MetricComparison{}, false
+}
+
+// ModelReport lines up the models that ran one benchmark.
+type ModelReport struct {
+	Benchmark string
+	Reference string         // Model the others are tested against
+	Models    []ModelSummary // Reference first, then by name
+}
+
+// CompareModels summarizes every benchmark per model and tests each model
+// against the reference model at level alpha, with the tests Compare uses.
+// If the reference did not run a benchmark, the first model by name is used
+// instead. pricing maps model names to USD per million tokens.
+func CompareModels(records []storage.BenchmarkRecord, reference string, pricing map[string]float64, alpha float64) []ModelReport {
+	byBenchmark := groupByName(records)
+	names := make([]string, 0, len(byBenchmark))
+	for name := range byBenchmark {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reports := make([]ModelReport, 0, len(names))
+	for _, name := range names {
+		byModel := make(map[string][]storage.BenchmarkRecord)
+		for _, r := range byBenchmark[name] {
+			byModel[r.Model] = append(byModel[r.Model], r)
+		}
+
+		models := make([]string, 0, len(byModel))
+		for model := range byModel {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+
+		ref := reference
+		if _, ok := byModel[ref]; !ok {
+			ref = models[0]
+		}
+		sort.SliceStable(models, func(i, j int) bool { return models[i] == ref && models[j] != ref })
+
+		report := ModelReport{Benchmark: name, Reference: ref}
+		for _, model := range models {
+			summary := summarizeModel(model, byModel[model], pricing)
+			if model != ref {
+				for _, metric := range ModelMetrics {
+					d := compareMetric(metric, byModel[ref], byModel[model])
+					d.Significant = d.PValue < alpha
+					summary.Differences = append(summary.Differences, d)
+				}
+			}
+			report.Models = append(report.Models, summary)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// summarizeModel computes the summary of one model's records.
+func summarizeModel(model string, records []storage.BenchmarkRecord, pricing map[string]float64) ModelSummary {
+	tokens := values(MetricTokens, records)
+	s := ModelSummary{
+		Model:    model,
+		Runs:     len(records),
+		PassRate: stats.Mean(values(MetricPassRate, records)),
+		Effort:   stats.Median(values(MetricEffort, scored(records))),
+		Tokens:   stats.Median(tokens),
+		Latency:  stats.Median(values(MetricLatency, records)),
+	}
+
+	if price, ok := pricing[model]; ok {
+		s.Priced = true
+		s.Cost = sum(tokens) * price / 1e6
+		s.CostPerRun = s.Cost / float64(len(records))
+	}
+	return s
+}
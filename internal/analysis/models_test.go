@@ -0,0 +1,175 @@
+package analysis
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestCompareModels(t *testing.T) {
+	var records []storage.BenchmarkRecord
+	add := func(model string, passed bool, tokens int) {
+		records = append(records, storage.BenchmarkRecord{
+			Name: "Sum1to100", Model: model, Passed: passed, TokensUsed: tokens,
+			Duration: time.Second, EffortScore: 90, Effort: "good",
+		})
+	}
+	for i := 0; i < 12; i++ {
+		add("Sonnet", true, 4+i%2)
+		add("Opus", true, 4+i%2)
+		add("Haiku", i%3 == 0, 10+i%2)
+	}
+	// Only Haiku ran this one
+	records = append(records, storage.BenchmarkRecord{Name: "ListReverse", Model: "Haiku", Passed: true})
+
+	reports := CompareModels(records, "Sonnet", map[string]float64{"Sonnet": 15, "Haiku": 4}, 0.05)
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 benchmark reports, got %d", len(reports))
+	}
+
+	if reports[0].Benchmark != "ListReverse" || reports[0].Reference != "Haiku" || len(reports[0].Models) != 1 {
+		t.Errorf("Expected Haiku as the fallback reference for ListReverse, got %+v", reports[0])
+	}
+
+	report := reports[1]
+	if report.Reference != "Sonnet" || len(report.Models) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Models[0].Model != "Sonnet" || report.Models[1].Model != "Haiku" || report.Models[2].Model != "Opus" {
+		t.Errorf("Expected the reference first, then models by name, got %s, %s, %s",
+			report.Models[0].Model, report.Models[1].Model, report.Models[2].Model)
+	}
+
+	sonnet, haiku, opus := report.Models[0], report.Models[1], report.Models[2]
+	if sonnet.Differences != nil {
+		t.Errorf("Expected no differences for the reference, got %+v", sonnet.Differences)
+	}
+	if !sonnet.Priced || sonnet.CostPerRun != 4.5*15/1e6 {
+		t.Errorf("Expected Sonnet cost per run of %g, got %g", 4.5*15/1e6, sonnet.CostPerRun)
+	}
+	if opus.Priced {
+		t.Error("Expected Opus to be unpriced")
+	}
+
+	if d, _ := haiku.Difference(MetricPassRate); !d.Regressed() {
+		t.Errorf("Expected Haiku's pass rate to be significantly lower, got %+v", d)
+	}
+	if d, _ := haiku.Difference(MetricTokens); !d.Significant || d.Current != 10.5 {
+		t.Errorf("Expected Haiku's tokens to differ significantly, got %+v", d)
+	}
+	for _, d := range opus.Differences {
+		if d.Significant {
+			t.Errorf("Expected Opus to match Sonnet, got %+v", d)
+		}
+	}
+}
+
+// forModel tags records as model's, with run IDs of their own, as a second
+// daemon sharing the store would store them.
+func forModel(model string, records []storage.BenchmarkRecord) []storage.BenchmarkRecord {
+	for i := range records {
+		records[i].Model = model
+		records[i].RunID = model + "-" + records[i].RunID
+		if records[i].Passed {
+			records[i].Output = "5050"
+		}
+	}
+	return records
+}
+
+func TestModelsKeepSeparateState(t *testing.T) {
+	db := storage.NewMemory()
+	opusB := forModel("Opus", outcomes("B", "PFPFPFPFPFPFPFPFPFPF"))
+	for i := 10; i < len(opusB); i++ {
+		if opusB[i].Passed {
+			opusB[i].Output = "The answer is 5050"
+		}
+	}
+	// Sonnet is healthy; Opus regresses on A and is flaky with a new answer form on B
+	for _, r := range slices.Concat(
+		forModel("Sonnet", outcomes("A", "PPPPPPPPPPPPPPPPPPPP")),
+		forModel("Sonnet", outcomes("B", "PPPPPPPPPPPPPPPPPPPP")),
+		forModel("Opus", outcomes("A", "PPPPPPPPPPFFFFFFFFFF")),
+		opusB,
+	) {
+		db.InsertRecord(r)
+	}
+	now := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	names := []string{"A", "B"}
+
+	if _, _, passRate, _ := db.GetRollingStats("Sonnet", "A", 10); passRate != 1 {
+		t.Errorf("Expected Sonnet's pass rate to be 100%%, got %f", passRate)
+	}
+	if runs, _ := db.ListRuns("Sonnet", 100); len(runs) != 20 || runs[0].Model != "Sonnet" {
+		t.Errorf("Expected Sonnet's 20 runs only, got %d", len(runs))
+	}
+
+	if found, err := DetectRegressions(db, "Sonnet", testDetector, names, 100, now); err != nil || len(found) != 0 {
+		t.Errorf("Expected no regressions for Sonnet, got %+v (err %v)", found, err)
+	}
+	if found, _ := DetectRegressions(db, "Opus", testDetector, names, 100, now); len(found) == 0 || found[0].Model != "Opus" {
+		t.Errorf("Expected a regression for Opus, got %+v", found)
+	}
+
+	d := DriftDetector{Window: 4}
+	if found, err := DetectAnswerDrift(db, "Sonnet", d, names, 100, now); err != nil || len(found) != 0 {
+		t.Errorf("Expected no answer drift for Sonnet, got %+v (err %v)", found, err)
+	}
+	if found, _ := DetectAnswerDrift(db, "Opus", d, names, 100, now); len(found) == 0 || found[0].Model != "Opus" {
+		t.Errorf("Expected answer drift for Opus, got %+v", found)
+	}
+
+	f := FlakyDetector{Window: 10, MinRuns: 6, Alternation: 0.3, Entropy: 0.7, Quarantine: true}
+	if changed, err := UpdateFlakiness(db, "Opus", f, names, now); err != nil || len(changed) != 1 {
+		t.Fatalf("Expected B to become flaky for Opus, got %+v (err %v)", changed, err)
+	}
+	if changed, _ := UpdateFlakiness(db, "Sonnet", f, names, now); len(changed) != 0 {
+		t.Errorf("Expected no flakiness for Sonnet, got %+v", changed)
+	}
+	if quarantined, _ := Quarantined(db, "Sonnet"); len(quarantined) != 0 {
+		t.Errorf("Expected nothing quarantined for Sonnet, got %v", quarantined)
+	}
+
+	rules := []Rule{{Name: "pass_rate", Metric: MetricPassRate, Runs: 4, MinRuns: 1, Op: "<", Threshold: 0.5}}
+	results, err := EvaluateRules(db, "Sonnet", rules, names, nil, now)
+	if err != nil {
+		t.Fatalf("EvaluateRules failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Firing || r.Runs != 4 {
+			t.Errorf("Expected Sonnet's rule not to fire over 4 runs, got %+v", r)
+		}
+	}
+
+	e := SLOEvaluator{Objectives: []Objective{{Name: "suite", Target: 0.9, Window: 48 * time.Hour}}}
+	statuses, err := e.Evaluate(db, "Sonnet", now)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if statuses[0].Runs != 40 || statuses[0].Bad != 0 {
+		t.Errorf("Expected 40 good Sonnet results, got %+v", statuses[0])
+	}
+
+	// A healthy Sonnet cycle does not resolve Opus's incidents
+	tracker := IncidentTracker{ConsecutiveFailures: 2, SuiteFailureRate: 0.5}
+	if changes, err := tracker.Update(db, "Opus-run-19"); err != nil || len(changes) == 0 {
+		t.Fatalf("Expected incidents to open for Opus, got %+v (err %v)", changes, err)
+	}
+	if changes, err := tracker.Update(db, "Sonnet-run-19"); err != nil || len(changes) != 0 {
+		t.Errorf("Expected no incident changes for Sonnet, got %+v (err %v)", changes, err)
+	}
+	if incidents, _ := db.ListIncidents("Sonnet", time.Time{}); len(incidents) != 0 {
+		t.Errorf("Expected no incidents for Sonnet, got %+v", incidents)
+	}
+	incidents, _ := db.ListIncidents("Opus", time.Time{})
+	if len(incidents) == 0 {
+		t.Fatal("Expected incidents for Opus, got none")
+	}
+	for _, inc := range incidents {
+		if !inc.Open() || inc.Model != "Opus" {
+			t.Errorf("Expected Opus's incidents to stay open, got %+v", inc)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package analysis derives reports and signals from stored benchmark history.
+package analysis
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/stats"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Metric names a per-run measurement tracked across history.
+type Metric string
+
+const (
+	MetricPassRate Metric = "pass_rate" // 1 for a pass, 0 for a failure
+	MetricTokens   Metric = "tokens"
+	MetricLatency  Metric = "latency" // Seconds
+	MetricEffort   Metric = "effort"  // Effort score, 0-100
+)
+
+// Metrics lists every metric checked for regressions. Effort is left out
+// because results stored before effort scoring have no score.
+var Metrics = []Metric{MetricPassRate, MetricTokens, MetricLatency}
+
+// Value extracts the metric from a record.
+func (m Metric) Value(r storage.BenchmarkRecord) float64 {
+	switch m {
+	case MetricPassRate:
+		if r.Passed {
+			return 1
+		}
+		return 0
+	case MetricTokens:
+		return float64(r.TokensUsed)
+	case MetricLatency:
+		return r.Duration.Seconds()
+	case MetricEffort:
+		return r.EffortScore
+	default:
+		return 0
+	}
+}
+
+// Adverse reports whether a shift of delta in the metric is a degradation.
+func (m Metric) Adverse(delta float64) bool {
+	if m == MetricPassRate || m == MetricEffort {
+		return delta < 0
+	}
+	return delta > 0
+}
+
+// Format renders a metric value for display.
+func (m Metric) Format(v float64) string {
+	switch m {
+	case MetricPassRate:
+		return fmt.Sprintf("%.0f%%", v*100)
+	case MetricLatency, MetricP95Latency:
+		return fmt.Sprintf("%.2fs", v)
+	case MetricEffort, MetricErrors, MetricConsecutiveFailures:
+		return fmt.Sprintf("%.0f", v)
+	default:
+		return fmt.Sprintf("%.1f", v)
+	}
+}
+
+// RegressionDetector finds shifts in benchmark metrics using CUSUM change
+// point analysis with binary segmentation: the most significant shift in the
+// series is located, and the segments on either side are searched again.
+type RegressionDetector struct {
+	Confidence float64 // Minimum confidence (0.0-1.0) for a change point
+	MinSegment int     // Minimum number of runs on each side of a change point
+	Bootstraps int     // Reorderings used to estimate confidence
+	Seed       int64   // Seeds the reorderings so results are reproducible
+}
+
+// Detect returns the significant change points of metric in records, which
+// must belong to one benchmark and be ordered oldest first.
+func (d RegressionDetector) Detect(benchmark string, metric Metric, records []storage.BenchmarkRecord, now time.Time) []storage.ChangePoint {
+	xs := make([]float64, len(records))
+	for i, r := range records {
+		xs[i] = metric.Value(r)
+	}
+
+	rng := rand.New(rand.NewSource(d.Seed))
+	splits := make(map[int]float64)
+	d.segment(xs, 0, len(xs), rng, splits)
+
+	indices := make([]int, 0, len(splits))
+	for i := range splits {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	// Each change point compares the segments directly around it
+	var points []storage.ChangePoint
+	for n, split := range indices {
+		lo, hi := 0, len(xs)
+		if n > 0 {
+			lo = indices[n-1]
+		}
+		if n < len(indices)-1 {
+			hi = indices[n+1]
+		}
+
+		points = append(points, storage.ChangePoint{
+			Benchmark:  benchmark,
+			Metric:     string(metric),
+			ChangeAt:   records[split].Timestamp,
+			DetectedAt: now,
+			BeforeMean: stats.Mean(xs[lo:split]),
+			AfterMean:  stats.Mean(xs[split:hi]),
+			BeforeRuns: split - lo,
+			AfterRuns:  hi - split,
+			Confidence: splits[split],
+		})
+	}
+	return points
+}
+
+// segment records significant splits of xs[lo:hi] in splits, keyed by index.
+func (d RegressionDetector) segment(xs []float64, lo, hi int, rng *rand.Rand, splits map[int]float64) {
+	minSegment := max(d.MinSegment, 1)
+	if hi-lo < 2*minSegment {
+		return
+	}
+
+	index, confidence := stats.CUSUM(xs[lo:hi], d.Bootstraps, rng)
+	if confidence < d.Confidence || index < minSegment || hi-lo-index < minSegment {
+		return
+	}
+
+	split := lo + index
+	splits[split] = confidence
+	d.segment(xs, lo, split, rng, splits)
+	d.segment(xs, split, hi, rng, splits)
+}
+
+// DetectRegressions runs d over the latest window records of each benchmark
+// on model and metric and stores the change points found. Results from
+// outage cycles are left out, so an outage is not a step in pass rate or
+// latency. It returns the change points that were not stored before.
+func DetectRegressions(db storage.Store, model string, d RegressionDetector, benchmarks []string, window int, now time.Time) ([]storage.ChangePoint, error) {
+	var found []storage.ChangePoint
+	outages := make(map[string]bool)
+	for _, name := range benchmarks {
+		records, err := db.ListRecords(storage.RecordFilter{Name: name, Model: model, Limit: window})
+		if err != nil {
+			return found, err
+		}
+		records, err = withoutOutages(db, records, outages)
+		if err != nil {
+			return found, err
+		}
+
+		for _, metric := range Metrics {
+			for _, cp := range d.Detect(name, metric, records, now) {
+				cp.Model = model
+				inserted, err := db.InsertChangePoint(cp)
+				if err != nil {
+					return found, err
+				}
+				if inserted {
+					found = append(found, cp)
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// DescribeChangePoint renders a change point as a one-line summary.
+func DescribeChangePoint(cp storage.ChangePoint) string {
+	metric := Metric(cp.Metric)
+	kind := "shift"
+	if metric.Adverse(cp.Magnitude()) {
+		kind = "regression"
+	}
+
+	change := ""
+	if cp.BeforeMean != 0 {
+		change = fmt.Sprintf(" (%+.0f%%)", cp.Magnitude()/cp.BeforeMean*100)
+	}
+
+	return fmt.Sprintf("%s %s %s: %s → %s%s at %s, confidence %.0f%% (%d vs %d runs)",
+		cp.Benchmark, cp.Metric, kind,
+		metric.Format(cp.BeforeMean), metric.Format(cp.AfterMean), change,
+		cp.ChangeAt.Local().Format("2006-01-02 15:04"), cp.Confidence*100,
+		cp.BeforeRuns, cp.AfterRuns)
+}
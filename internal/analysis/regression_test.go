@@ -0,0 +1,140 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+var testDetector = RegressionDetector{Confidence: 0.95, MinSegment: 5, Bootstraps: 500, Seed: 1}
+
+// series builds one record per value, an hour apart, for the given metric.
+func series(name string, metric Metric, values []float64) []storage.BenchmarkRecord {
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]storage.BenchmarkRecord, len(values))
+	for i, v := range values {
+		r := storage.BenchmarkRecord{Name: name, Passed: true, TokensUsed: 10, Duration: time.Second, Timestamp: base.Add(time.Duration(i) * time.Hour)}
+		switch metric {
+		case MetricPassRate:
+			r.Passed = v == 1
+		case MetricTokens:
+			r.TokensUsed = int(v)
+		case MetricLatency:
+			r.Duration = time.Duration(v * float64(time.Second))
+		}
+		records[i] = r
+	}
+	return records
+}
+
+func TestDetectTokenRegression(t *testing.T) {
+	values := []float64{7, 8, 7, 7, 6, 7, 8, 7, 7, 7, 7, 8, 12, 13, 12, 11, 12, 13, 12, 12, 13, 12}
+	records := series("Sum1to100", MetricTokens, values)
+
+	points := testDetector.Detect("Sum1to100", MetricTokens, records, time.Now())
+	if len(points) != 1 {
+		t.Fatalf("Expected 1 change point, got %d: %+v", len(points), points)
+	}
+
+	cp := points[0]
+	if !cp.ChangeAt.Equal(records[12].Timestamp) {
+		t.Errorf("Expected change at run 12, got %v", cp.ChangeAt)
+	}
+	if cp.BeforeRuns != 12 || cp.AfterRuns != 10 {
+		t.Errorf("Expected 12 vs 10 runs, got %d vs %d", cp.BeforeRuns, cp.AfterRuns)
+	}
+	if cp.Magnitude() < 4 || cp.Magnitude() > 6 {
+		t.Errorf("Expected magnitude around +5 tokens, got %.2f", cp.Magnitude())
+	}
+	if cp.Confidence < 0.95 {
+		t.Errorf("Expected confidence >= 0.95, got %.2f", cp.Confidence)
+	}
+}
+
+func TestDetectTwoShifts(t *testing.T) {
+	var values []float64
+	for i := 0; i < 12; i++ {
+		values = append(values, 1.0+float64(i%2)*0.1)
+	}
+	for i := 0; i < 12; i++ {
+		values = append(values, 3.0+float64(i%2)*0.1)
+	}
+	for i := 0; i < 12; i++ {
+		values = append(values, 1.0+float64(i%2)*0.1)
+	}
+
+	points := testDetector.Detect("ListReverse", MetricLatency, series("ListReverse", MetricLatency, values), time.Now())
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 change points, got %d: %+v", len(points), points)
+	}
+	if points[0].Magnitude() < 1.5 || points[1].Magnitude() > -1.5 {
+		t.Errorf("Expected a rise then a fall, got %+.2f and %+.2f", points[0].Magnitude(), points[1].Magnitude())
+	}
+}
+
+func TestDetectStableSeries(t *testing.T) {
+	values := []float64{1, 1, 1, 1, 1, 0, 1, 1, 1, 1, 1, 1, 1, 0, 1, 1, 1, 1, 1, 1}
+	points := testDetector.Detect("PalindromeCheck", MetricPassRate, series("PalindromeCheck", MetricPassRate, values), time.Now())
+	if len(points) != 0 {
+		t.Errorf("Expected no change points, got %+v", points)
+	}
+}
+
+func TestDetectRegressionsStoresOnce(t *testing.T) {
+	db := storage.NewMemory()
+	values := []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, r := range series("SimpleArithmetic", MetricPassRate, values) {
+		if err := db.InsertRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := DetectRegressions(db, "", testDetector, []string{"SimpleArithmetic"}, 100, time.Now())
+	if err != nil {
+		t.Fatalf("DetectRegressions failed: %v", err)
+	}
+	if len(found) != 1 || found[0].Metric != string(MetricPassRate) {
+		t.Fatalf("Expected one pass rate change point, got %+v", found)
+	}
+	if !strings.Contains(DescribeChangePoint(found[0]), "pass_rate regression: 100% → ") {
+		t.Errorf("Unexpected description: %s", DescribeChangePoint(found[0]))
+	}
+
+	// A second pass over the same history reports nothing new
+	found, err = DetectRegressions(db, "", testDetector, []string{"SimpleArithmetic"}, 100, time.Now())
+	if err != nil || len(found) != 0 {
+		t.Errorf("Expected no new change points, got %+v (err %v)", found, err)
+	}
+
+	stored, _ := db.ListChangePoints("", "SimpleArithmetic", time.Time{})
+	if len(stored) != 1 {
+		t.Errorf("Expected 1 stored change point, got %d", len(stored))
+	}
+}
+
+func TestDetectRegressionsSkipsOutages(t *testing.T) {
+	db := storage.NewMemory()
+	for _, r := range outcomes("A", "PPPPPPPPPPFFFFFFFFPPPPPPPPPP") {
+		db.InsertRecord(r)
+	}
+	for i := 10; i < 18; i++ {
+		db.SaveCycle(storage.Cycle{RunID: fmt.Sprintf("run-%02d", i), Label: storage.CycleOutage})
+	}
+
+	found, err := DetectRegressions(db, "", testDetector, []string{"A"}, 100, time.Now())
+	if err != nil || len(found) != 0 {
+		t.Errorf("Expected the outage not to be a regression, got %+v (err %v)", found, err)
+	}
+}
+
+func TestMetricAdverse(t *testing.T) {
+	if !MetricPassRate.Adverse(-0.2) || MetricPassRate.Adverse(0.2) {
+		t.Error("A falling pass rate is adverse, a rising one is not")
+	}
+	if !MetricTokens.Adverse(3) || MetricLatency.Adverse(-1) {
+		t.Error("Rising tokens and latency are adverse, falling ones are not")
+	}
+}
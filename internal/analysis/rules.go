@@ -0,0 +1,316 @@
+package analysis
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/stats"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Metrics only alert rules use, over a set of results rather than per run.
+// Rules also take MetricPassRate, MetricEffort and MetricTokens, as means.
+const (
+	MetricP95Latency          Metric = "p95_latency"          // Seconds
+	MetricErrors              Metric = "errors"               // Failed results, of one error class or any
+	MetricConsecutiveFailures Metric = "consecutive_failures" // Failed results since the last pass
+)
+
+// Rule fires while Metric over the recent results of a benchmark, or of
+// several together, compares to Threshold by Op.
+type Rule struct {
+	Name           string
+	Metric         Metric
+	ErrorClass     string // For MetricErrors; empty for every failure
+	Runs           int    // Latest results evaluated, or cycles for suite rules, with Window zero
+	Window         time.Duration
+	MinRuns        int // Fewer results are not evaluated
+	Op             string
+	Threshold      float64
+	Severity       string
+	Labels         map[string]string
+	Benchmarks     []string // Empty for every benchmark
+	Suite          bool     // One result over every benchmark's results together
+	IncludeOutages bool
+}
+
+// RuleResult is the outcome of evaluating a rule for one benchmark, or for
+// the suite.
+type RuleResult struct {
+	Rule        Rule
+	Benchmark   string // Empty for suite rules
+	Runs        int    // Results evaluated
+	Value       float64
+	Firing      bool
+	Quarantined bool // The benchmark was left out; never firing
+}
+
+// NewRules returns the alert rules in cfg.
+func NewRules(cfg *config.Config) ([]Rule, error) {
+	var rules []Rule
+	for _, r := range cfg.Alerts.Rules {
+		var window time.Duration
+		if r.Window != "" {
+			var err error
+			if window, err = config.ParseDuration(r.Window); err != nil {
+				return nil, fmt.Errorf("invalid window for alert rule %s: %w", r.Name, err)
+			}
+		}
+		rules = append(rules, Rule{
+			Name:           r.Name,
+			Metric:         Metric(r.Metric),
+			ErrorClass:     r.ErrorClass,
+			Runs:           r.Runs,
+			Window:         window,
+			MinRuns:        r.MinRuns,
+			Op:             r.Op,
+			Threshold:      r.Threshold,
+			Severity:       r.Severity,
+			Labels:         r.Labels,
+			Benchmarks:     r.Benchmarks,
+			Suite:          r.Suite,
+			IncludeOutages: r.IncludeOutages,
+		})
+	}
+	return rules, nil
+}
+
+// EvaluateRules evaluates every rule at now against the stored results of
+// benchmarks on model. Quarantined benchmarks are left out of suite rules and
+// never fire. Rules with fewer than MinRuns results have no result.
+func EvaluateRules(db storage.Store, model string, rules []Rule, benchmarks []string, quarantined map[string]bool, now time.Time) ([]RuleResult, error) {
+	outages := make(map[string]bool)
+	var results []RuleResult
+	for _, r := range rules {
+		names := benchmarks
+		if len(r.Benchmarks) > 0 {
+			names = r.Benchmarks
+		}
+
+		if r.Suite {
+			records, err := ruleRecords(db, model, r, Unquarantined(names, quarantined), now, outages)
+			if err != nil {
+				return nil, err
+			}
+			if res, ok := evaluateRule(r, "", records); ok {
+				results = append(results, res)
+			}
+			continue
+		}
+
+		for _, name := range names {
+			if quarantined[name] {
+				results = append(results, RuleResult{Rule: r, Benchmark: name, Quarantined: true})
+				continue
+			}
+			records, err := ruleRecords(db, model, r, []string{name}, now, outages)
+			if err != nil {
+				return nil, err
+			}
+			if res, ok := evaluateRule(r, name, records); ok {
+				results = append(results, res)
+			}
+		}
+	}
+	return results, nil
+}
+
+// ruleRecords returns the results of names on model that r evaluates, oldest first.
+func ruleRecords(db storage.Store, model string, r Rule, names []string, now time.Time, outages map[string]bool) ([]storage.BenchmarkRecord, error) {
+	if r.Suite && r.Window == 0 {
+		return suiteRecords(db, model, r, names, outages)
+	}
+
+	var records []storage.BenchmarkRecord
+	for _, name := range names {
+		filter := storage.RecordFilter{Name: name, Model: model}
+		if r.Window > 0 {
+			filter.Since = now.Add(-r.Window)
+		} else {
+			filter.Limit = outageSlack(r.Runs)
+		}
+		found, err := db.ListRecords(filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, found...)
+	}
+	slices.SortStableFunc(records, func(a, b storage.BenchmarkRecord) int { return a.Timestamp.Compare(b.Timestamp) })
+
+	if !r.IncludeOutages {
+		var err error
+		if records, err = withoutOutages(db, records, outages); err != nil {
+			return nil, err
+		}
+	}
+	if r.Window == 0 && len(records) > r.Runs {
+		records = records[len(records)-r.Runs:]
+	}
+	return records, nil
+}
+
+// suiteRecords returns the results of names on model in the latest r.Runs
+// cycles that ran any of them, oldest first.
+func suiteRecords(db storage.Store, model string, r Rule, names []string, outages map[string]bool) ([]storage.BenchmarkRecord, error) {
+	runs, err := db.ListRuns(model, outageSlack(r.Runs))
+	if err != nil {
+		return nil, err
+	}
+
+	var cycles [][]storage.BenchmarkRecord
+	for _, run := range runs {
+		if len(cycles) == r.Runs {
+			break
+		}
+		records, err := db.GetRun(run.RunID)
+		if err != nil {
+			return nil, err
+		}
+		if !r.IncludeOutages {
+			if records, err = withoutOutages(db, records, outages); err != nil {
+				return nil, err
+			}
+		}
+		records = slices.DeleteFunc(records, func(rec storage.BenchmarkRecord) bool { return !slices.Contains(names, rec.Name) })
+		if len(records) > 0 {
+			cycles = append(cycles, records)
+		}
+	}
+
+	// runs is newest first
+	slices.Reverse(cycles)
+	return slices.Concat(cycles...), nil
+}
+
+// evaluateRule computes the metric of r over records; ok is false without
+// enough results.
+func evaluateRule(r Rule, benchmark string, records []storage.BenchmarkRecord) (RuleResult, bool) {
+	value, n := RuleMetric(r, records)
+	if n == 0 || n < r.MinRuns {
+		return RuleResult{}, false
+	}
+	return RuleResult{
+		Rule:      r,
+		Benchmark: benchmark,
+		Runs:      n,
+		Value:     value,
+		Firing:    compareOp(value, r.Op, r.Threshold),
+	}, true
+}
+
+// RuleMetric computes the metric of r over records, oldest first, and
+// returns it with the number of results it covers: only scored results
+// count for effort.
+func RuleMetric(r Rule, records []storage.BenchmarkRecord) (value float64, n int) {
+	switch r.Metric {
+	case MetricPassRate:
+		for _, rec := range records {
+			if rec.Passed {
+				value++
+			}
+		}
+		if len(records) > 0 {
+			value /= float64(len(records))
+		}
+	case MetricEffort:
+		for _, rec := range records {
+			if rec.Effort != "" {
+				value += rec.EffortScore
+				n++
+			}
+		}
+		if n > 0 {
+			value /= float64(n)
+		}
+		return value, n
+	case MetricP95Latency:
+		durations := make([]float64, len(records))
+		for i, rec := range records {
+			durations[i] = rec.Duration.Seconds()
+		}
+		value = stats.Quantile(durations, 0.95)
+	case MetricTokens:
+		for _, rec := range records {
+			value += float64(rec.TokensUsed)
+		}
+		if len(records) > 0 {
+			value /= float64(len(records))
+		}
+	case MetricErrors:
+		for _, rec := range records {
+			if !rec.Passed && (r.ErrorClass == "" || rec.ErrorClass == r.ErrorClass) {
+				value++
+			}
+		}
+	case MetricConsecutiveFailures:
+		for i := len(records) - 1; i >= 0 && !records[i].Passed; i-- {
+			value++
+		}
+	}
+	return value, len(records)
+}
+
+// compareOp reports whether value op threshold holds.
+func compareOp(value float64, op string, threshold float64) bool {
+	switch op {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+// DescribeRuleResult returns a one-line summary of a rule result, e.g.
+// "Sum1to100 pass rate 40% is below 70% over the last 10 runs".
+func DescribeRuleResult(res RuleResult) string {
+	r := res.Rule
+	subject := res.Benchmark
+	if r.Suite {
+		subject = "Suite"
+	}
+	if res.Quarantined {
+		return fmt.Sprintf("%s is quarantined as flaky", subject)
+	}
+
+	metric := map[Metric]string{
+		MetricPassRate:            "pass rate",
+		MetricEffort:              "effort score",
+		MetricP95Latency:          "p95 latency",
+		MetricTokens:              "avg tokens",
+		MetricErrors:              "errors",
+		MetricConsecutiveFailures: "consecutive failures",
+	}[r.Metric]
+	if r.Metric == MetricErrors && r.ErrorClass != "" {
+		metric = r.ErrorClass + " errors"
+	}
+
+	comparison := map[string]string{
+		"<": "is below", "<=": "is at or below", ">": "is above", ">=": "is at or above", "==": "is", "!=": "is not",
+	}[r.Op]
+	if !res.Firing {
+		comparison = map[string]string{
+			"<": "is no longer below", "<=": "is above", ">": "is no longer above", ">=": "is below", "==": "is no longer", "!=": "is back at",
+		}[r.Op]
+	}
+
+	over := fmt.Sprintf("the last %d runs", res.Runs)
+	if r.Suite {
+		over = fmt.Sprintf("the last %d cycles", r.Runs)
+	}
+	if r.Window > 0 {
+		over = "the last " + FormatWindow(r.Window)
+	}
+	return fmt.Sprintf("%s %s %s %s %s over %s", subject, metric, r.Metric.Format(res.Value),
+		comparison, r.Metric.Format(r.Threshold), over)
+}
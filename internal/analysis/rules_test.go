@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestRuleMetric(t *testing.T) {
+	records := outcomes("A", "PPFPFF")
+	for i := range records {
+		records[i].Duration = time.Duration(i+1) * time.Second
+		records[i].TokensUsed = 10 * (i + 1)
+		if !records[i].Passed {
+			records[i].ErrorClass = "timeout"
+		}
+	}
+	records[5].ErrorClass = "refusal"
+	records[0].Effort, records[0].EffortScore = "good", 90
+	records[1].Effort, records[1].EffortScore = "poor", 30
+
+	tests := []struct {
+		rule  Rule
+		value float64
+		n     int
+	}{
+		{Rule{Metric: MetricPassRate}, 0.5, 6},
+		{Rule{Metric: MetricEffort}, 60, 2},
+		{Rule{Metric: MetricP95Latency}, 5.75, 6},
+		{Rule{Metric: MetricTokens}, 35, 6},
+		{Rule{Metric: MetricErrors}, 3, 6},
+		{Rule{Metric: MetricErrors, ErrorClass: "timeout"}, 2, 6},
+		{Rule{Metric: MetricConsecutiveFailures}, 2, 6},
+	}
+
+	for _, tt := range tests {
+		value, n := RuleMetric(tt.rule, records)
+		if math.Abs(value-tt.value) > 1e-9 || n != tt.n {
+			t.Errorf("%s %s: expected %v over %d results, got %v over %d", tt.rule.Metric, tt.rule.ErrorClass, tt.value, tt.n, value, n)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	db := storage.NewMemory()
+	for _, r := range slices.Concat(outcomes("A", "PPPPFFFF"), outcomes("B", "PPPPPPPP"), outcomes("C", "FFFFFFFF")) {
+		db.InsertRecord(r)
+	}
+	// The last cycle was an outage, so it is left out unless rules include outages
+	db.SaveCycle(storage.Cycle{RunID: "run-07", Label: storage.CycleOutage})
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	quarantined := map[string]bool{"C": true}
+
+	rules := []Rule{
+		{Name: "pass_rate", Metric: MetricPassRate, Runs: 4, MinRuns: 1, Op: "<", Threshold: 0.5},
+		{Name: "streak", Metric: MetricConsecutiveFailures, Runs: 10, MinRuns: 1, Op: ">=", Threshold: 4, Benchmarks: []string{"A"}, IncludeOutages: true},
+		{Name: "suite", Metric: MetricPassRate, Window: 3 * time.Hour, MinRuns: 1, Op: "<", Threshold: 0.6, Suite: true},
+		{Name: "thin", Metric: MetricEffort, Runs: 4, MinRuns: 1, Op: "<", Threshold: 50},
+	}
+	results, err := EvaluateRules(db, "", rules, []string{"A", "B", "C"}, quarantined, now)
+	if err != nil {
+		t.Fatalf("EvaluateRules failed: %v", err)
+	}
+
+	got := make(map[string]RuleResult)
+	for _, r := range results {
+		got[r.Rule.Name+"/"+r.Benchmark] = r
+	}
+	// No effort scores, so thin only reports the quarantined C
+	if len(got) != 6 || !got["thin/C"].Quarantined {
+		t.Fatalf("Expected 6 results, got %+v", results)
+	}
+	// A's last four results without the outage: PFFF
+	if r := got["pass_rate/A"]; !r.Firing || r.Value != 0.25 || r.Runs != 4 {
+		t.Errorf("Expected pass_rate to fire for A at 25%%, got %+v", r)
+	}
+	if r := got["pass_rate/B"]; r.Firing || r.Value != 1 {
+		t.Errorf("Expected pass_rate not to fire for B, got %+v", r)
+	}
+	if r := got["pass_rate/C"]; !r.Quarantined || r.Firing {
+		t.Errorf("Expected C to be quarantined, got %+v", r)
+	}
+	if r := got["streak/A"]; !r.Firing || r.Value != 4 {
+		t.Errorf("Expected a streak of 4 failures for A, got %+v", r)
+	}
+	// A and B from 05:00 to 06:00 (FF and PP), the outage at 07:00 left out
+	if r := got["suite/"]; !r.Firing || r.Value != 0.5 || r.Runs != 4 {
+		t.Errorf("Expected the suite rule to fire at 50%% over 4 results, got %+v", r)
+	}
+
+	if s := DescribeRuleResult(got["pass_rate/A"]); s != "A pass rate 25% is below 50% over the last 4 runs" {
+		t.Errorf("Unexpected description: %s", s)
+	}
+	if s := DescribeRuleResult(got["suite/"]); s != "Suite pass rate 50% is below 60% over the last 3h" {
+		t.Errorf("Unexpected description: %s", s)
+	}
+	if s := DescribeRuleResult(got["pass_rate/B"]); s != "B pass rate 100% is no longer below 50% over the last 4 runs" {
+		t.Errorf("Unexpected description: %s", s)
+	}
+}
+
+func TestEvaluateSuiteRuleOverCycles(t *testing.T) {
+	db := storage.NewMemory()
+	for _, r := range slices.Concat(outcomes("A", "PPPFF"), outcomes("B", "PPPPF"), outcomes("C", "FFFFF")) {
+		db.InsertRecord(r)
+	}
+	// The last cycle was an outage and C is quarantined, so neither counts
+	db.SaveCycle(storage.Cycle{RunID: "run-04", Label: storage.CycleOutage})
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	rule := Rule{Name: "suite", Metric: MetricPassRate, Runs: 2, MinRuns: 1, Op: "<", Threshold: 0.8, Suite: true}
+	results, err := EvaluateRules(db, "", []Rule{rule}, []string{"A", "B", "C"}, map[string]bool{"C": true}, now)
+	if err != nil {
+		t.Fatalf("EvaluateRules failed: %v", err)
+	}
+	// A and B in run-02 and run-03: PP and FP
+	if len(results) != 1 || results[0].Value != 0.75 || results[0].Runs != 4 || !results[0].Firing {
+		t.Fatalf("Expected 75%% over both benchmarks of 2 cycles, got %+v", results)
+	}
+	if s := DescribeRuleResult(results[0]); s != "Suite pass rate 75% is below 80% over the last 2 cycles" {
+		t.Errorf("Unexpected description: %s", s)
+	}
+}
@@ -0,0 +1,268 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Objective is a service level objective: at least Target of the runs of
+// Benchmark (or of every benchmark, if empty) over Window must be good.
+type Objective struct {
+	Name      string
+	Benchmark string
+	Target    float64 // 0.0-1.0
+	Window    time.Duration
+	Latency   time.Duration // If set, only passed runs faster than Latency are good; otherwise every passed run is
+}
+
+// Good reports whether r counts towards the objective rather than against it.
+// A failed run is never good, however fast it failed.
+func (o Objective) Good(r storage.BenchmarkRecord) bool {
+	if o.Latency > 0 {
+		return r.Passed && r.Duration < o.Latency
+	}
+	return r.Passed
+}
+
+// Covers reports whether r is one of the runs the objective is about.
+func (o Objective) Covers(r storage.BenchmarkRecord) bool {
+	return o.Benchmark == "" || r.Name == o.Benchmark
+}
+
+// String describes the objective, e.g. "99% of suite runs pass over 30d".
+func (o Objective) String() string {
+	scope := "suite"
+	if o.Benchmark != "" {
+		scope = o.Benchmark
+	}
+	condition := "pass"
+	if o.Latency > 0 {
+		condition = "pass within " + o.Latency.String()
+	}
+	return fmt.Sprintf("%s%% of %s runs %s over %s", formatPercent(o.Target), scope, condition, FormatWindow(o.Window))
+}
+
+// BurnAlert fires when the error budget of an objective burns at least
+// BurnRate times faster than the rate that would use it up exactly over the
+// objective's window, over both the Long and the Short window. The long
+// window makes the alert significant; the short one makes it stop soon
+// after the problem does.
+type BurnAlert struct {
+	Long     time.Duration
+	Short    time.Duration
+	BurnRate float64
+	Severity string // "page" or "ticket"
+}
+
+// BurnWindow is the burn rate of an objective over one window.
+type BurnWindow struct {
+	Window   time.Duration
+	Runs     int
+	Bad      int
+	BurnRate float64 // Error rate over the window divided by the error budget (1 - Target)
+}
+
+// AlertStatus is a BurnAlert evaluated for one objective.
+type AlertStatus struct {
+	BurnAlert
+	Long   BurnWindow
+	Short  BurnWindow
+	Firing bool
+}
+
+// SLOStatus is the state of an objective over its window.
+type SLOStatus struct {
+	Objective
+	Runs           int
+	Bad            int
+	Compliance     float64 // Share of good runs (0.0-1.0); 1 without runs
+	BudgetConsumed float64 // Share of the error budget used up; above 1 when exhausted
+	Alerts         []AlertStatus
+}
+
+// Met reports whether the objective is currently met.
+func (s SLOStatus) Met() bool {
+	return s.Compliance >= s.Target
+}
+
+// BudgetRemaining returns the share of the error budget left, down to 0.
+func (s SLOStatus) BudgetRemaining() float64 {
+	if s.BudgetConsumed >= 1 {
+		return 0
+	}
+	return 1 - s.BudgetConsumed
+}
+
+// Firing returns the alerts that are firing.
+func (s SLOStatus) Firing() []AlertStatus {
+	var firing []AlertStatus
+	for _, a := range s.Alerts {
+		if a.Firing {
+			firing = append(firing, a)
+		}
+	}
+	return firing
+}
+
+// SLOEvaluator computes the status of each objective from stored results.
+// Quarantined benchmarks are left out of suite-wide objectives.
+type SLOEvaluator struct {
+	Objectives  []Objective
+	Alerts      []BurnAlert
+	Quarantined map[string]bool
+}
+
+// NewSLOEvaluator returns the evaluator for the objectives and burn-rate
+// alerts in cfg.
+func NewSLOEvaluator(cfg *config.Config) (SLOEvaluator, error) {
+	var e SLOEvaluator
+	for _, o := range cfg.SLO.Objectives {
+		window, err := config.ParseDuration(o.Window)
+		if err != nil {
+			return SLOEvaluator{}, fmt.Errorf("invalid window for SLO %s: %w", o.Name, err)
+		}
+		var latency time.Duration
+		if o.Latency != "" {
+			if latency, err = time.ParseDuration(o.Latency); err != nil {
+				return SLOEvaluator{}, fmt.Errorf("invalid latency for SLO %s: %w", o.Name, err)
+			}
+		}
+		e.Objectives = append(e.Objectives, Objective{
+			Name:      o.Name,
+			Benchmark: o.Benchmark,
+			Target:    o.Target,
+			Window:    window,
+			Latency:   latency,
+		})
+	}
+
+	for _, a := range cfg.SLO.BurnAlerts {
+		long, err := config.ParseDuration(a.Long)
+		if err != nil {
+			return SLOEvaluator{}, fmt.Errorf("invalid long window for burn alert: %w", err)
+		}
+		short, err := config.ParseDuration(a.Short)
+		if err != nil {
+			return SLOEvaluator{}, fmt.Errorf("invalid short window for burn alert: %w", err)
+		}
+		e.Alerts = append(e.Alerts, BurnAlert{Long: long, Short: short, BurnRate: a.BurnRate, Severity: a.Severity})
+	}
+	return e, nil
+}
+
+// Evaluate returns the status of every objective on model at now.
+func (e SLOEvaluator) Evaluate(db storage.Store, model string, now time.Time) ([]SLOStatus, error) {
+	if len(e.Objectives) == 0 {
+		return nil, nil
+	}
+
+	// One query covers every objective and alert window
+	var longest time.Duration
+	for _, o := range e.Objectives {
+		longest = max(longest, o.Window)
+	}
+	for _, a := range e.Alerts {
+		longest = max(longest, a.Long)
+	}
+
+	records, err := db.ListRecords(storage.RecordFilter{Model: model, Since: now.Add(-longest)})
+	if err != nil {
+		return nil, err
+	}
+
+	suite := withoutQuarantined(records, e.Quarantined)
+	statuses := make([]SLOStatus, len(e.Objectives))
+	for i, o := range e.Objectives {
+		if o.Benchmark == "" {
+			statuses[i] = EvaluateObjective(o, e.Alerts, suite, now)
+		} else {
+			statuses[i] = EvaluateObjective(o, e.Alerts, records, now)
+		}
+	}
+	return statuses, nil
+}
+
+// EvaluateObjective computes the status of o at now from records, which must
+// cover the objective's window and the long window of every alert.
+func EvaluateObjective(o Objective, alerts []BurnAlert, records []storage.BenchmarkRecord, now time.Time) SLOStatus {
+	s := SLOStatus{Objective: o, Compliance: 1}
+	s.Runs, s.Bad = countBad(o, records, now.Add(-o.Window), now)
+	if s.Runs > 0 {
+		s.Compliance = 1 - float64(s.Bad)/float64(s.Runs)
+		s.BudgetConsumed = float64(s.Bad) / (float64(s.Runs) * (1 - o.Target))
+	}
+
+	for _, a := range alerts {
+		status := AlertStatus{
+			BurnAlert: a,
+			Long:      burnWindow(o, records, a.Long, now),
+			Short:     burnWindow(o, records, a.Short, now),
+		}
+		status.Firing = status.Long.Runs > 0 && status.Short.Runs > 0 &&
+			burning(status.Long.BurnRate, a.BurnRate) && burning(status.Short.BurnRate, a.BurnRate)
+		s.Alerts = append(s.Alerts, status)
+	}
+	return s
+}
+
+func burnWindow(o Objective, records []storage.BenchmarkRecord, window time.Duration, now time.Time) BurnWindow {
+	w := BurnWindow{Window: window}
+	w.Runs, w.Bad = countBad(o, records, now.Add(-window), now)
+	if w.Runs > 0 {
+		w.BurnRate = float64(w.Bad) / float64(w.Runs) / (1 - o.Target)
+	}
+	return w
+}
+
+// burning reports whether rate reaches threshold, allowing for rounding in
+// targets such as 0.9 that have no exact binary representation.
+func burning(rate, threshold float64) bool {
+	return rate >= threshold*(1-1e-9)
+}
+
+// countBad counts the runs covered by o in (from, to] and the bad ones among them.
+func countBad(o Objective, records []storage.BenchmarkRecord, from, to time.Time) (runs, bad int) {
+	for _, r := range records {
+		if !o.Covers(r) || !r.Timestamp.After(from) || r.Timestamp.After(to) {
+			continue
+		}
+		runs++
+		if !o.Good(r) {
+			bad++
+		}
+	}
+	return runs, bad
+}
+
+// DescribeBurnAlert returns a one-line summary of a firing alert.
+func DescribeBurnAlert(s SLOStatus, a AlertStatus) string {
+	return fmt.Sprintf("SLO %s [%s]: error budget burning %.1fx over %s and %.1fx over %s (threshold %.1fx), %.0f%% of budget left",
+		s.Name, a.Severity, a.Long.BurnRate, FormatWindow(a.Long.Window), a.Short.BurnRate, FormatWindow(a.Short.Window),
+		a.BurnRate, s.BudgetRemaining()*100)
+}
+
+// FormatWindow prints whole days as "30d" and shorter windows without zero
+// units, e.g. "6h" rather than "6h0m0s".
+func FormatWindow(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatPercent prints a share as a percentage without trailing zeros, e.g. 99.9.
+func formatPercent(share float64) string {
+	return fmt.Sprintf("%g", float64(int64(share*1e6+0.5))/1e4)
+}
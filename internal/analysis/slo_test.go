@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestEvaluateObjective(t *testing.T) {
+	now := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	o := Objective{Name: "liveness", Target: 0.9, Window: 30 * 24 * time.Hour}
+	alerts := []BurnAlert{
+		{Long: 6 * time.Hour, Short: time.Hour, BurnRate: 5, Severity: "page"},
+		{Long: 3 * 24 * time.Hour, Short: 6 * time.Hour, BurnRate: 1, Severity: "ticket"},
+	}
+
+	// Hourly runs for 30 days; the last 3 hours fail
+	var records []storage.BenchmarkRecord
+	for i := 0; i < 720; i++ {
+		at := now.Add(-time.Duration(i) * time.Hour)
+		records = append(records, storage.BenchmarkRecord{Name: "A", Passed: i >= 3, Duration: time.Second, Timestamp: at})
+	}
+
+	s := EvaluateObjective(o, alerts, records, now)
+	if s.Runs != 720 || s.Bad != 3 || !s.Met() {
+		t.Fatalf("Expected 720 runs with 3 bad ones meeting the objective, got %+v", s)
+	}
+	// The budget allows 72 bad runs
+	if s.BudgetConsumed < 3.0/72-1e-9 || s.BudgetConsumed > 3.0/72+1e-9 {
+		t.Errorf("Expected 3/72 of the budget consumed, got %f", s.BudgetConsumed)
+	}
+
+	// 3 of 6 runs over 6h is a burn rate of 5; the last hour fails entirely
+	page := s.Alerts[0]
+	if !page.Firing || math.Abs(page.Long.BurnRate-5) > 1e-9 || math.Abs(page.Short.BurnRate-10) > 1e-9 {
+		t.Errorf("Expected the page alert to fire at 5x and 10x, got %+v", page)
+	}
+	// 3 of 72 runs over 3 days burns at less than 1x
+	if s.Alerts[1].Firing {
+		t.Errorf("Expected the ticket alert not to fire, got %+v", s.Alerts[1])
+	}
+	if got := s.Firing(); len(got) != 1 || got[0].Severity != "page" {
+		t.Errorf("Expected only the page alert firing, got %+v", got)
+	}
+
+	// Latency objectives count slow runs and failed runs, however fast, as bad
+	fast := Objective{Name: "fast", Benchmark: "A", Target: 0.95, Window: 24 * time.Hour, Latency: 2 * time.Second}
+	records[0].Duration = 3 * time.Second
+	records[1].Duration = 10 * time.Millisecond
+	records[5].Duration = 3 * time.Second
+	if s := EvaluateObjective(fast, nil, records, now); s.Bad != 4 || s.Runs != 24 {
+		t.Errorf("Expected 3 failed and 1 slow run of 24, got %d of %d", s.Bad, s.Runs)
+	}
+	if fast.Good(records[1]) {
+		t.Errorf("Expected a fast failed run not to be good")
+	}
+	if desc := fast.String(); desc != "95% of A runs pass within 2s over 1d" {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+	if desc := o.String(); desc != "90% of suite runs pass over 30d" {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+
+	if w := FormatWindow(30 * time.Minute); w != "30m" {
+		t.Errorf("Expected 30m, got %s", w)
+	}
+}
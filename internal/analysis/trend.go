@@ -0,0 +1,36 @@
+package analysis
+
+import (
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// PreviousPassRate returns the pass rate of a benchmark on model over the
+// window runs before its latest window, the one its rolling statistics
+// cover. Like the rolling statistics it leaves out outage cycles. ok is
+// false until there are two full windows of history.
+func PreviousPassRate(db storage.Store, model, name string, window int) (passRate float64, ok bool, err error) {
+	if window <= 0 {
+		return 0, false, nil
+	}
+
+	records, err := db.ListRecords(storage.RecordFilter{Name: name, Model: model, Limit: outageSlack(2 * window)})
+	if err != nil {
+		return 0, false, err
+	}
+	records, err = withoutOutages(db, records, make(map[string]bool))
+	if err != nil {
+		return 0, false, err
+	}
+	if len(records) < 2*window {
+		return 0, false, nil
+	}
+
+	previous := records[len(records)-2*window : len(records)-window]
+	passed := 0
+	for _, r := range previous {
+		if r.Passed {
+			passed++
+		}
+	}
+	return float64(passed) / float64(window), true, nil
+}
@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestPreviousPassRate(t *testing.T) {
+	db := storage.NewMemory()
+	for _, r := range outcomes("A", "PPPFPPFFFF") {
+		db.InsertRecord(r)
+	}
+
+	if _, ok, err := PreviousPassRate(db, "", "A", 6); err != nil || ok {
+		t.Errorf("Expected no previous window without enough history, got ok %v (err %v)", ok, err)
+	}
+
+	// The latest 3 runs are FFF, the 3 before them PPF
+	rate, ok, err := PreviousPassRate(db, "", "A", 3)
+	if err != nil || !ok {
+		t.Fatalf("Expected a previous window, got ok %v (err %v)", ok, err)
+	}
+	if math.Abs(rate-2.0/3) > 1e-9 {
+		t.Errorf("Expected previous pass rate 0.67, got %.2f", rate)
+	}
+
+	// Outage cycles are left out, so the window shifts back past run-05
+	db.SaveCycle(storage.Cycle{RunID: "run-05", Label: storage.CycleOutage})
+	if rate, _, _ := PreviousPassRate(db, "", "A", 3); math.Abs(rate-1.0/3) > 1e-9 {
+		t.Errorf("Expected previous pass rate 0.33 without the outage, got %.2f", rate)
+	}
+}
@@ -37,3 +37,12 @@ var Benchmarks = []Benchmark{
 		MaxDuration: 5,
 	},
 }
+
+// BenchmarkNames returns the names of all defined benchmarks.
+func BenchmarkNames() []string {
+	names := make([]string, len(Benchmarks))
+	for i, b := range Benchmarks {
+		names[i] = b.Name
+	}
+	return names
+}
@@ -2,7 +2,12 @@ package checker
 
 import (
     "bytes"
+    "context"
+    "encoding/json"
     "fmt"
+    "io"
+    "log/slog"
+    "math"
     "os/exec"
     "strings"
     "time"
@@ -12,54 +17,68 @@ import (
 )
 
 type Result struct {
-    Name       string
-    Passed     bool
-    TokensUsed int
-    Duration   time.Duration
-    Quote      string
-    Effort     string // "good", "medium", "poor"
-    Output     string
+    RunID       string
+    Name        string
+    Model       string
+    Passed      bool
+    TokensUsed  int
+    Duration    time.Duration
+    Quote       string
+    EffortScore float64 // 0-100, see EffortScorer
+    Effort      string  // "good", "medium", "poor"
+    ErrorClass  string  // Why the run failed, one of the Error* classes; empty when passed
+    Output      string
+    Transcript  *storage.Transcript
 }
 
-// Determine effort category based on passed status, tokens, and duration
-func categorizeEffort(r Result, b Benchmark) string {
-    // Failed benchmarks are always poor effort
-    if !r.Passed {
-        return "poor"
-    }
-
-    // Passed within limits is good
-    if r.TokensUsed <= b.MaxTokens && r.Duration.Seconds() <= float64(b.MaxDuration) {
-        return "good"
-    }
+// Error classes of failed runs
+const (
+    ErrorExec        = "exec_error"   // The CLI could not be started
+    ErrorTimeout     = "timeout"      // Killed after MaxDuration
+    ErrorNonzeroExit = "nonzero_exit" // The CLI exited with an error
+    ErrorOverBudget  = "over_budget"  // Used more than MaxTokens
+    ErrorTooSlow     = "too_slow"     // Finished, but after MaxDuration
+)
 
-    // Medium if slightly exceeded tokens or duration (but still passed)
-    if r.TokensUsed <= b.MaxTokens*2 && r.Duration.Seconds() <= float64(b.MaxDuration)*2 {
-        return "medium"
+// Classify why a finished run failed, or return "" if it passed
+func classifyError(exitErr error, tokensUsed int, duration time.Duration, b Benchmark) string {
+    switch {
+    case exitErr != nil:
+        return ErrorNonzeroExit
+    case tokensUsed > b.MaxTokens:
+        return ErrorOverBudget
+    case duration.Seconds() > float64(b.MaxDuration):
+        return ErrorTooSlow
     }
-
-    return "poor"
+    return ""
 }
 
-// Run a single benchmark using Claude CLI
-func RunClaudeBenchmark(b Benchmark, db *storage.Storage) Result {
+// Run a single benchmark against model using Claude CLI as part of the cycle identified by runID
+func RunClaudeBenchmark(b Benchmark, model, runID string, db storage.Store, scorer EffortScorer) Result {
+    slog.Debug("Running benchmark", "run_id", runID, "benchmark", b.Name, "model", model)
     start := time.Now()
 
     cmd := exec.Command(
         "claude",
-        "--model", "Sonnet",
+        "--model", model,
         "--fresh",
         "--max-tokens", fmt.Sprintf("%d", b.MaxTokens),
     )
     cmd.Stdin = strings.NewReader(b.Prompt)
 
-    var out bytes.Buffer
-    cmd.Stdout = &out
-    cmd.Stderr = &out
+    // Output keeps the interleaved streams; the transcript keeps them apart
+    var out, stdout, stderr bytes.Buffer
+    cmd.Stdout = io.MultiWriter(&out, &stdout)
+    cmd.Stderr = io.MultiWriter(&out, &stderr)
+    // Do not wait forever for children that keep the output open
+    cmd.WaitDelay = time.Second
+
+    transcript := &storage.Transcript{Prompt: b.Prompt, Argv: cmd.Args}
 
     err := cmd.Start()
     if err != nil {
-        r := Result{Name: b.Name, Passed: false, Effort: "poor", Quote: ripley.RandomQuoteByEffort("poor"), Output: err.Error()}
+        transcript.Stderr = err.Error()
+        r := Result{RunID: runID, Name: b.Name, Model: model, Passed: false, Effort: "poor", ErrorClass: ErrorExec, Quote: ripley.RandomQuoteByEffort("poor"), Output: err.Error(), Transcript: transcript}
         saveResult(r, db)
         return r
     }
@@ -72,57 +91,123 @@ func RunClaudeBenchmark(b Benchmark, db *storage.Storage) Result {
     case <-time.After(time.Duration(b.MaxDuration) * time.Second):
         _ = cmd.Process.Kill()
         duration := time.Since(start)
-        r = Result{Name: b.Name, Passed: false, Duration: duration, Output: "Timed out"}
+        <-done // the output buffers are only safe to read once Wait returns
+        r = Result{RunID: runID, Name: b.Name, Model: model, Passed: false, Duration: duration, ErrorClass: ErrorTimeout, Output: "Timed out"}
     case err := <-done:
         duration := time.Since(start)
         output := out.String()
         tokensUsed := len(strings.Fields(output))
-        passed := tokensUsed <= b.MaxTokens && duration.Seconds() <= float64(b.MaxDuration)
-        if err != nil {
-            passed = false
-        }
+        errorClass := classifyError(err, tokensUsed, duration, b)
 
         r = Result{
+            RunID:      runID,
             Name:       b.Name,
-            Passed:     passed,
+            Model:      model,
+            Passed:     errorClass == "",
             TokensUsed: tokensUsed,
             Duration:   duration,
+            ErrorClass: errorClass,
             Output:     strings.TrimSpace(output),
         }
     }
 
-    // Determine effort and assign Ripley quote
-    r.Effort = categorizeEffort(r, b)
+    transcript.Stdout = stdout.String()
+    transcript.Stderr = stderr.String()
+    transcript.Events = parseEvents(transcript.Stdout)
+    r.Transcript = transcript
+
+    // Score effort against recent history and assign Ripley quote
+    var avgTokens float64
+    if db != nil {
+        if avg, _, _, err := db.GetRollingStats(model, b.Name, scorer.Window); err == nil {
+            avgTokens = avg
+        }
+    }
+    r.EffortScore = scorer.Score(r, b, avgTokens)
+    r.Effort = scorer.Category(r.EffortScore, r.Passed)
     r.Quote = ripley.RandomQuoteByEffort(r.Effort)
 
     saveResult(r, db)
     return r
 }
 
+// Extract the JSON events from stream-style output (one JSON object per line).
+// Lines that are not JSON objects are ignored.
+func parseEvents(stdout string) []json.RawMessage {
+    var events []json.RawMessage
+    for _, line := range strings.Split(stdout, "\n") {
+        line = strings.TrimSpace(line)
+        if strings.HasPrefix(line, "{") && json.Valid([]byte(line)) {
+            events = append(events, json.RawMessage(line))
+        }
+    }
+    return events
+}
+
 // Save benchmark result to DB if storage is provided
-func saveResult(r Result, db *storage.Storage) {
+func saveResult(r Result, db storage.Store) {
     if db != nil {
-        _ = db.InsertRecord(storage.BenchmarkRecord{
-            Name:       r.Name,
-            Passed:     r.Passed,
-            TokensUsed: r.TokensUsed,
-            Duration:   r.Duration,
-            Quote:      r.Quote,
-            Output:     r.Output,
-            Timestamp:  time.Now(),
+        err := db.InsertRecord(storage.BenchmarkRecord{
+            RunID:       r.RunID,
+            Name:        r.Name,
+            Model:       r.Model,
+            Passed:      r.Passed,
+            TokensUsed:  r.TokensUsed,
+            Duration:    r.Duration,
+            EffortScore: r.EffortScore,
+            Effort:      r.Effort,
+            ErrorClass:  r.ErrorClass,
+            Quote:       r.Quote,
+            Output:      r.Output,
+            Timestamp:   time.Now(),
+            Transcript:  r.Transcript,
         })
+        if err != nil {
+            slog.Error("Failed to save result", "run_id", r.RunID, "benchmark", r.Name, "error", err)
+        }
     }
 }
 
-// Run all benchmarks
-func RunBenchmarks(db *storage.Storage) []Result {
+// Run all benchmarks against model as a single cycle sharing one run ID
+func RunBenchmarks(db storage.Store, model string, scorer EffortScorer) []Result {
+    runID := NewRunID(time.Now())
+
     var results []Result
     for _, b := range Benchmarks {
-        results = append(results, RunClaudeBenchmark(b, db))
+        results = append(results, RunClaudeBenchmark(b, model, runID, db, scorer))
     }
     return results
 }
 
+// NewRunID returns the identifier for a benchmark cycle started at t.
+// IDs sort in start order.
+func NewRunID(t time.Time) string {
+    return t.UTC().Format("20060102T150405.000000000Z")
+}
+
+// Log results as structured records, failures as warnings
+func LogResults(logger *slog.Logger, results []Result) {
+    for _, r := range results {
+        level := slog.LevelInfo
+        if !r.Passed {
+            level = slog.LevelWarn
+        }
+        logger.Log(context.Background(), level, "Benchmark finished",
+            "run_id", r.RunID,
+            "benchmark", r.Name,
+            "model", r.Model,
+            "passed", r.Passed,
+            "effort", r.Effort,
+            "effort_score", math.Round(r.EffortScore),
+            "tokens", r.TokensUsed,
+            "duration_seconds", r.Duration.Seconds(),
+            "error_class", r.ErrorClass,
+            "quote", r.Quote,
+            "output", r.Output,
+        )
+    }
+}
+
 // Print results with Ripley-style quotes
 func PrintResults(results []Result) {
     for _, r := range results {
@@ -130,7 +215,7 @@ func PrintResults(results []Result) {
         if !r.Passed {
             status = "FAIL"
         }
-        fmt.Printf("[%s] %s | Effort: %s | Tokens: %d | Duration: %s\nQuote: %s\nOutput: %s\n\n",
-            status, r.Name, r.Effort, r.TokensUsed, r.Duration, r.Quote, r.Output)
+        fmt.Printf("[%s] %s (%s) | Effort: %s (%.0f) | Tokens: %d | Duration: %s\nQuote: %s\nOutput: %s\n\n",
+            status, r.Name, r.Model, r.Effort, r.EffortScore, r.TokensUsed, r.Duration, r.Quote, r.Output)
     }
 }
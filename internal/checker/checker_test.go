@@ -1,11 +1,19 @@
 package checker
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
 
-func TestCategorizeEffort(t *testing.T) {
+func TestEffortScorerCategory(t *testing.T) {
 	benchmark := Benchmark{
 		Name:        "TestBench",
 		Prompt:      "test",
@@ -82,14 +90,37 @@ func TestCategorizeEffort(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			effort := categorizeEffort(tt.result, benchmark)
+			s := DefaultEffortScorer
+			effort := s.Category(s.Score(tt.result, benchmark, 0), tt.result.Passed)
 			if effort != tt.expected {
-				t.Errorf("categorizeEffort() = %v, want %v", effort, tt.expected)
+				t.Errorf("Category() = %v, want %v", effort, tt.expected)
 			}
 		})
 	}
 }
 
+func TestBudgetScore(t *testing.T) {
+	tests := []struct {
+		used     float64
+		limit    float64
+		expected float64
+	}{
+		{5, 10, 1},
+		{10, 10, 1},
+		{10.1, 10, 0.4975}, // Any overrun costs at least half
+		{20, 10, 0.25},
+		{30, 10, 0},
+		{45, 10, 0},
+		{45, 0, 1}, // No limit
+	}
+
+	for _, tt := range tests {
+		if got := budgetScore(tt.used, tt.limit); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("budgetScore(%v, %v) = %v, want %v", tt.used, tt.limit, got, tt.expected)
+		}
+	}
+}
+
 func TestBenchmarks(t *testing.T) {
 	// Verify benchmarks are properly defined
 	if len(Benchmarks) == 0 {
@@ -136,3 +167,159 @@ func TestResultStruct(t *testing.T) {
 		t.Errorf("Expected Effort='good', got '%s'", result.Effort)
 	}
 }
+
+func TestParseEvents(t *testing.T) {
+	stdout := "{\"type\":\"start\"}\nplain text\n  {\"type\":\"result\",\"text\":\"5050\"}  \n{broken\n"
+
+	events := parseEvents(stdout)
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if string(events[1]) != `{"type":"result","text":"5050"}` {
+		t.Errorf("Unexpected second event: %s", events[1])
+	}
+
+	if events := parseEvents("5050"); events != nil {
+		t.Errorf("Expected no events for plain output, got %v", events)
+	}
+}
+
+func TestEffortScore(t *testing.T) {
+	benchmark := Benchmark{Name: "TestBench", MaxTokens: 10, MaxDuration: 5}
+	within := Result{Passed: true, TokensUsed: 8, Duration: 3 * time.Second, Output: "5050"}
+
+	tests := []struct {
+		name      string
+		result    Result
+		avgTokens float64
+		expected  float64
+	}{
+		{"Within limits", within, 0, 100},
+		{"Consistent with history", within, 8, 100},
+		{"Twice the usual tokens", within, 4, 95},
+		{"Lazy answer", Result{Passed: true, TokensUsed: 8, Duration: time.Second, Output: "I think it is probably 5050..."}, 0, 90},
+		{"Over budget", Result{Passed: true, TokensUsed: 20, Duration: 10 * time.Second, Output: "5050"}, 0, 58.8},
+		{"Failed", Result{Passed: false, TokensUsed: 8, Duration: time.Second}, 0, 35},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := DefaultEffortScorer.Score(tt.result, benchmark, tt.avgTokens)
+			if score != tt.expected {
+				t.Errorf("Score() = %.1f, want %.1f", score, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEffortScorerThresholds(t *testing.T) {
+	scorer := DefaultEffortScorer
+	scorer.Good, scorer.Medium = 95, 70
+
+	tests := []struct {
+		score    float64
+		passed   bool
+		expected string
+	}{
+		{96, true, "good"},
+		{90, true, "medium"},
+		{60, true, "poor"},
+		{96, false, "poor"},
+	}
+
+	for _, tt := range tests {
+		if got := scorer.Category(tt.score, tt.passed); got != tt.expected {
+			t.Errorf("Category(%.0f, %v) = %s, want %s", tt.score, tt.passed, got, tt.expected)
+		}
+	}
+}
+
+func TestLazinessSignals(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected []string
+	}{
+		{"5050", nil},
+		{"[5, 4, 3, 2, 1]", nil},
+		{"As an AI, I cannot do arithmetic.", []string{"refusal"}},
+		{"It's probably 5050, you can check with a calculator", []string{"deferral", "hedging"}},
+		{"1, 2, 3, ... and so on", []string{"placeholder"}},
+	}
+
+	for _, tt := range tests {
+		got := LazinessSignals(tt.output)
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("LazinessSignals(%q) = %v, want %v", tt.output, got, tt.expected)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	benchmark := Benchmark{Name: "TestBench", MaxTokens: 10, MaxDuration: 5}
+
+	tests := []struct {
+		name     string
+		err      error
+		tokens   int
+		duration time.Duration
+		expected string
+	}{
+		{"passed", nil, 10, 5 * time.Second, ""},
+		{"nonzero exit", errors.New("exit status 1"), 3, time.Second, ErrorNonzeroExit},
+		{"over budget", nil, 11, time.Second, ErrorOverBudget},
+		{"too slow", nil, 3, 6 * time.Second, ErrorTooSlow},
+		{"exit error wins", errors.New("exit status 2"), 50, 9 * time.Second, ErrorNonzeroExit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err, tt.tokens, tt.duration, benchmark); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunClaudeBenchmarkTimeout(t *testing.T) {
+	// A fake claude that leaves a grandchild holding stdout open
+	dir := t.TempDir()
+	script := "#!/bin/sh\nsleep 30 &\nsleep 30\n"
+	if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake claude: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	start := time.Now()
+	r := RunClaudeBenchmark(Benchmark{Name: "Hang", Prompt: "hang", MaxTokens: 10, MaxDuration: 1}, "Sonnet", "run-01", nil, EffortScorer{})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the timeout to return promptly, took %s", elapsed)
+	}
+	if r.Passed || r.ErrorClass != ErrorTimeout {
+		t.Errorf("Expected a timeout, got %+v", r)
+	}
+}
+
+func TestLogResults(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	LogResults(logger, []Result{
+		{RunID: "run-01", Name: "Sum1to100", Model: "Sonnet", Passed: true, TokensUsed: 12, Duration: 1500 * time.Millisecond, Effort: "good", EffortScore: 91.6},
+		{RunID: "run-01", Name: "ListReverse", Model: "Sonnet", ErrorClass: ErrorTimeout},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+	var passed, failed map[string]any
+	json.Unmarshal([]byte(lines[0]), &passed)
+	json.Unmarshal([]byte(lines[1]), &failed)
+
+	if passed["level"] != "INFO" || passed["run_id"] != "run-01" || passed["benchmark"] != "Sum1to100" ||
+		passed["tokens"] != 12.0 || passed["effort_score"] != 92.0 || passed["duration_seconds"] != 1.5 {
+		t.Errorf("Unexpected record for a passed result: %v", passed)
+	}
+	if failed["level"] != "WARN" || failed["passed"] != false || failed["error_class"] != ErrorTimeout {
+		t.Errorf("Unexpected record for a failed result: %v", failed)
+	}
+}
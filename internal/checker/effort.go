@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"math"
+	"strings"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// EffortWeights sets the relative weight of each effort score component.
+// Weights need not sum to one; they are normalized by their sum.
+type EffortWeights struct {
+	Correctness float64
+	Tokens      float64
+	Latency     float64
+	Laziness    float64
+	Consistency float64
+}
+
+// EffortScorer turns a benchmark result into a 0-100 effort score and the
+// category derived from it.
+type EffortScorer struct {
+	Weights EffortWeights
+	Good    float64 // Minimum score for "good"
+	Medium  float64 // Minimum score for "medium"
+	Window  int     // Recent runs that consistency is measured against
+}
+
+// DefaultEffortScorer matches the defaults of the effort config section.
+var DefaultEffortScorer = EffortScorer{
+	Weights: EffortWeights{Correctness: 0.3, Tokens: 0.3, Latency: 0.25, Laziness: 0.1, Consistency: 0.05},
+	Good:    80,
+	Medium:  50,
+	Window:  10,
+}
+
+// NewEffortScorer returns the scorer configured in cfg.
+func NewEffortScorer(cfg *config.Config) EffortScorer {
+	w := cfg.Effort.Weights
+	return EffortScorer{
+		Weights: EffortWeights{
+			Correctness: w.Correctness,
+			Tokens:      w.Tokens,
+			Latency:     w.Latency,
+			Laziness:    w.Laziness,
+			Consistency: w.Consistency,
+		},
+		Good:   *cfg.Effort.Good,
+		Medium: *cfg.Effort.Medium,
+		Window: cfg.Monitoring.RollingWindow,
+	}
+}
+
+// Score returns the effort score (0-100) of r. avgTokens is the recent
+// average token count of the benchmark, or 0 when there is no history.
+// A failed run scores at most half, whatever its other components.
+func (s EffortScorer) Score(r Result, b Benchmark, avgTokens float64) float64 {
+	w := s.Weights
+	total := w.Correctness + w.Tokens + w.Latency + w.Laziness + w.Consistency
+	if total <= 0 {
+		return 0
+	}
+
+	correctness := 0.0
+	if r.Passed {
+		correctness = 1
+	}
+
+	score := w.Correctness*correctness +
+		w.Tokens*budgetScore(float64(r.TokensUsed), float64(b.MaxTokens)) +
+		w.Latency*budgetScore(r.Duration.Seconds(), float64(b.MaxDuration)) +
+		w.Laziness*lazinessScore(r.Output) +
+		w.Consistency*consistencyScore(r.TokensUsed, avgTokens)
+	score = score / total * 100
+
+	if !r.Passed {
+		score /= 2
+	}
+	return math.Round(score*10) / 10
+}
+
+// Category maps a score to "good", "medium" or "poor". Failed runs are
+// always poor.
+func (s EffortScorer) Category(score float64, passed bool) string {
+	switch {
+	case !passed:
+		return "poor"
+	case score >= s.Good:
+		return "good"
+	case score >= s.Medium:
+		return "medium"
+	default:
+		return "poor"
+	}
+}
+
+// budgetScore rates resource use against a limit: 1 within the limit, and
+// at most half for any overrun, falling to 0 at three times the limit. The
+// step at the limit is deliberate: MaxTokens and MaxDuration are budgets, so
+// going over at all costs half the component rather than a sliver of it.
+func budgetScore(used, limit float64) float64 {
+	if limit <= 0 || used <= limit {
+		return 1
+	}
+	return math.Max(0, (3-used/limit)/4)
+}
+
+// lazinessSignals are phrases that suggest the model avoided the work,
+// grouped by kind. Each kind found costs half of the laziness component.
+var lazinessSignals = []struct {
+	kind    string
+	phrases []string
+}{
+	{"refusal", []string{"i can't", "i cannot", "i'm unable", "i am unable", "as an ai"}},
+	{"deferral", []string{"try it yourself", "left as an exercise", "you can calculate", "you can check"}},
+	{"placeholder", []string{"...", "…", "etc.", "and so on", "rest of the"}},
+	{"hedging", []string{"i think", "probably", "not sure", "approximately"}},
+}
+
+// LazinessSignals returns the kinds of laziness signal found in output.
+func LazinessSignals(output string) []string {
+	lower := strings.ToLower(output)
+
+	var found []string
+	for _, signal := range lazinessSignals {
+		for _, phrase := range signal.phrases {
+			if strings.Contains(lower, phrase) {
+				found = append(found, signal.kind)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func lazinessScore(output string) float64 {
+	return math.Max(0, 1-0.5*float64(len(LazinessSignals(output))))
+}
+
+// consistencyScore rates how close tokens is to the recent average: 1 at
+// the average, 0 at double or none. Without history every run is consistent.
+func consistencyScore(tokens int, avgTokens float64) float64 {
+	if avgTokens <= 0 {
+		return 1
+	}
+	return math.Max(0, 1-math.Abs(float64(tokens)-avgTokens)/avgTokens)
+}
@@ -3,7 +3,13 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -13,7 +19,7 @@ import (
 type Config struct {
 	Daemon struct {
 		Interval string `yaml:"interval"` // e.g. "30m", "1h"
-		DBPath   string `yaml:"db_path"`
+		DBPath   string `yaml:"db_path"`  // SQLite path or storage DSN (see storage.Open)
 	} `yaml:"daemon"`
 
 	Claude struct {
@@ -25,6 +31,234 @@ type Config struct {
 		RollingWindow    int     `yaml:"rolling_window"`
 		WarningThreshold float64 `yaml:"warning_threshold"`
 	} `yaml:"monitoring"`
+
+	Logging struct {
+		Level      string `yaml:"level"`       // debug, info, warn or error
+		Format     string `yaml:"format"`      // "text" or "json"
+		File       string `yaml:"file"`        // Empty for stderr
+		MaxSizeMB  int    `yaml:"max_size_mb"` // Size at which the file is rotated
+		MaxBackups *int   `yaml:"max_backups"` // Rotated files kept; 0 keeps none
+	} `yaml:"logging"`
+
+	Spool struct {
+		Path string `yaml:"path"` // JSONL file for results the database could not store
+	} `yaml:"spool"`
+
+	Backup struct {
+		Interval string `yaml:"interval"` // e.g. "24h"; empty disables scheduled backups
+		Dir      string `yaml:"dir"`
+		Keep     *int   `yaml:"keep"` // Number of scheduled backups to retain; 0 keeps every backup
+	} `yaml:"backup"`
+
+	Server struct {
+		Listen string `yaml:"listen"` // e.g. ":9120"; empty disables the HTTP listener
+	} `yaml:"server"`
+
+	Health struct {
+		StaleAfter string `yaml:"stale_after"` // No cycle finished for this long fails /healthz; empty for three intervals
+		Heartbeat  struct {
+			URL     string `yaml:"url"`      // Pinged after every successful cycle; empty disables
+			FailURL string `yaml:"fail_url"` // Pinged after failed cycles; empty to skip them
+			Method  string `yaml:"method"`   // GET, HEAD or POST (with a JSON summary of the cycle)
+			Timeout string `yaml:"timeout"`
+		} `yaml:"heartbeat"`
+	} `yaml:"health"`
+
+	Metrics struct {
+		Textfile string `yaml:"textfile"` // Written after every cycle, e.g. for node_exporter; empty disables
+		Format   string `yaml:"format"`   // "prometheus" or "openmetrics"
+	} `yaml:"metrics"`
+
+	Regression struct {
+		Window     int      `yaml:"window"`      // Recent runs per benchmark to analyze
+		Confidence *float64 `yaml:"confidence"`  // Minimum confidence (0-1) to report a shift
+		MinSegment int      `yaml:"min_segment"` // Minimum runs on each side of a shift
+		Bootstraps int      `yaml:"bootstraps"`  // Reorderings used to estimate confidence
+	} `yaml:"regression"`
+
+	Drift struct {
+		Window  int `yaml:"window"`  // Recent answers the dominant answer form is taken from
+		History int `yaml:"history"` // Recent runs per benchmark searched for drift
+	} `yaml:"drift"`
+
+	Incidents struct {
+		ConsecutiveFailures *int     `yaml:"consecutive_failures"` // Failed runs (or cycles) in a row that open an incident; 0 opens none
+		SuiteFailureRate    *float64 `yaml:"suite_failure_rate"`   // Share (0-1) of failed benchmarks that makes a cycle fail; 0 means any
+	} `yaml:"incidents"`
+
+	Effort struct {
+		Weights struct {
+			Correctness float64 `yaml:"correctness"`
+			Tokens      float64 `yaml:"tokens"`
+			Latency     float64 `yaml:"latency"`
+			Laziness    float64 `yaml:"laziness"`
+			Consistency float64 `yaml:"consistency"`
+		} `yaml:"weights"` // Relative; normalized by their sum
+		Good   *float64 `yaml:"good"`   // Minimum score (0-100) for "good"
+		Medium *float64 `yaml:"medium"` // Minimum score (0-100) for "medium"; at most good
+	} `yaml:"effort"`
+
+	Cycles struct {
+		OutageShare *float64 `yaml:"outage_share"` // Share (0-1) of benchmarks failing on infrastructure errors that makes an outage
+	} `yaml:"cycles"`
+
+	Flaky struct {
+		Window      int      `yaml:"window"`      // Recent results assessed per benchmark
+		MinRuns     int      `yaml:"min_runs"`    // Benchmarks with fewer results are not flaky
+		Alternation *float64 `yaml:"alternation"` // Minimum share (0-1) of consecutive results with a different outcome
+		Entropy     *float64 `yaml:"entropy"`     // Minimum outcome entropy in bits (0-1)
+		Quarantine  bool     `yaml:"quarantine"`  // Exclude flaky benchmarks from alerts and suite-wide aggregates
+	} `yaml:"flaky"`
+
+	SLO struct {
+		Objectives []Objective `yaml:"objectives"`
+		BurnAlerts []BurnAlert `yaml:"burn_alerts"` // Multi-window burn-rate alerts, fastest first; empty for none
+	} `yaml:"slo"`
+
+	Alerts struct {
+		Cooldown       string `yaml:"cooldown"`        // Minimum time between notifications of one alert
+		RepeatInterval string `yaml:"repeat_interval"` // Re-notify alerts still firing this often; "0" never
+		FlapWindow     string `yaml:"flap_window"`     // Period over which state changes are counted
+		FlapThreshold  *int   `yaml:"flap_threshold"`  // State changes within flap_window that make an alert flapping; 0 never
+
+		// Evaluated after every cycle; a rule on monitoring.warning_threshold
+		// when empty
+		Rules []AlertRule `yaml:"rules"`
+	} `yaml:"alerts"`
+
+	Notify struct {
+		Webhooks []Webhook     `yaml:"webhooks"`
+		Slack    []Destination `yaml:"slack"`   // Slack incoming webhooks, one per channel
+		Discord  []Destination `yaml:"discord"` // Discord channel webhooks
+		Email    []Email       `yaml:"email"`
+	} `yaml:"notify"`
+
+	Hooks struct {
+		MaxConcurrent int    `yaml:"max_concurrent"` // Hooks running at once; the others wait their turn
+		Commands      []Hook `yaml:"commands"`
+	} `yaml:"hooks"`
+
+	Analysis struct {
+		Timezone string `yaml:"timezone"` // IANA name, e.g. "America/New_York"; empty for local time
+	} `yaml:"analysis"`
+
+	Pricing map[string]float64 `yaml:"pricing"` // USD per million tokens, by model name
+}
+
+// Objective is a service level objective: the share of runs that must be
+// good over a rolling window.
+type Objective struct {
+	Name      string  `yaml:"name"`
+	Benchmark string  `yaml:"benchmark"` // Empty for every benchmark in the suite
+	Target    float64 `yaml:"target"`    // Share (0-1) of good runs, e.g. 0.99
+	Window    string  `yaml:"window"`    // e.g. "30d"
+	Latency   string  `yaml:"latency"`   // If set, passed runs faster than this are good; otherwise every passed run is
+}
+
+// BurnAlert fires when the error budget burns at least BurnRate times the
+// sustainable rate over both the Long and the Short window.
+type BurnAlert struct {
+	Long     string  `yaml:"long"`  // e.g. "6h"
+	Short    string  `yaml:"short"` // e.g. "30m"
+	BurnRate float64 `yaml:"burn_rate"`
+	Severity string  `yaml:"severity"` // "page" or "ticket"
+}
+
+// AlertRule fires while a metric over the recent results of a benchmark, or
+// of several together, compares to a threshold.
+type AlertRule struct {
+	Name           string            `yaml:"name"`
+	Metric         string            `yaml:"metric"`          // pass_rate, effort, p95_latency, tokens, errors or consecutive_failures
+	ErrorClass     string            `yaml:"error_class"`     // For errors, only count this class; empty for every failure
+	Runs           int               `yaml:"runs"`            // Latest results, or cycles for suite rules; monitoring.rolling_window without a window
+	Window         string            `yaml:"window"`          // Or the results this recent, e.g. "6h"
+	MinRuns        int               `yaml:"min_runs"`        // Fewer results are not evaluated
+	Op             string            `yaml:"op"`              // <, <=, >, >=, == or !=
+	Threshold      float64           `yaml:"threshold"`       // Pass rate 0-1, effort 0-100, latency in seconds, counts
+	Severity       string            `yaml:"severity"`        // info, warning or critical
+	Labels         map[string]string `yaml:"labels"`          // Added to the alert's events
+	Benchmarks     []string          `yaml:"benchmarks"`      // Empty for every benchmark
+	Suite          bool              `yaml:"suite"`           // One alert over the benchmarks' results together, not one each
+	IncludeOutages bool              `yaml:"include_outages"` // Also evaluate results of cycles labeled outage
+}
+
+// Hook is a shell command run on events, with the event as JSON on stdin.
+type Hook struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"` // Run by /bin/sh -c
+	Events  []string          `yaml:"events"`  // See HookEvents; empty for every event
+	Timeout string            `yaml:"timeout"` // e.g. "30s"; the command is killed after it
+	Env     map[string]string `yaml:"env"`     // Added to the daemon's environment
+}
+
+// HookEvents are the events hooks can run on.
+var HookEvents = []string{"cycle", "alert_firing", "alert_resolved", "alert_flapping", "incident_opened", "incident_resolved"}
+
+// Alert rule metrics
+var alertMetrics = []string{"pass_rate", "effort", "p95_latency", "tokens", "errors", "consecutive_failures"}
+
+// Destination is a URL that notifications are delivered to, and which of
+// them it gets.
+type Destination struct {
+	Name        string   `yaml:"name"`
+	URL         string   `yaml:"url"`
+	MinSeverity string   `yaml:"min_severity"` // "info", "warning" or "critical"; empty for every event
+	Tags        []string `yaml:"tags"`         // Only events with at least one of these tags; empty for every event
+	Timeout     string   `yaml:"timeout"`      // Per attempt, e.g. "10s"
+	Attempts    int      `yaml:"attempts"`     // Total attempts per event, including the first
+	Backoff     string   `yaml:"backoff"`      // Wait before the first retry, doubled after each
+}
+
+// Webhook is a destination that events are posted to as JSON.
+type Webhook struct {
+	Destination `yaml:",inline"`
+	Secret      string            `yaml:"secret"`  // HMAC-SHA256 key for the signature header; empty leaves requests unsigned
+	Headers     map[string]string `yaml:"headers"` // Extra request headers, e.g. Authorization
+}
+
+// Email is a list of recipients that get alerts and periodic digests by
+// SMTP.
+type Email struct {
+	Name        string   `yaml:"name"`
+	MinSeverity string   `yaml:"min_severity"` // As for Destination
+	Tags        []string `yaml:"tags"`         // As for Destination
+	Host        string   `yaml:"host"`
+	Port        int      `yaml:"port"`     // Defaults to 587, or 465 with implicit TLS
+	TLS         string   `yaml:"tls"`      // "starttls" (required, the default), "implicit" or "none"
+	Username    string   `yaml:"username"` // Empty to send without authenticating
+	Password    string   `yaml:"password"`
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to"`
+	Timeout     string   `yaml:"timeout"`     // For the whole exchange with the server, e.g. "30s"
+	Digest      string   `yaml:"digest"`      // "daily" or "weekly"; empty for no digest
+	DigestAt    string   `yaml:"digest_at"`   // Local time of day to send the digest, e.g. "08:00"
+	DigestDay   string   `yaml:"digest_day"`  // Day of the week for weekly digests, e.g. "monday"
+	DigestOnly  bool     `yaml:"digest_only"` // Send only the digest, no alerts
+}
+
+// Weekday parses the name of a day of the week, e.g. "monday".
+func Weekday(name string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), name) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week %q", name)
+}
+
+// ParseDuration parses a Go duration ("90m", "6h") or a number of days or
+// weeks ("30d", "2w").
+func ParseDuration(s string) (time.Duration, error) {
+	for unit, length := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(s, unit); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n) * length, nil
+		}
+	}
+	return time.ParseDuration(s)
 }
 
 // Load reads and parses a YAML configuration file.
@@ -39,6 +273,8 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	cfg.applyDefaults()
+
 	// Validate
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -61,9 +297,210 @@ func LoadWithDefaults() *Config {
 	cfg.Monitoring.RollingWindow = 10
 	cfg.Monitoring.WarningThreshold = 0.7
 
+	cfg.applyDefaults()
+
 	return cfg
 }
 
+// applyDefaults fills in optional settings that were left unset.
+// Required settings are left alone so that validate can report them.
+func (c *Config) applyDefaults() {
+	if c.Spool.Path == "" {
+		c.Spool.Path = "./ripley.spool.jsonl"
+	}
+
+	if c.Backup.Dir == "" {
+		c.Backup.Dir = "./backups"
+	}
+	// A pointer, since an explicit 0 keeps every backup
+	if c.Backup.Keep == nil {
+		keep := 7
+		c.Backup.Keep = &keep
+	}
+
+	if c.Regression.Window == 0 {
+		c.Regression.Window = 100
+	}
+	// A pointer, since an explicit 0 reports every shift found
+	if c.Regression.Confidence == nil {
+		confidence := 0.95
+		c.Regression.Confidence = &confidence
+	}
+	if c.Regression.MinSegment == 0 {
+		c.Regression.MinSegment = 5
+	}
+	if c.Regression.Bootstraps == 0 {
+		c.Regression.Bootstraps = 1000
+	}
+
+	if c.Drift.Window == 0 {
+		c.Drift.Window = 20
+	}
+	if c.Drift.History == 0 {
+		c.Drift.History = 500
+	}
+
+	// A pointer, since an explicit 0 turns incidents off
+	if c.Incidents.ConsecutiveFailures == nil {
+		failures := 3
+		c.Incidents.ConsecutiveFailures = &failures
+	}
+	// A pointer, since an explicit 0 fails a cycle on any failed benchmark
+	if c.Incidents.SuiteFailureRate == nil {
+		rate := 0.5
+		c.Incidents.SuiteFailureRate = &rate
+	}
+
+	w := &c.Effort.Weights
+	if w.Correctness == 0 && w.Tokens == 0 && w.Latency == 0 && w.Laziness == 0 && w.Consistency == 0 {
+		w.Correctness, w.Tokens, w.Latency, w.Laziness, w.Consistency = 0.3, 0.3, 0.25, 0.1, 0.05
+	}
+	// A pointer, since an explicit 0 makes any infrastructure failure an outage
+	if c.Cycles.OutageShare == nil {
+		share := 1.0
+		c.Cycles.OutageShare = &share
+	}
+
+	if c.Flaky.Window == 0 {
+		c.Flaky.Window = 30
+	}
+	if c.Flaky.MinRuns == 0 {
+		c.Flaky.MinRuns = 10
+	}
+	// Pointers, since an explicit 0 leaves that criterion out
+	if c.Flaky.Alternation == nil {
+		alternation := 0.3
+		c.Flaky.Alternation = &alternation
+	}
+	if c.Flaky.Entropy == nil {
+		entropy := 0.7
+		c.Flaky.Entropy = &entropy
+	}
+
+	// Only when unset: an explicit empty list (burn_alerts: []) turns
+	// burn-rate alerting off
+	if c.SLO.BurnAlerts == nil {
+		c.SLO.BurnAlerts = []BurnAlert{
+			{Long: "1h", Short: "5m", BurnRate: 14.4, Severity: "page"},
+			{Long: "6h", Short: "30m", BurnRate: 6, Severity: "page"},
+			{Long: "3d", Short: "6h", BurnRate: 1, Severity: "ticket"},
+		}
+	}
+	if c.Alerts.Cooldown == "" {
+		c.Alerts.Cooldown = "1h"
+	}
+	if c.Alerts.RepeatInterval == "" {
+		c.Alerts.RepeatInterval = "24h"
+	}
+	if c.Alerts.FlapWindow == "" {
+		c.Alerts.FlapWindow = "6h"
+	}
+	// A pointer, since an explicit 0 turns flap suppression off
+	if c.Alerts.FlapThreshold == nil {
+		threshold := 4
+		c.Alerts.FlapThreshold = &threshold
+	}
+	if len(c.Alerts.Rules) == 0 {
+		c.Alerts.Rules = []AlertRule{{
+			Name:      "pass_rate",
+			Metric:    "pass_rate",
+			Op:        "<",
+			Threshold: c.Monitoring.WarningThreshold,
+		}}
+	}
+	for i := range c.Alerts.Rules {
+		r := &c.Alerts.Rules[i]
+		if r.Runs == 0 && r.Window == "" {
+			r.Runs = c.Monitoring.RollingWindow
+		}
+		if r.MinRuns == 0 {
+			r.MinRuns = 1
+		}
+		if r.Severity == "" {
+			r.Severity = "warning"
+		}
+	}
+	for _, d := range c.destinations() {
+		if d.Timeout == "" {
+			d.Timeout = "10s"
+		}
+		if d.Attempts == 0 {
+			d.Attempts = 3
+		}
+		if d.Backoff == "" {
+			d.Backoff = "1s"
+		}
+	}
+	if c.Logging.Level == "" {
+		c.Logging.Level = "info"
+	}
+	if c.Logging.Format == "" {
+		c.Logging.Format = "text"
+	}
+	if c.Logging.MaxSizeMB == 0 {
+		c.Logging.MaxSizeMB = 10
+	}
+	// A pointer, since an explicit 0 keeps no rotated files
+	if c.Logging.MaxBackups == nil {
+		backups := 5
+		c.Logging.MaxBackups = &backups
+	}
+	if c.Health.Heartbeat.Method == "" {
+		c.Health.Heartbeat.Method = "GET"
+	}
+	if c.Health.Heartbeat.Timeout == "" {
+		c.Health.Heartbeat.Timeout = "10s"
+	}
+	if c.Metrics.Format == "" {
+		c.Metrics.Format = "prometheus"
+	}
+	if c.Hooks.MaxConcurrent == 0 {
+		c.Hooks.MaxConcurrent = 4
+	}
+	for i := range c.Hooks.Commands {
+		if c.Hooks.Commands[i].Timeout == "" {
+			c.Hooks.Commands[i].Timeout = "30s"
+		}
+	}
+	for i := range c.Notify.Email {
+		e := &c.Notify.Email[i]
+		if e.TLS == "" {
+			e.TLS = "starttls"
+		}
+		if e.Port == 0 {
+			e.Port = 587
+			if e.TLS == "implicit" {
+				e.Port = 465
+			}
+		}
+		if e.Timeout == "" {
+			e.Timeout = "30s"
+		}
+		if e.DigestAt == "" {
+			e.DigestAt = "08:00"
+		}
+		if e.DigestDay == "" {
+			e.DigestDay = "monday"
+		}
+	}
+	for i := range c.SLO.Objectives {
+		if c.SLO.Objectives[i].Window == "" {
+			c.SLO.Objectives[i].Window = "30d"
+		}
+	}
+
+	// Pointers, since an explicit 0 makes every run good or medium. An unset
+	// medium never exceeds good, so lowering good alone stays valid.
+	if c.Effort.Good == nil {
+		good := 80.0
+		c.Effort.Good = &good
+	}
+	if c.Effort.Medium == nil {
+		medium := min(50, *c.Effort.Good)
+		c.Effort.Medium = &medium
+	}
+}
+
 // GetInterval parses the interval string and returns a time.Duration.
 func (c *Config) GetInterval() (time.Duration, error) {
 	duration, err := time.ParseDuration(c.Daemon.Interval)
@@ -73,6 +510,31 @@ func (c *Config) GetInterval() (time.Duration, error) {
 	return duration, nil
 }
 
+// GetBackupInterval parses the backup interval, in days or weeks too. Zero
+// means scheduled backups are disabled.
+func (c *Config) GetBackupInterval() (time.Duration, error) {
+	if c.Backup.Interval == "" {
+		return 0, nil
+	}
+	duration, err := ParseDuration(c.Backup.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid backup interval format: %w", err)
+	}
+	return duration, nil
+}
+
+// GetLocation returns the timezone used to bucket results by time of day.
+func (c *Config) GetLocation() (*time.Location, error) {
+	if c.Analysis.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Analysis.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid analysis timezone: %w", err)
+	}
+	return loc, nil
+}
+
 // validate checks that all required fields are set and valid.
 func (c *Config) validate() error {
 	if c.Daemon.Interval == "" {
@@ -99,5 +561,387 @@ func (c *Config) validate() error {
 		return fmt.Errorf("monitoring.warning_threshold must be between 0 and 1")
 	}
 
+	interval, err := c.GetBackupInterval()
+	if err != nil || interval < 0 {
+		return fmt.Errorf("backup.interval must be a positive duration (e.g. '24h')")
+	}
+	// Online backups only work on SQLite (see storage.Backup)
+	dsn := c.Daemon.DBPath
+	if interval > 0 && (strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") || dsn == "memory:") {
+		return fmt.Errorf("backup.interval requires a SQLite daemon.db_path")
+	}
+
+	if k := c.Backup.Keep; k != nil && *k < 0 {
+		return fmt.Errorf("backup.keep must not be negative")
+	}
+
+	if c.Regression.Window < 0 || c.Regression.MinSegment < 0 || c.Regression.Bootstraps < 0 {
+		return fmt.Errorf("regression.window, regression.min_segment and regression.bootstraps must not be negative")
+	}
+
+	if p := c.Regression.Confidence; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("regression.confidence must be between 0 and 1")
+	}
+
+	if c.Drift.Window < 0 || c.Drift.History < 0 {
+		return fmt.Errorf("drift.window and drift.history must not be negative")
+	}
+
+	if n := c.Incidents.ConsecutiveFailures; n != nil && *n < 0 {
+		return fmt.Errorf("incidents.consecutive_failures must not be negative")
+	}
+
+	if p := c.Incidents.SuiteFailureRate; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("incidents.suite_failure_rate must be between 0 and 1")
+	}
+
+	w := c.Effort.Weights
+	if w.Correctness < 0 || w.Tokens < 0 || w.Latency < 0 || w.Laziness < 0 || w.Consistency < 0 {
+		return fmt.Errorf("effort.weights must not be negative")
+	}
+
+	if good, medium := c.Effort.Good, c.Effort.Medium; good != nil && medium != nil && (*medium < 0 || *medium > *good || *good > 100) {
+		return fmt.Errorf("effort.medium and effort.good must satisfy 0 <= medium <= good <= 100")
+	}
+
+	for model, price := range c.Pricing {
+		if price < 0 {
+			return fmt.Errorf("pricing for %s must not be negative", model)
+		}
+	}
+
+	if p := c.Cycles.OutageShare; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("cycles.outage_share must be between 0 and 1")
+	}
+
+	if c.Flaky.Window < 0 || c.Flaky.MinRuns < 0 {
+		return fmt.Errorf("flaky.window and flaky.min_runs must not be negative")
+	}
+
+	if a, e := c.Flaky.Alternation, c.Flaky.Entropy; (a != nil && (*a < 0 || *a > 1)) || (e != nil && (*e < 0 || *e > 1)) {
+		return fmt.Errorf("flaky.alternation and flaky.entropy must be between 0 and 1")
+	}
+
+	for _, d := range []struct{ name, value string }{
+		{"alerts.cooldown", c.Alerts.Cooldown},
+		{"alerts.repeat_interval", c.Alerts.RepeatInterval},
+		{"alerts.flap_window", c.Alerts.FlapWindow},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if duration, err := ParseDuration(d.value); err != nil || duration < 0 {
+			return fmt.Errorf("%s must be a duration (e.g. '6h')", d.name)
+		}
+	}
+	if t := c.Alerts.FlapThreshold; t != nil && (*t == 1 || *t < 0) {
+		return fmt.Errorf("alerts.flap_threshold must be 0 or at least 2")
+	}
+	if err := c.validateRules(); err != nil {
+		return err
+	}
+
+	if err := c.validateNotify(); err != nil {
+		return err
+	}
+
+	if err := c.validateSLO(); err != nil {
+		return err
+	}
+
+	if err := c.validateHooks(); err != nil {
+		return err
+	}
+
+	if c.Server.Listen != "" {
+		if _, port, err := net.SplitHostPort(c.Server.Listen); err != nil || port == "" {
+			return fmt.Errorf("server.listen must be a host:port address (e.g. ':9120')")
+		}
+	}
+
+	if err := c.validateHealth(); err != nil {
+		return err
+	}
+
+	if c.Logging.Level != "" && !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Logging.Level) {
+		return fmt.Errorf("logging.level must be 'debug', 'info', 'warn' or 'error'")
+	}
+	if c.Logging.Format != "" && c.Logging.Format != "text" && c.Logging.Format != "json" {
+		return fmt.Errorf("logging.format must be 'text' or 'json'")
+	}
+	if c.Logging.MaxSizeMB < 0 || (c.Logging.MaxBackups != nil && *c.Logging.MaxBackups < 0) {
+		return fmt.Errorf("logging.max_size_mb and logging.max_backups must not be negative")
+	}
+
+	if c.Metrics.Format != "" && c.Metrics.Format != "prometheus" && c.Metrics.Format != "openmetrics" {
+		return fmt.Errorf("metrics.format must be 'prometheus' or 'openmetrics'")
+	}
+
+	if _, err := c.GetLocation(); err != nil {
+		return fmt.Errorf("analysis.timezone must be an IANA timezone name (e.g. 'America/New_York'): %w", err)
+	}
+
+	return nil
+}
+
+// validateRules checks the alert rules.
+func (c *Config) validateRules() error {
+	names := make(map[string]bool)
+	for _, r := range c.Alerts.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("every alert rule needs a name")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("alert rule %s is defined twice", r.Name)
+		}
+		names[r.Name] = true
+		if strings.HasPrefix(r.Name, "slo/") {
+			return fmt.Errorf("alert rule %s: names starting with 'slo/' are reserved for SLO burn-rate alerts", r.Name)
+		}
+
+		if !slices.Contains(alertMetrics, r.Metric) {
+			return fmt.Errorf("alert rule %s: metric must be one of %s", r.Name, strings.Join(alertMetrics, ", "))
+		}
+		if r.ErrorClass != "" && r.Metric != "errors" {
+			return fmt.Errorf("alert rule %s: error_class only applies to the errors metric", r.Name)
+		}
+		switch r.Op {
+		case "<", "<=", ">", ">=", "==", "!=":
+		default:
+			return fmt.Errorf("alert rule %s: op must be <, <=, >, >=, == or !=", r.Name)
+		}
+		switch r.Severity {
+		case "", "info", "warning", "critical":
+		default:
+			return fmt.Errorf("alert rule %s: severity must be 'info', 'warning' or 'critical'", r.Name)
+		}
+
+		if r.Runs < 0 || r.MinRuns < 0 {
+			return fmt.Errorf("alert rule %s: runs and min_runs must not be negative", r.Name)
+		}
+		if r.Window != "" {
+			if r.Runs > 0 {
+				return fmt.Errorf("alert rule %s: set runs or window, not both", r.Name)
+			}
+			if window, err := ParseDuration(r.Window); err != nil || window <= 0 {
+				return fmt.Errorf("alert rule %s: window must be a positive duration (e.g. '6h')", r.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// destinations returns every notification destination, in the order
+// webhooks, Slack, Discord.
+func (c *Config) destinations() []*Destination {
+	var all []*Destination
+	for i := range c.Notify.Webhooks {
+		all = append(all, &c.Notify.Webhooks[i].Destination)
+	}
+	for i := range c.Notify.Slack {
+		all = append(all, &c.Notify.Slack[i])
+	}
+	for i := range c.Notify.Discord {
+		all = append(all, &c.Notify.Discord[i])
+	}
+	return all
+}
+
+func (c *Config) validateNotify() error {
+	names := make(map[string]bool)
+	for _, d := range c.destinations() {
+		if d.Name == "" {
+			return fmt.Errorf("every notify destination needs a name")
+		}
+		if names[d.Name] {
+			return fmt.Errorf("notify destination %s is defined twice", d.Name)
+		}
+		names[d.Name] = true
+
+		if u, err := url.Parse(d.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notify destination %s: url must be an http or https URL", d.Name)
+		}
+		switch d.MinSeverity {
+		case "", "info", "warning", "critical":
+		default:
+			return fmt.Errorf("notify destination %s: min_severity must be 'info', 'warning' or 'critical'", d.Name)
+		}
+		if timeout, err := time.ParseDuration(d.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("notify destination %s: timeout must be a positive duration (e.g. '10s')", d.Name)
+		}
+		if d.Attempts < 1 {
+			return fmt.Errorf("notify destination %s: attempts must be at least 1", d.Name)
+		}
+		if backoff, err := time.ParseDuration(d.Backoff); err != nil || backoff < 0 {
+			return fmt.Errorf("notify destination %s: backoff must be a duration (e.g. '1s')", d.Name)
+		}
+	}
+
+	for _, e := range c.Notify.Email {
+		if e.Name == "" {
+			return fmt.Errorf("every notify destination needs a name")
+		}
+		if names[e.Name] {
+			return fmt.Errorf("notify destination %s is defined twice", e.Name)
+		}
+		names[e.Name] = true
+
+		if err := e.validate(); err != nil {
+			return fmt.Errorf("notify destination %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+func (e Email) validate() error {
+	switch e.MinSeverity {
+	case "", "info", "warning", "critical":
+	default:
+		return fmt.Errorf("min_severity must be 'info', 'warning' or 'critical'")
+	}
+	if e.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if e.Port < 1 || e.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if e.TLS != "starttls" && e.TLS != "implicit" && e.TLS != "none" {
+		return fmt.Errorf("tls must be 'starttls', 'implicit' or 'none'")
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("from must be an email address: %w", err)
+	}
+	if len(e.To) == 0 {
+		return fmt.Errorf("to needs at least one recipient")
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+	}
+	if d, err := time.ParseDuration(e.Timeout); err != nil || d <= 0 {
+		return fmt.Errorf("timeout must be a positive duration (e.g. '30s')")
+	}
+
+	if e.Digest != "" && e.Digest != "daily" && e.Digest != "weekly" {
+		return fmt.Errorf("digest must be 'daily' or 'weekly'")
+	}
+	if e.DigestOnly && e.Digest == "" {
+		return fmt.Errorf("digest_only needs a digest")
+	}
+	if _, err := time.Parse("15:04", e.DigestAt); err != nil {
+		return fmt.Errorf("digest_at must be a time of day (e.g. '08:00')")
+	}
+	if _, err := Weekday(e.DigestDay); err != nil {
+		return fmt.Errorf("digest_day must be a day of the week (e.g. 'monday')")
+	}
+	return nil
+}
+
+// validateHealth checks the health check and heartbeat settings.
+func (c *Config) validateHealth() error {
+	if c.Health.StaleAfter != "" {
+		if d, err := ParseDuration(c.Health.StaleAfter); err != nil || d <= 0 {
+			return fmt.Errorf("health.stale_after must be a positive duration (e.g. '2h')")
+		}
+	}
+	hb := c.Health.Heartbeat
+	for _, u := range []string{hb.URL, hb.FailURL} {
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("health.heartbeat urls must be http or https URLs")
+		}
+	}
+	if hb.FailURL != "" && hb.URL == "" {
+		return fmt.Errorf("health.heartbeat.fail_url requires health.heartbeat.url")
+	}
+	switch hb.Method {
+	case "", "GET", "HEAD", "POST":
+	default:
+		return fmt.Errorf("health.heartbeat.method must be 'GET', 'HEAD' or 'POST'")
+	}
+	if hb.Timeout != "" {
+		if timeout, err := time.ParseDuration(hb.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("health.heartbeat.timeout must be a positive duration (e.g. '10s')")
+		}
+	}
+	return nil
+}
+
+// validateHooks checks the hook commands.
+func (c *Config) validateHooks() error {
+	if c.Hooks.MaxConcurrent < 0 {
+		return fmt.Errorf("hooks.max_concurrent must not be negative")
+	}
+	names := make(map[string]bool)
+	for _, h := range c.Hooks.Commands {
+		if h.Name == "" {
+			return fmt.Errorf("every hook needs a name")
+		}
+		if names[h.Name] {
+			return fmt.Errorf("hook %s is defined twice", h.Name)
+		}
+		names[h.Name] = true
+
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("hook %s: command is required", h.Name)
+		}
+		for _, e := range h.Events {
+			if !slices.Contains(HookEvents, e) {
+				return fmt.Errorf("hook %s: unknown event %q; events are %s", h.Name, e, strings.Join(HookEvents, ", "))
+			}
+		}
+		if h.Timeout != "" {
+			if timeout, err := time.ParseDuration(h.Timeout); err != nil || timeout <= 0 {
+				return fmt.Errorf("hook %s: timeout must be a positive duration (e.g. '30s')", h.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSLO checks the objectives and burn-rate alerts.
+func (c *Config) validateSLO() error {
+	names := make(map[string]bool)
+	for i, o := range c.SLO.Objectives {
+		if o.Name == "" {
+			return fmt.Errorf("slo.objectives[%d].name is required", i)
+		}
+		if names[o.Name] {
+			return fmt.Errorf("slo objective %s is defined twice", o.Name)
+		}
+		names[o.Name] = true
+
+		if o.Target <= 0 || o.Target >= 1 {
+			return fmt.Errorf("slo objective %s: target must be between 0 and 1 (exclusive)", o.Name)
+		}
+		if d, err := ParseDuration(o.Window); err != nil || d <= 0 {
+			return fmt.Errorf("slo objective %s: window must be a positive duration (e.g. '30d')", o.Name)
+		}
+		if o.Latency != "" {
+			if d, err := time.ParseDuration(o.Latency); err != nil || d <= 0 {
+				return fmt.Errorf("slo objective %s: latency must be a positive duration (e.g. '4s')", o.Name)
+			}
+		}
+	}
+
+	for i, a := range c.SLO.BurnAlerts {
+		long, err := ParseDuration(a.Long)
+		if err != nil || long <= 0 {
+			return fmt.Errorf("slo.burn_alerts[%d].long must be a positive duration", i)
+		}
+		short, err := ParseDuration(a.Short)
+		if err != nil || short <= 0 || short > long {
+			return fmt.Errorf("slo.burn_alerts[%d].short must be a positive duration no longer than long", i)
+		}
+		if a.BurnRate <= 0 {
+			return fmt.Errorf("slo.burn_alerts[%d].burn_rate must be positive", i)
+		}
+		if a.Severity != "page" && a.Severity != "ticket" {
+			return fmt.Errorf("slo.burn_alerts[%d].severity must be 'page' or 'ticket'", i)
+		}
+	}
 	return nil
 }
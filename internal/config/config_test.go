@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -28,6 +29,10 @@ func TestLoadWithDefaults(t *testing.T) {
 	if cfg.Monitoring.WarningThreshold != 0.7 {
 		t.Errorf("Expected default warning_threshold 0.7, got %.2f", cfg.Monitoring.WarningThreshold)
 	}
+
+	if cfg.Spool.Path != "./ripley.spool.jsonl" {
+		t.Errorf("Expected default spool path './ripley.spool.jsonl', got '%s'", cfg.Spool.Path)
+	}
 }
 
 func TestGetInterval(t *testing.T) {
@@ -106,6 +111,11 @@ monitoring:
 	if cfg.Monitoring.WarningThreshold != 0.8 {
 		t.Errorf("Expected warning_threshold 0.8, got %.2f", cfg.Monitoring.WarningThreshold)
 	}
+
+	// Optional sections fall back to defaults
+	if cfg.Spool.Path != "./ripley.spool.jsonl" {
+		t.Errorf("Expected default spool path, got '%s'", cfg.Spool.Path)
+	}
 }
 
 func TestLoadInvalidInterval(t *testing.T) {
@@ -294,3 +304,770 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestBackupConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+
+	interval, err := cfg.GetBackupInterval()
+	if err != nil || interval != 0 {
+		t.Errorf("Expected scheduled backups disabled by default, got %v (err %v)", interval, err)
+	}
+	if cfg.Backup.Dir != "./backups" || *cfg.Backup.Keep != 7 {
+		t.Errorf("Unexpected backup defaults: dir=%s keep=%d", cfg.Backup.Dir, *cfg.Backup.Keep)
+	}
+
+	cfg.Backup.Interval = "24h"
+	if interval, err := cfg.GetBackupInterval(); err != nil || interval != 24*time.Hour {
+		t.Errorf("Expected 24h, got %v (err %v)", interval, err)
+	}
+
+	cfg.Backup.Interval = "1d"
+	if interval, err := cfg.GetBackupInterval(); err != nil || interval != 24*time.Hour {
+		t.Errorf("Expected 1d to be 24h, got %v (err %v)", interval, err)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected 1d to be valid, got %v", err)
+	}
+
+	cfg.Backup.Interval = "daily"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for invalid backup interval, got nil")
+	}
+
+	cfg.Backup.Interval = "-1h"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative backup interval, got nil")
+	}
+
+	*cfg.Backup.Keep = -1
+	cfg.Backup.Interval = "24h"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative backup.keep, got nil")
+	}
+
+	*cfg.Backup.Keep = 7
+	for _, dsn := range []string{"postgres://ripley@localhost/ripley", "memory:"} {
+		cfg.Daemon.DBPath = dsn
+		if err := cfg.validate(); err == nil {
+			t.Errorf("Expected error for scheduled backups of %s, got nil", dsn)
+		}
+	}
+}
+
+// loadRequired loads a config file with the required settings followed by
+// extra.
+func loadRequired(t *testing.T, extra string) *Config {
+	t.Helper()
+	content := `
+daemon:
+  interval: "30m"
+  db_path: "./ripley.db"
+claude:
+  model: "Sonnet"
+monitoring:
+  rolling_window: 10
+  warning_threshold: 0.7
+` + extra
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestBackupConfigKeepEverything(t *testing.T) {
+	cfg := loadRequired(t, `
+backup:
+  keep: 0
+`)
+	if *cfg.Backup.Keep != 0 {
+		t.Errorf("Expected an explicit backup.keep of 0 to be kept, got %d", *cfg.Backup.Keep)
+	}
+}
+
+func TestRegressionConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if cfg.Regression.Window != 100 || *cfg.Regression.Confidence != 0.95 ||
+		cfg.Regression.MinSegment != 5 || cfg.Regression.Bootstraps != 1000 {
+		t.Errorf("Unexpected regression defaults: %+v", cfg.Regression)
+	}
+
+	*cfg.Regression.Confidence = 1.5
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for regression.confidence > 1, got nil")
+	}
+
+	cfg = loadRequired(t, `
+regression:
+  confidence: 0
+`)
+	if *cfg.Regression.Confidence != 0 {
+		t.Errorf("Expected an explicit regression.confidence of 0 to be kept, got %f", *cfg.Regression.Confidence)
+	}
+}
+
+func TestDriftConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if cfg.Drift.Window != 20 || cfg.Drift.History != 500 {
+		t.Errorf("Unexpected drift defaults: %+v", cfg.Drift)
+	}
+
+	cfg.Drift.Window = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative drift.window, got nil")
+	}
+}
+
+func TestIncidentsConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if *cfg.Incidents.ConsecutiveFailures != 3 || *cfg.Incidents.SuiteFailureRate != 0.5 {
+		t.Errorf("Unexpected incidents defaults: %+v", cfg.Incidents)
+	}
+
+	*cfg.Incidents.SuiteFailureRate = 1.5
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for incidents.suite_failure_rate > 1, got nil")
+	}
+
+	cfg = loadRequired(t, `
+incidents:
+  consecutive_failures: 0
+`)
+	if *cfg.Incidents.ConsecutiveFailures != 0 {
+		t.Errorf("Expected an explicit incidents.consecutive_failures of 0 to be kept, got %d", *cfg.Incidents.ConsecutiveFailures)
+	}
+
+	cfg = loadRequired(t, `
+incidents:
+  suite_failure_rate: 0
+`)
+	if *cfg.Incidents.SuiteFailureRate != 0 {
+		t.Errorf("Expected an explicit incidents.suite_failure_rate of 0 to be kept, got %f", *cfg.Incidents.SuiteFailureRate)
+	}
+}
+
+func TestCyclesConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if *cfg.Cycles.OutageShare != 1 {
+		t.Errorf("Expected cycles.outage_share to default to 1, got %f", *cfg.Cycles.OutageShare)
+	}
+
+	*cfg.Cycles.OutageShare = -0.5
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative cycles.outage_share, got nil")
+	}
+
+	cfg = loadRequired(t, `
+cycles:
+  outage_share: 0
+`)
+	if *cfg.Cycles.OutageShare != 0 {
+		t.Errorf("Expected an explicit cycles.outage_share of 0 to be kept, got %f", *cfg.Cycles.OutageShare)
+	}
+}
+
+func TestFlakyConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	f := cfg.Flaky
+	if f.Window != 30 || f.MinRuns != 10 || *f.Alternation != 0.3 || *f.Entropy != 0.7 || f.Quarantine {
+		t.Errorf("Unexpected flaky defaults: %+v", f)
+	}
+
+	*cfg.Flaky.Entropy = 2
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for flaky.entropy > 1, got nil")
+	}
+
+	cfg = loadRequired(t, `
+flaky:
+  alternation: 0
+  entropy: 0
+`)
+	if *cfg.Flaky.Alternation != 0 || *cfg.Flaky.Entropy != 0 {
+		t.Errorf("Expected explicit flaky thresholds of 0 to be kept, got alternation=%f entropy=%f", *cfg.Flaky.Alternation, *cfg.Flaky.Entropy)
+	}
+}
+
+func TestAlertsConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	a := cfg.Alerts
+	if a.Cooldown != "1h" || a.RepeatInterval != "24h" || a.FlapWindow != "6h" || *a.FlapThreshold != 4 {
+		t.Errorf("Unexpected alerts defaults: %+v", a)
+	}
+
+	cfg.Alerts.RepeatInterval = "0"
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected repeat_interval 0 to be valid, got %v", err)
+	}
+
+	cfg.Alerts.Cooldown = "soon"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for invalid alerts.cooldown, got nil")
+	}
+
+	cfg = LoadWithDefaults()
+	*cfg.Alerts.FlapThreshold = 1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for alerts.flap_threshold 1, got nil")
+	}
+}
+
+func TestAlertsConfigExplicitZero(t *testing.T) {
+	content := `
+daemon:
+  interval: "30m"
+  db_path: "./ripley.db"
+claude:
+  model: "Sonnet"
+monitoring:
+  rolling_window: 10
+  warning_threshold: 0.7
+alerts:
+  cooldown: 0
+  repeat_interval: 0
+  flap_threshold: 0
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	// Zero turns each off rather than falling back to the default
+	a := cfg.Alerts
+	if a.Cooldown != "0" || a.RepeatInterval != "0" || *a.FlapThreshold != 0 {
+		t.Errorf("Expected explicit zeros to be kept, got cooldown %q, repeat_interval %q, flap_threshold %d",
+			a.Cooldown, a.RepeatInterval, *a.FlapThreshold)
+	}
+}
+
+func TestAlertRulesConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if len(cfg.Alerts.Rules) != 1 {
+		t.Fatalf("Expected the default pass_rate rule, got %+v", cfg.Alerts.Rules)
+	}
+	r := cfg.Alerts.Rules[0]
+	if r.Name != "pass_rate" || r.Metric != "pass_rate" || r.Op != "<" || r.Threshold != 0.7 || r.Runs != 10 || r.MinRuns != 1 || r.Severity != "warning" {
+		t.Errorf("Unexpected default rule: %+v", r)
+	}
+
+	valid := AlertRule{Name: "slow", Metric: "p95_latency", Window: "6h", Op: ">", Threshold: 4, Severity: "critical"}
+	tests := []struct {
+		name   string
+		modify func(r *AlertRule)
+	}{
+		{"missing name", func(r *AlertRule) { r.Name = "" }},
+		{"reserved name", func(r *AlertRule) { r.Name = "slo/liveness" }},
+		{"unknown metric", func(r *AlertRule) { r.Metric = "vibes" }},
+		{"error class on another metric", func(r *AlertRule) { r.ErrorClass = "timeout" }},
+		{"unknown op", func(r *AlertRule) { r.Op = "=<" }},
+		{"unknown severity", func(r *AlertRule) { r.Severity = "page" }},
+		{"runs and window", func(r *AlertRule) { r.Runs = 10 }},
+		{"invalid window", func(r *AlertRule) { r.Window = "soon" }},
+	}
+
+	cfg.Alerts.Rules = []AlertRule{valid}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected the rule to be valid, got %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.modify(&r)
+			cfg.Alerts.Rules = []AlertRule{r}
+			if err := cfg.validate(); err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+		})
+	}
+
+	cfg.Alerts.Rules = []AlertRule{valid, valid}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a duplicate rule name, got nil")
+	}
+}
+
+func TestHooksConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if cfg.Hooks.MaxConcurrent != 4 {
+		t.Errorf("Expected max_concurrent 4, got %d", cfg.Hooks.MaxConcurrent)
+	}
+
+	valid := Hook{Name: "page", Command: "./page.sh", Events: []string{"alert_firing", "incident_opened"}, Timeout: "10s"}
+	tests := []struct {
+		name   string
+		modify func(h *Hook)
+	}{
+		{"missing name", func(h *Hook) { h.Name = "" }},
+		{"missing command", func(h *Hook) { h.Command = " " }},
+		{"unknown event", func(h *Hook) { h.Events = []string{"alert_fired"} }},
+		{"invalid timeout", func(h *Hook) { h.Timeout = "0s" }},
+	}
+
+	cfg.Hooks.Commands = []Hook{valid}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected the hook to be valid, got %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := valid
+			tt.modify(&h)
+			cfg.Hooks.Commands = []Hook{h}
+			if err := cfg.validate(); err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+		})
+	}
+
+	cfg.Hooks.Commands = []Hook{valid, valid}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a duplicate hook name, got nil")
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	tests := []struct {
+		listen    string
+		expectErr bool
+	}{
+		{"", false},
+		{":9120", false},
+		{"127.0.0.1:9120", false},
+		{"[::1]:9120", false},
+		{"9120", true},
+		{"localhost:", true},
+	}
+
+	for _, tt := range tests {
+		cfg := LoadWithDefaults()
+		cfg.Server.Listen = tt.listen
+		err := cfg.validate()
+		if tt.expectErr && err == nil {
+			t.Errorf("Expected error for listen %q, got nil", tt.listen)
+		}
+		if !tt.expectErr && err != nil {
+			t.Errorf("Expected no error for listen %q, got %v", tt.listen, err)
+		}
+	}
+}
+
+func TestHealthConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	hb := cfg.Health.Heartbeat
+	if cfg.Health.StaleAfter != "" || hb.URL != "" || hb.Method != "GET" || hb.Timeout != "10s" {
+		t.Errorf("Unexpected health defaults: %+v", cfg.Health)
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(c *Config)
+		expectErr bool
+	}{
+		{"heartbeat", func(c *Config) { c.Health.Heartbeat.URL = "https://hc-ping.com/abc" }, false},
+		{"fail url", func(c *Config) {
+			c.Health.Heartbeat.URL = "https://hc-ping.com/abc"
+			c.Health.Heartbeat.FailURL = "https://hc-ping.com/abc/fail"
+		}, false},
+		{"stale after in days", func(c *Config) { c.Health.StaleAfter = "1d" }, false},
+		{"invalid stale after", func(c *Config) { c.Health.StaleAfter = "0s" }, true},
+		{"invalid url", func(c *Config) { c.Health.Heartbeat.URL = "hc-ping.com/abc" }, true},
+		{"fail url alone", func(c *Config) { c.Health.Heartbeat.FailURL = "https://hc-ping.com/abc/fail" }, true},
+		{"invalid method", func(c *Config) { c.Health.Heartbeat.Method = "PUT" }, true},
+		{"invalid timeout", func(c *Config) { c.Health.Heartbeat.Timeout = "soon" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := LoadWithDefaults()
+			tt.modify(cfg)
+			err := cfg.validate()
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected no error for %s, got %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestLoggingConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	l := cfg.Logging
+	if l.Level != "info" || l.Format != "text" || l.File != "" || l.MaxSizeMB != 10 || *l.MaxBackups != 5 {
+		t.Errorf("Unexpected logging defaults: %+v", l)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"unknown level", func(c *Config) { c.Logging.Level = "verbose" }},
+		{"unknown format", func(c *Config) { c.Logging.Format = "xml" }},
+		{"negative size", func(c *Config) { c.Logging.MaxSizeMB = -1 }},
+		{"negative backups", func(c *Config) { *c.Logging.MaxBackups = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := LoadWithDefaults()
+			tt.modify(cfg)
+			if err := cfg.validate(); err == nil {
+				t.Errorf("Expected error for %s, got nil", tt.name)
+			}
+		})
+	}
+
+	cfg = loadRequired(t, `
+logging:
+  max_backups: 0
+`)
+	if *cfg.Logging.MaxBackups != 0 {
+		t.Errorf("Expected an explicit logging.max_backups of 0 to be kept, got %d", *cfg.Logging.MaxBackups)
+	}
+}
+
+func TestMetricsConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if cfg.Metrics.Textfile != "" || cfg.Metrics.Format != "prometheus" {
+		t.Errorf("Expected no textfile in the prometheus format, got %+v", cfg.Metrics)
+	}
+
+	cfg.Metrics.Format = "openmetrics"
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected openmetrics to be valid, got %v", err)
+	}
+	cfg.Metrics.Format = "json"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for metrics.format json, got nil")
+	}
+}
+
+func TestEffortConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	w := cfg.Effort.Weights
+	if w.Correctness != 0.3 || w.Tokens != 0.3 || w.Latency != 0.25 || w.Laziness != 0.1 || w.Consistency != 0.05 {
+		t.Errorf("Unexpected effort weight defaults: %+v", w)
+	}
+	if *cfg.Effort.Good != 80 || *cfg.Effort.Medium != 50 {
+		t.Errorf("Unexpected effort thresholds: good=%.0f medium=%.0f", *cfg.Effort.Good, *cfg.Effort.Medium)
+	}
+
+	medium := 90.0
+	cfg.Effort.Medium = &medium
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for effort.medium above effort.good, got nil")
+	}
+
+	cfg = LoadWithDefaults()
+	cfg.Effort.Weights.Tokens = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative effort weight, got nil")
+	}
+
+	// An unset medium is capped at a lowered good
+	cfg = loadRequired(t, `
+effort:
+  good: 40
+`)
+	if *cfg.Effort.Good != 40 || *cfg.Effort.Medium != 40 {
+		t.Errorf("Expected good=40 medium=40, got good=%.0f medium=%.0f", *cfg.Effort.Good, *cfg.Effort.Medium)
+	}
+
+	cfg = loadRequired(t, `
+effort:
+  medium: 0
+`)
+	if *cfg.Effort.Good != 80 || *cfg.Effort.Medium != 0 {
+		t.Errorf("Expected an explicit effort.medium of 0 to be kept, got good=%.0f medium=%.0f", *cfg.Effort.Good, *cfg.Effort.Medium)
+	}
+}
+
+func TestAnalysisConfig(t *testing.T) {
+	cfg := LoadWithDefaults()
+	if loc, err := cfg.GetLocation(); err != nil || loc != time.Local {
+		t.Errorf("Expected local time by default, got %v (err %v)", loc, err)
+	}
+
+	cfg.Analysis.Timezone = "America/New_York"
+	if loc, err := cfg.GetLocation(); err != nil || loc.String() != "America/New_York" {
+		t.Errorf("Expected America/New_York, got %v (err %v)", loc, err)
+	}
+
+	cfg.Analysis.Timezone = "Mars/Olympus_Mons"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for unknown timezone, got nil")
+	}
+}
+
+func TestPricingConfig(t *testing.T) {
+	content := `
+daemon:
+  interval: "30m"
+  db_path: "./ripley.db"
+claude:
+  model: "Sonnet"
+monitoring:
+  rolling_window: 10
+  warning_threshold: 0.7
+pricing:
+  Sonnet: 15
+  Haiku: 4
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Pricing["Sonnet"] != 15 || cfg.Pricing["Haiku"] != 4 || len(cfg.Pricing) != 2 {
+		t.Errorf("Unexpected pricing: %v", cfg.Pricing)
+	}
+
+	cfg.Pricing["Opus"] = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative price, got nil")
+	}
+}
+
+func TestSLOConfig(t *testing.T) {
+	content := `
+daemon:
+  interval: "30m"
+  db_path: "./ripley.db"
+claude:
+  model: "Sonnet"
+monitoring:
+  rolling_window: 10
+  warning_threshold: 0.7
+slo:
+  objectives:
+    - name: liveness
+      target: 0.99
+    - name: fast-sums
+      benchmark: Sum1to100
+      target: 0.95
+      window: 7d
+      latency: 4s
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	objectives := cfg.SLO.Objectives
+	if len(objectives) != 2 || objectives[0].Window != "30d" || objectives[1].Latency != "4s" {
+		t.Errorf("Unexpected objectives: %+v", objectives)
+	}
+	if len(cfg.SLO.BurnAlerts) != 3 || cfg.SLO.BurnAlerts[0].BurnRate != 14.4 {
+		t.Errorf("Unexpected default burn alerts: %+v", cfg.SLO.BurnAlerts)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"duplicate name", func(c *Config) { c.SLO.Objectives[1].Name = "liveness" }},
+		{"target of 1", func(c *Config) { c.SLO.Objectives[0].Target = 1 }},
+		{"invalid window", func(c *Config) { c.SLO.Objectives[0].Window = "a month" }},
+		{"invalid latency", func(c *Config) { c.SLO.Objectives[1].Latency = "fast" }},
+		{"short longer than long", func(c *Config) { c.SLO.BurnAlerts[0].Short = "2h" }},
+		{"unknown severity", func(c *Config) { c.SLO.BurnAlerts[0].Severity = "email" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := Load(tmpfile.Name())
+			tt.modify(cfg)
+			if err := cfg.validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+	cfg = loadRequired(t, `
+slo:
+  burn_alerts: []
+`)
+	if cfg.SLO.BurnAlerts == nil || len(cfg.SLO.BurnAlerts) != 0 {
+		t.Errorf("Expected an explicit empty burn_alerts to turn them off, got %+v", cfg.SLO.BurnAlerts)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{"6h", 6 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"d", 0, true},
+		{"3dd", 0, true},
+		{"2wd", 0, true},
+		{"1dw", 0, true},
+		{"1.5d", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for %q, got %v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("Expected %v for %q, got %v (err %v)", tt.expected, tt.input, got, err)
+		}
+	}
+}
+
+func TestNotifyConfig(t *testing.T) {
+	content := `
+daemon:
+  interval: "30m"
+  db_path: "./ripley.db"
+claude:
+  model: "Sonnet"
+monitoring:
+  rolling_window: 10
+  warning_threshold: 0.7
+notify:
+  webhooks:
+    - name: ops
+      url: https://hooks.example.com/ripley
+      secret: s3cret
+    - name: local
+      url: http://localhost:9000/events
+      timeout: 2s
+      attempts: 1
+  slack:
+    - name: alerts
+      url: https://hooks.slack.com/services/T000/B000/XXXX
+      min_severity: warning
+  discord:
+    - name: incidents
+      url: https://discord.com/api/webhooks/1/abc
+      tags: [incident]
+  email:
+    - name: team
+      host: smtp.example.com
+      username: ripley
+      password: s3cret
+      from: Ripley <ripley@example.com>
+      to: [ops@example.com]
+      digest: weekly
+`
+	tmpfile, err := os.CreateTemp("", "config-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	webhooks := cfg.Notify.Webhooks
+	if len(webhooks) != 2 {
+		t.Fatalf("Expected 2 webhooks, got %d", len(webhooks))
+	}
+	if webhooks[0].Timeout != "10s" || webhooks[0].Attempts != 3 || webhooks[0].Backoff != "1s" {
+		t.Errorf("Expected default timeout, attempts and backoff, got %+v", webhooks[0])
+	}
+	if webhooks[1].Timeout != "2s" || webhooks[1].Attempts != 1 {
+		t.Errorf("Expected configured timeout and attempts, got %+v", webhooks[1])
+	}
+	if len(cfg.Notify.Slack) != 1 || cfg.Notify.Slack[0].MinSeverity != "warning" || cfg.Notify.Slack[0].Attempts != 3 {
+		t.Errorf("Unexpected Slack destinations: %+v", cfg.Notify.Slack)
+	}
+	if len(cfg.Notify.Discord) != 1 || len(cfg.Notify.Discord[0].Tags) != 1 || cfg.Notify.Discord[0].Timeout != "10s" {
+		t.Errorf("Unexpected Discord destinations: %+v", cfg.Notify.Discord)
+	}
+	email := cfg.Notify.Email[0]
+	if email.TLS != "starttls" || email.Port != 587 || email.DigestAt != "08:00" || email.DigestDay != "monday" {
+		t.Errorf("Expected default TLS, port and digest schedule, got %+v", email)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{"missing name", func(c *Config) { c.Notify.Webhooks[0].Name = "" }},
+		{"duplicate name", func(c *Config) { c.Notify.Webhooks[1].Name = "ops" }},
+		{"relative url", func(c *Config) { c.Notify.Webhooks[0].URL = "/ripley" }},
+		{"unsupported scheme", func(c *Config) { c.Notify.Webhooks[0].URL = "ftp://example.com" }},
+		{"invalid timeout", func(c *Config) { c.Notify.Webhooks[0].Timeout = "soon" }},
+		{"negative attempts", func(c *Config) { c.Notify.Webhooks[0].Attempts = -1 }},
+		{"invalid backoff", func(c *Config) { c.Notify.Webhooks[0].Backoff = "later" }},
+		{"name shared across kinds", func(c *Config) { c.Notify.Discord[0].Name = "alerts" }},
+		{"unknown severity", func(c *Config) { c.Notify.Slack[0].MinSeverity = "urgent" }},
+		{"invalid Discord url", func(c *Config) { c.Notify.Discord[0].URL = "discord" }},
+		{"email name shared", func(c *Config) { c.Notify.Email[0].Name = "ops" }},
+		{"email without host", func(c *Config) { c.Notify.Email[0].Host = "" }},
+		{"unknown tls mode", func(c *Config) { c.Notify.Email[0].TLS = "ssl" }},
+		{"invalid sender", func(c *Config) { c.Notify.Email[0].From = "ripley" }},
+		{"no recipients", func(c *Config) { c.Notify.Email[0].To = nil }},
+		{"invalid recipient", func(c *Config) { c.Notify.Email[0].To = []string{"ops at example"} }},
+		{"unknown digest period", func(c *Config) { c.Notify.Email[0].Digest = "monthly" }},
+		{"invalid digest time", func(c *Config) { c.Notify.Email[0].DigestAt = "8am" }},
+		{"invalid digest day", func(c *Config) { c.Notify.Email[0].DigestDay = "someday" }},
+		{"digest only without digest", func(c *Config) { c.Notify.Email[0].Digest, c.Notify.Email[0].DigestOnly = "", true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := Load(tmpfile.Name())
+			tt.modify(cfg)
+			if err := cfg.validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
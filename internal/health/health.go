@@ -0,0 +1,218 @@
+// Package health reports whether the daemon itself is working: /healthz fails
+// when the scheduler stops finishing cycles, /readyz while the database is
+// unreachable or no cycle has succeeded yet. A heartbeat pinged after every
+// cycle lets an outside watchdog notice when the daemon goes silent.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Scheduler states
+const (
+	StateStarting = "starting" // No cycle started yet
+	StateRunning  = "running"  // A cycle is in progress
+	StateSleeping = "sleeping" // Waiting for the next cycle
+)
+
+// pingTimeout bounds the database check of a request.
+const pingTimeout = 5 * time.Second
+
+// Monitor follows the cycles of the daemon and answers health checks.
+type Monitor struct {
+	DB         *storage.Spool
+	Interval   time.Duration
+	StaleAfter time.Duration // No cycle finished for this long is unhealthy
+	Now        func() time.Time
+
+	mu            sync.Mutex
+	started       time.Time
+	state         string
+	cycles        int
+	cycleStarted  time.Time
+	cycleFinished time.Time
+	lastSuccess   time.Time
+	lastRunID     string
+	lastFailure   string
+}
+
+// NewMonitor returns a monitor of a daemon, started now, that runs a cycle
+// every interval and writes results through db.
+func NewMonitor(cfg *config.Config, db *storage.Spool, interval time.Duration) (*Monitor, error) {
+	staleAfter := 3 * interval
+	if cfg.Health.StaleAfter != "" {
+		var err error
+		if staleAfter, err = config.ParseDuration(cfg.Health.StaleAfter); err != nil {
+			return nil, fmt.Errorf("invalid health.stale_after: %w", err)
+		}
+	}
+	return &Monitor{DB: db, Interval: interval, StaleAfter: staleAfter, Now: time.Now, started: time.Now(), state: StateStarting}, nil
+}
+
+// CycleStarted records the start of a cycle.
+func (m *Monitor) CycleStarted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state, m.cycleStarted = StateRunning, m.Now()
+}
+
+// CycleFinished records the end of a cycle. A cycle fails, with the reason
+// given, when it produced no results or could not store them.
+func (m *Monitor) CycleFinished(runID string, failure string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.Now()
+	m.state, m.cycleFinished = StateSleeping, now
+	m.cycles++
+	m.lastRunID, m.lastFailure = runID, failure
+	if failure == "" {
+		m.lastSuccess = now
+	}
+}
+
+// Report is the body of /healthz and /readyz.
+type Report struct {
+	Status              string     `json:"status"`             // "ok" or "fail"
+	Problems            []string   `json:"problems,omitempty"` // Why the check fails
+	StartedAt           time.Time  `json:"started_at"`
+	LastSuccessfulCycle *time.Time `json:"last_successful_cycle,omitempty"`
+	LastRunID           string     `json:"last_run_id,omitempty"`
+	LastFailure         string     `json:"last_failure,omitempty"` // Why the last cycle failed
+	Scheduler           Scheduler  `json:"scheduler"`
+	Database            Database   `json:"database"`
+}
+
+// Scheduler is the state of the cycle loop.
+type Scheduler struct {
+	State           string     `json:"state"`
+	Cycles          int        `json:"cycles"` // Finished since the daemon started
+	Interval        string     `json:"interval"`
+	StaleAfter      string     `json:"stale_after"`
+	CycleStartedAt  *time.Time `json:"cycle_started_at,omitempty"` // While running
+	LastCycleFinish *time.Time `json:"last_cycle_finished_at,omitempty"`
+	NextCycleAt     *time.Time `json:"next_cycle_at,omitempty"` // While sleeping
+}
+
+// Database is the state of the store.
+type Database struct {
+	OK            bool   `json:"ok"`
+	Error         string `json:"error,omitempty"`
+	SpoolPending  int    `json:"spool_pending"`
+	WriteFailures int    `json:"write_failures"`
+}
+
+// Health reports whether the daemon is alive: a cycle has finished, or the
+// daemon started, within StaleAfter. A hung cycle or scheduler fails it.
+func (m *Monitor) Health(ctx context.Context) Report {
+	r := m.report(ctx)
+	m.mu.Lock()
+	last, format := m.cycleFinished, "the last cycle finished %s ago, more than stale_after %s"
+	if last.IsZero() {
+		last, format = m.started, "no cycle has finished in the %s since the daemon started, more than stale_after %s"
+	}
+	m.mu.Unlock()
+
+	if age := m.Now().Sub(last).Round(time.Second); age > m.StaleAfter {
+		r.Problems = append(r.Problems, fmt.Sprintf(format, analysis.FormatWindow(age), analysis.FormatWindow(m.StaleAfter)))
+	}
+	return r.finish()
+}
+
+// Ready reports whether the daemon is doing useful work: the database can be
+// read, written results are not waiting in the spool and a cycle has
+// succeeded.
+func (m *Monitor) Ready(ctx context.Context) Report {
+	r := m.report(ctx)
+	if !r.Database.OK {
+		r.Problems = append(r.Problems, "database unreachable: "+r.Database.Error)
+	}
+	if r.Database.SpoolPending > 0 {
+		r.Problems = append(r.Problems, fmt.Sprintf("%d results spooled, waiting for the database", r.Database.SpoolPending))
+	}
+	if r.LastSuccessfulCycle == nil {
+		r.Problems = append(r.Problems, "no cycle has succeeded yet")
+	}
+	return r.finish()
+}
+
+func (r Report) finish() Report {
+	r.Status = "ok"
+	if len(r.Problems) > 0 {
+		r.Status = "fail"
+	}
+	return r
+}
+
+func (m *Monitor) report(ctx context.Context) Report {
+	m.mu.Lock()
+	r := Report{
+		StartedAt:   m.started,
+		LastRunID:   m.lastRunID,
+		LastFailure: m.lastFailure,
+		Scheduler: Scheduler{
+			State:      m.state,
+			Cycles:     m.cycles,
+			Interval:   m.Interval.String(),
+			StaleAfter: m.StaleAfter.String(),
+		},
+	}
+	if !m.lastSuccess.IsZero() {
+		r.LastSuccessfulCycle = timePtr(m.lastSuccess)
+	}
+	if !m.cycleFinished.IsZero() {
+		r.Scheduler.LastCycleFinish = timePtr(m.cycleFinished)
+	}
+	switch m.state {
+	case StateRunning:
+		r.Scheduler.CycleStartedAt = timePtr(m.cycleStarted)
+	case StateSleeping:
+		r.Scheduler.NextCycleAt = timePtr(m.cycleFinished.Add(m.Interval))
+	}
+	m.mu.Unlock()
+
+	// Outside the lock: the database may be slow to answer
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	r.Database = Database{OK: true, SpoolPending: m.DB.Pending(), WriteFailures: m.DB.Failures()}
+	if err := m.DB.Ping(ctx); err != nil {
+		r.Database.OK, r.Database.Error = false, err.Error()
+	}
+	return r
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// HealthHandler serves Health, with status 503 while it fails.
+func (m *Monitor) HealthHandler() http.Handler {
+	return handler(m.Health)
+}
+
+// ReadyHandler serves Ready, with status 503 while it fails.
+func (m *Monitor) ReadyHandler() http.Handler {
+	return handler(m.Ready)
+}
+
+func handler(check func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r := check(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(r)
+	})
+}
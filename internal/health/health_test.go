@@ -0,0 +1,208 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// downStore is a MemoryStore that cannot be reached.
+type downStore struct {
+	*storage.MemoryStore
+}
+
+func (downStore) Ping(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+var start = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// testMonitor returns a monitor over store whose clock is at *now.
+func testMonitor(t *testing.T, store storage.Store, now *time.Time) *Monitor {
+	t.Helper()
+	spool, err := storage.NewSpool(store, filepath.Join(t.TempDir(), "spool.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	m, err := NewMonitor(config.LoadWithDefaults(), spool, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("NewMonitor failed: %v", err)
+	}
+	m.Now = func() time.Time { return *now }
+	m.started = start
+	return m
+}
+
+func TestHealth(t *testing.T) {
+	now := start
+	m := testMonitor(t, storage.NewMemory(), &now)
+	if m.StaleAfter != 90*time.Minute {
+		t.Errorf("Expected stale_after to default to three intervals, got %v", m.StaleAfter)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		event   string // "start" or "finish" a cycle after advancing
+		state   string
+		ok      bool
+	}{
+		{"just started", time.Minute, "", StateStarting, true},
+		{"first cycle running", 0, "start", StateRunning, true},
+		{"first cycle finished", 5 * time.Minute, "finish", StateSleeping, true},
+		{"sleeping", 30 * time.Minute, "", StateSleeping, true},
+		{"next cycle hung", 0, "start", StateRunning, true},
+		{"hung past stale_after", 61 * time.Minute, "", StateRunning, false},
+		{"recovered", time.Minute, "finish", StateSleeping, true},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		switch tt.event {
+		case "start":
+			m.CycleStarted()
+		case "finish":
+			m.CycleFinished("run-01", "")
+		}
+		r := m.Health(context.Background())
+		if r.Scheduler.State != tt.state || (r.Status == "ok") != tt.ok {
+			t.Errorf("%s: expected state %s and ok %v, got %+v", tt.name, tt.state, tt.ok, r)
+		}
+	}
+
+	// A daemon that never finishes its first cycle is unhealthy too
+	now = start.Add(2 * time.Hour)
+	m = testMonitor(t, storage.NewMemory(), &now)
+	m.CycleStarted()
+	r := m.Health(context.Background())
+	if r.Status != "fail" || len(r.Problems) != 1 || !strings.Contains(r.Problems[0], "since the daemon started") {
+		t.Errorf("Expected a stale first cycle, got %+v", r)
+	}
+}
+
+func TestReady(t *testing.T) {
+	now := start
+	m := testMonitor(t, storage.NewMemory(), &now)
+
+	r := m.Ready(context.Background())
+	if r.Status != "fail" || len(r.Problems) != 1 || r.Problems[0] != "no cycle has succeeded yet" {
+		t.Errorf("Expected not ready before the first cycle, got %+v", r)
+	}
+
+	m.CycleStarted()
+	m.CycleFinished("run-01", "no results were stored")
+	if r := m.Ready(context.Background()); r.Status != "fail" || r.LastFailure != "no results were stored" {
+		t.Errorf("Expected not ready after a failed cycle, got %+v", r)
+	}
+
+	now = now.Add(30 * time.Minute)
+	m.CycleStarted()
+	m.CycleFinished("run-02", "")
+	r = m.Ready(context.Background())
+	if r.Status != "ok" || r.LastRunID != "run-02" || r.LastSuccessfulCycle == nil || !r.LastSuccessfulCycle.Equal(now) {
+		t.Errorf("Expected ready after a successful cycle, got %+v", r)
+	}
+	if r.Scheduler.Cycles != 2 || r.Scheduler.NextCycleAt == nil || !r.Scheduler.NextCycleAt.Equal(now.Add(30*time.Minute)) {
+		t.Errorf("Unexpected scheduler: %+v", r.Scheduler)
+	}
+
+	m = testMonitor(t, downStore{storage.NewMemory()}, &now)
+	m.CycleFinished("run-01", "")
+	r = m.Ready(context.Background())
+	if r.Status != "fail" || r.Database.OK || r.Database.Error != "connection refused" {
+		t.Errorf("Expected not ready with the database down, got %+v", r)
+	}
+	if h := m.Health(context.Background()); h.Status != "ok" {
+		t.Errorf("Expected the database to leave liveness alone, got %+v", h)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	now := start
+	m := testMonitor(t, storage.NewMemory(), &now)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		status  int
+	}{
+		{"healthz", m.HealthHandler(), http.StatusOK},
+		{"readyz", m.ReadyHandler(), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/"+tt.name, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+		}
+		var r Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+			t.Errorf("%s: expected a JSON report, got %q", tt.name, rec.Body.String())
+		}
+		if r.Scheduler.State != StateStarting || r.Scheduler.StaleAfter != "1h30m0s" {
+			t.Errorf("%s: unexpected report %+v", tt.name, r)
+		}
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	type ping struct {
+		method, path, body string
+	}
+	var pings []ping
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pings = append(pings, ping{r.Method, r.URL.Path, string(body)})
+		if r.URL.Path == "/broken" {
+			http.Error(w, "no such check", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.LoadWithDefaults()
+	if hb, err := NewHeartbeat(cfg); hb != nil || err != nil {
+		t.Fatalf("Expected no heartbeat without a url, got %v, %v", hb, err)
+	}
+
+	cfg.Health.Heartbeat.URL = server.URL + "/check"
+	hb, err := NewHeartbeat(cfg)
+	if err != nil {
+		t.Fatalf("NewHeartbeat failed: %v", err)
+	}
+	ok := Beat{RunID: "run-01", OK: true, Total: 4}
+	failed := Beat{RunID: "run-02", Failure: "no results were stored"}
+
+	// Without a fail url, failed cycles skip the ping
+	if err := hb.Ping(context.Background(), ok); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	if err := hb.Ping(context.Background(), failed); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	if len(pings) != 1 || pings[0] != (ping{"GET", "/check", ""}) {
+		t.Errorf("Expected one GET ping, got %+v", pings)
+	}
+
+	pings = nil
+	hb.Method, hb.FailURL = http.MethodPost, server.URL+"/check/fail"
+	hb.Ping(context.Background(), failed)
+	if len(pings) != 1 || pings[0].path != "/check/fail" || !strings.Contains(pings[0].body, `"failure":"no results were stored"`) {
+		t.Errorf("Expected a POST to the fail url, got %+v", pings)
+	}
+
+	hb.URL = server.URL + "/broken"
+	if err := hb.Ping(context.Background(), ok); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected the 404 to be reported, got %v", err)
+	}
+}
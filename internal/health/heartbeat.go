@@ -0,0 +1,93 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// Heartbeat pings a dead man's switch, e.g. a healthchecks.io check, after
+// every cycle, so the watchdog alerts when the pings stop. Pings are not
+// retried: the next cycle pings again.
+type Heartbeat struct {
+	URL     string // Pinged after successful cycles
+	FailURL string // Pinged after failed cycles; empty skips them
+	Method  string // GET, HEAD or POST
+	Client  *http.Client
+}
+
+// Beat is the body of POST pings.
+type Beat struct {
+	RunID    string    `json:"run_id,omitempty"`
+	Time     time.Time `json:"time"`
+	OK       bool      `json:"ok"`
+	Failure  string    `json:"failure,omitempty"` // Why the cycle failed
+	Label    string    `json:"label,omitempty"`   // Of the cycle; empty if unclassified
+	Total    int       `json:"total"`
+	Failed   int       `json:"failed"`
+	Duration float64   `json:"duration_seconds"`
+}
+
+// NewHeartbeat returns the heartbeat configured in cfg, or nil if none is.
+func NewHeartbeat(cfg *config.Config) (*Heartbeat, error) {
+	hb := cfg.Health.Heartbeat
+	if hb.URL == "" {
+		return nil, nil
+	}
+	timeout, err := time.ParseDuration(hb.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health.heartbeat.timeout: %w", err)
+	}
+	return &Heartbeat{URL: hb.URL, FailURL: hb.FailURL, Method: hb.Method, Client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Ping reports b to the URL for its outcome.
+func (h *Heartbeat) Ping(ctx context.Context, b Beat) error {
+	url := h.URL
+	if !b.OK {
+		if h.FailURL == "" {
+			return nil
+		}
+		url = h.FailURL
+	}
+
+	var body io.Reader
+	if h.Method == http.MethodPost {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("failed to encode heartbeat: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, h.Method, url, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "ripley")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return fmt.Errorf("heartbeat: unexpected status %s: %s", resp.Status, bytes.TrimSpace(snippet))
+}
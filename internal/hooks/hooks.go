@@ -0,0 +1,265 @@
+// Package hooks runs shell commands configured under hooks when a cycle
+// completes, alerts fire or resolve and incidents open or close. Each command
+// gets the event as JSON on stdin and its main fields as RIPLEY_*
+// environment variables.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// EventCycle is the hook event of a completed cycle; the others are derived
+// from notification events by EventName.
+const EventCycle = "cycle"
+
+// maxOutput is how much of a failed command's output is logged.
+const maxOutput = 500
+
+// Hook is a command run on some events.
+type Hook struct {
+	Name    string
+	Command string   // Run by /bin/sh -c
+	Events  []string // Empty for every event
+	Timeout time.Duration
+	Env     []string // "KEY=value", added to the daemon's environment
+}
+
+// Runs reports whether h runs on event.
+func (h Hook) Runs(event string) bool {
+	return len(h.Events) == 0 || slices.Contains(h.Events, event)
+}
+
+// Result is the outcome of running a hook once.
+type Result struct {
+	ExitCode int // -1 if the command did not exit by itself
+	Duration time.Duration
+	Output   string // Combined stdout and stderr
+	Err      error  // Why the command failed, nil on exit status 0
+}
+
+// Runner runs hooks in the background, at most hooks.max_concurrent at a
+// time. It is a notify.Notifier, so it can be added to the notifiers of the
+// daemon.
+type Runner struct {
+	Hooks []Hook
+	sem   chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewRunner returns a runner for the hooks in cfg.
+func NewRunner(cfg *config.Config) (*Runner, error) {
+	r := &Runner{sem: make(chan struct{}, max(cfg.Hooks.MaxConcurrent, 1))}
+	for _, h := range cfg.Hooks.Commands {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for hook %s: %w", h.Name, err)
+		}
+		var env []string
+		for k, v := range h.Env {
+			env = append(env, k+"="+v)
+		}
+		slices.Sort(env)
+		r.Hooks = append(r.Hooks, Hook{Name: h.Name, Command: h.Command, Events: h.Events, Timeout: timeout, Env: env})
+	}
+	return r, nil
+}
+
+// EventName returns the hook event that e triggers, e.g. "alert_firing", or
+// "" for none.
+func EventName(e notify.Event) string {
+	switch {
+	case e.Alert != nil:
+		return "alert_" + e.Alert.State
+	case e.Kind == notify.KindIncidentOpened, e.Kind == notify.KindIncidentResolved:
+		return e.Kind
+	}
+	return ""
+}
+
+// Notify starts the hooks that run on e and returns without waiting for
+// them; their exit status is logged.
+func (r *Runner) Notify(ctx context.Context, e notify.Event) error {
+	event := EventName(e)
+	if event == "" {
+		return nil
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	r.start(event, payload, EventEnv(event, e))
+	return nil
+}
+
+// EventEnv returns the RIPLEY_* environment variables of hooks run on e as
+// event.
+func EventEnv(event string, e notify.Event) []string {
+	env := []string{
+		"RIPLEY_EVENT=" + event,
+		"RIPLEY_KIND=" + e.Kind,
+		"RIPLEY_SEVERITY=" + e.Severity,
+		"RIPLEY_SUMMARY=" + e.Summary,
+		"RIPLEY_BENCHMARK=" + e.Benchmark,
+	}
+	if e.Alert != nil {
+		env = append(env, "RIPLEY_ALERT_RULE="+e.Alert.Rule)
+	}
+	if e.Incident != nil {
+		env = append(env, "RIPLEY_INCIDENT_ID="+strconv.FormatInt(e.Incident.ID, 10))
+	}
+	return env
+}
+
+// Cycle starts the hooks that run after a cycle.
+func (r *Runner) Cycle(c Cycle) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		slog.Error("Failed to encode cycle for hooks", "run_id", c.RunID, "error", err)
+		return
+	}
+	r.start(EventCycle, payload, []string{
+		"RIPLEY_EVENT=" + EventCycle,
+		"RIPLEY_RUN_ID=" + c.RunID,
+		"RIPLEY_CYCLE_LABEL=" + c.Label,
+		"RIPLEY_TOTAL=" + strconv.Itoa(c.Total),
+		"RIPLEY_FAILED=" + strconv.Itoa(c.Failed),
+	})
+}
+
+// Wait blocks until every hook started has finished.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) start(event string, payload []byte, env []string) {
+	for _, h := range r.Hooks {
+		if !h.Runs(event) {
+			continue
+		}
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.sem <- struct{}{}
+			defer func() { <-r.sem }()
+
+			res := Run(context.Background(), h, payload, env)
+			attrs := []any{"hook", h.Name, "event", event, "exit_code", res.ExitCode, "duration_seconds", res.Duration.Seconds()}
+			switch {
+			case res.Err == nil:
+				slog.Info("Hook finished", attrs...)
+			case res.ExitCode >= 0:
+				slog.Warn("Hook failed", append(attrs, "output", tail(res.Output))...)
+			default:
+				slog.Warn("Hook failed", append(attrs, "error", res.Err)...)
+			}
+		}()
+	}
+}
+
+// Run runs h with payload on stdin and env added to its environment, and
+// waits for it to exit or time out.
+func Run(ctx context.Context, h Hook, payload []byte, env []string) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = slices.Concat(os.Environ(), env, h.Env)
+	// Do not wait forever for children that keep the output open
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	res := Result{ExitCode: -1, Duration: time.Since(start), Output: output.String(), Err: err}
+	if ctx.Err() == context.DeadlineExceeded {
+		res.Err = fmt.Errorf("timed out after %s", h.Timeout)
+		return res
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		res.ExitCode = 0
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		res.ExitCode = exitErr.ExitCode()
+	}
+	return res
+}
+
+// tail returns the end of a command's output, on one line.
+func tail(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxOutput {
+		output = "…" + output[len(output)-maxOutput:]
+	}
+	if output == "" {
+		return "no output"
+	}
+	return strings.ReplaceAll(output, "\n", " | ")
+}
+
+// Cycle is the payload of hooks run after a cycle.
+type Cycle struct {
+	Kind    string            `json:"kind"` // Always "cycle"
+	RunID   string            `json:"run_id"`
+	Time    time.Time         `json:"time"`
+	Model   string            `json:"model,omitempty"`
+	Label   string            `json:"label,omitempty"` // healthy, outage, partial or quality; empty if unclassified
+	Reason  string            `json:"reason,omitempty"`
+	Total   int               `json:"total"`
+	Failed  int               `json:"failed"`
+	Results []BenchmarkResult `json:"results"`
+}
+
+// BenchmarkResult is one result of a cycle.
+type BenchmarkResult struct {
+	Benchmark   string  `json:"benchmark"`
+	Passed      bool    `json:"passed"`
+	Tokens      int     `json:"tokens"`
+	Duration    float64 `json:"duration_seconds"`
+	EffortScore float64 `json:"effort_score"`
+	Effort      string  `json:"effort,omitempty"`
+	ErrorClass  string  `json:"error_class,omitempty"`
+}
+
+// NewCycle returns the payload of the cycle that produced results, labeled
+// by c; c is the zero Cycle if the cycle was not classified.
+func NewCycle(results []checker.Result, c storage.Cycle, now time.Time) Cycle {
+	cycle := Cycle{Kind: EventCycle, Time: now, Label: c.Label, Reason: c.Reason, Total: len(results), Results: []BenchmarkResult{}}
+	for _, r := range results {
+		cycle.RunID, cycle.Model = r.RunID, r.Model
+		if !r.Passed {
+			cycle.Failed++
+		}
+		cycle.Results = append(cycle.Results, BenchmarkResult{
+			Benchmark:   r.Name,
+			Passed:      r.Passed,
+			Tokens:      r.TokensUsed,
+			Duration:    r.Duration.Seconds(),
+			EffortScore: r.EffortScore,
+			Effort:      r.Effort,
+			ErrorClass:  r.ErrorClass,
+		})
+	}
+	return cycle
+}
@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/notify"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		exitCode int
+		output   string
+		failed   bool
+	}{
+		{"reads stdin", `cat`, 0, `{"kind":"test"}`, false},
+		{"gets env", `echo "$RIPLEY_EVENT $HOOK_VAR"`, 0, "test configured\n", false},
+		{"exit status", `echo broken >&2; exit 3`, 3, "broken\n", true},
+		{"timeout", `sleep 5`, -1, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Hook{Name: "h", Command: tt.command, Timeout: 200 * time.Millisecond, Env: []string{"HOOK_VAR=configured"}}
+			res := Run(context.Background(), h, []byte(`{"kind":"test"}`), []string{"RIPLEY_EVENT=test"})
+			if res.ExitCode != tt.exitCode || res.Output != tt.output || (res.Err != nil) != tt.failed {
+				t.Errorf("Expected exit %d with output %q (failed %v), got %+v", tt.exitCode, tt.output, tt.failed, res)
+			}
+		})
+	}
+}
+
+func TestEventName(t *testing.T) {
+	tests := []struct {
+		event    notify.Event
+		expected string
+	}{
+		{notify.Event{Kind: notify.KindDegraded, Alert: &notify.Alert{State: "firing"}}, "alert_firing"},
+		{notify.Event{Kind: notify.KindAlertResolved, Alert: &notify.Alert{State: "resolved"}}, "alert_resolved"},
+		{notify.Event{Kind: notify.KindIncidentOpened}, "incident_opened"},
+		{notify.Event{Kind: notify.KindIncidentResolved}, "incident_resolved"},
+		{notify.Event{Kind: notify.KindDegraded}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := EventName(tt.event); got != tt.expected {
+			t.Errorf("Expected %q for %s, got %q", tt.expected, tt.event.Kind, got)
+		}
+		if tt.expected != "" && !slices.Contains(config.HookEvents, tt.expected) {
+			t.Errorf("Expected %q to be a configurable hook event", tt.expected)
+		}
+	}
+}
+
+func TestRunner(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.LoadWithDefaults()
+	cfg.Hooks.MaxConcurrent = 1
+	cfg.Hooks.Commands = []config.Hook{
+		{Name: "incidents", Command: `cat > "$OUT/incident-$RIPLEY_INCIDENT_ID.json"`, Events: []string{"incident_opened"}, Timeout: "5s", Env: map[string]string{"OUT": dir}},
+		{Name: "cycles", Command: `cat > "$OUT/$RIPLEY_EVENT-$RIPLEY_CYCLE_LABEL-$RIPLEY_FAILED.json"`, Events: []string{"cycle"}, Timeout: "5s", Env: map[string]string{"OUT": dir}},
+	}
+	r, err := NewRunner(cfg)
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+
+	r.Notify(context.Background(), notify.Event{Kind: notify.KindIncidentOpened, Incident: &notify.Incident{ID: 7}})
+	r.Notify(context.Background(), notify.Event{Kind: notify.KindIncidentResolved, Incident: &notify.Incident{ID: 7}})
+	results := []checker.Result{
+		{RunID: "run-1", Name: "Sum1to100", Passed: true, Duration: 2 * time.Second},
+		{RunID: "run-1", Name: "Capital", ErrorClass: "timeout"},
+	}
+	r.Cycle(NewCycle(results, storage.Cycle{Label: storage.CyclePartial}, time.Now()))
+	r.Wait()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	slices.Sort(files)
+	if strings.Join(files, ",") != "cycle-partial-1.json,incident-7.json" {
+		t.Fatalf("Expected one incident and one cycle payload, got %v", files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "cycle-partial-1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cycle Cycle
+	if err := json.Unmarshal(data, &cycle); err != nil {
+		t.Fatalf("Expected a JSON payload, got %q: %v", data, err)
+	}
+	if cycle.Kind != "cycle" || cycle.RunID != "run-1" || cycle.Total != 2 || cycle.Failed != 1 || cycle.Results[0].Duration != 2 {
+		t.Errorf("Unexpected cycle payload: %+v", cycle)
+	}
+}
@@ -0,0 +1,120 @@
+// Package logging sets up the daemon's structured logger: text or JSON
+// records at a configured level, written to stderr or to a file rotated by
+// size.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// New returns the logger configured by cfg and the file it writes to, nil
+// for stderr. The caller closes the file on exit.
+func New(cfg *config.Config) (*slog.Logger, io.Closer, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Logging.Level)); err != nil {
+		return nil, nil, fmt.Errorf("invalid logging.level: %w", err)
+	}
+
+	var w io.Writer = os.Stderr
+	var file *RotatingFile
+	if cfg.Logging.File != "" {
+		var err error
+		file, err = OpenRotating(cfg.Logging.File, int64(cfg.Logging.MaxSizeMB)<<20, *cfg.Logging.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = file
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(w, opts)
+	if strings.EqualFold(cfg.Logging.Format, "json") {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	if file == nil {
+		return slog.New(handler), nil, nil
+	}
+	return slog.New(handler), file, nil
+}
+
+// RotatingFile is a log file that is rotated once it reaches a size: path is
+// renamed to path.1, path.1 to path.2 and so on, keeping at most MaxBackups
+// rotated files.
+type RotatingFile struct {
+	path       string
+	maxSize    int64 // Zero never rotates
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotating opens the log file at path for appending, creating it.
+func OpenRotating(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would take the file past its size.
+// Records are never split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files up by one, dropping the oldest, and starts
+// a new file. If the file cannot be moved aside, writing goes on appending to
+// it and rotation is tried again on the next write.
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	if f.maxBackups > 0 {
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		}
+		os.Rename(f.path, f.path+".1")
+	} else {
+		os.Remove(f.path)
+	}
+	return f.open()
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.log")
+	f, err := OpenRotating(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotating failed: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"aaaaaa\n", "bbb\n", "cccccc\n", "dddddd\n", "eeeeeeeeeeee\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	// Records are never split, and one larger than the limit gets a file of
+	// its own
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path, "eeeeeeeeeeee\n"},
+		{path + ".1", "dddddd\n"},
+		{path + ".2", "cccccc\n"},
+		{path + ".3", ""},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(tt.path)
+		if tt.want == "" {
+			if !os.IsNotExist(err) {
+				t.Errorf("Expected %s to be dropped, got %q", tt.path, data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.path, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("Expected %q in %s, got %q", tt.want, filepath.Base(tt.path), data)
+		}
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.log")
+	if err := os.WriteFile(path, []byte("aaaaaa\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The size of an existing file counts toward the limit
+	f, err := OpenRotating(path, 10, 1)
+	if err != nil {
+		t.Fatalf("OpenRotating failed: %v", err)
+	}
+	defer f.Close()
+	f.Write([]byte("bbbbbb\n"))
+
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "aaaaaa\n" {
+		t.Errorf("Expected the existing file to be rotated, got %q", data)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cfg := config.LoadWithDefaults()
+	cfg.Logging.Format = "json"
+	cfg.Logging.Level = "warn"
+	cfg.Logging.File = filepath.Join(t.TempDir(), "ripley.log")
+
+	logger, closer, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	logger.Info("Cycle started", "run_id", "run-01")
+	logger.Warn("Benchmark failed", "run_id", "run-01", "benchmark", "Sum1to100")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.Logging.File)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warning, got %q", lines)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %q", lines[0])
+	}
+	if record["level"] != "WARN" || record["msg"] != "Benchmark failed" || record["benchmark"] != "Sum1to100" || record["run_id"] != "run-01" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	cfg.Logging.File = ""
+	if _, closer, err := New(cfg); err != nil || closer != nil {
+		t.Errorf("Expected stderr without a closer, got %v, %v", closer, err)
+	}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Bucket upper bounds of the benchmark histograms
+var (
+	durationBuckets = []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120}
+	tokenBuckets    = []float64{25, 50, 100, 200, 400, 800, 1600}
+	cycleBuckets    = []float64{10, 30, 60, 120, 300, 600, 1200}
+)
+
+// Daemon holds the metrics the daemon exposes on /metrics.
+type Daemon struct {
+	Registry *Registry
+
+	runs          *Counter
+	duration      *Histogram
+	tokens        *Histogram
+	effort        *Counter
+	errors        *Counter
+	lastRun       *Gauge
+	lastSuccess   *Gauge
+	passRate      *Gauge
+	avgTokens     *Gauge
+	avgDuration   *Gauge
+	cycles        *Counter
+	cycleDuration *Histogram
+	lastCycle     *Gauge
+}
+
+// NewDaemon registers the metrics of a daemon running benchmarks with model
+// and writing results through spool. The run counters of benchmarks start at
+// zero, so rates are defined before their first failure.
+func NewDaemon(model string, benchmarks []string, spool *storage.Spool) *Daemon {
+	r := NewRegistry()
+	d := &Daemon{
+		Registry:      r,
+		runs:          r.Counter("ripley_benchmark_runs_total", "Benchmark runs by result (pass or fail).", "benchmark", "result"),
+		duration:      r.Histogram("ripley_benchmark_duration_seconds", "Time benchmark runs took.", durationBuckets, "benchmark"),
+		tokens:        r.Histogram("ripley_benchmark_tokens", "Tokens benchmark runs used.", tokenBuckets, "benchmark"),
+		effort:        r.Counter("ripley_benchmark_effort_total", "Scored benchmark runs by effort category (good, medium or poor).", "benchmark", "effort"),
+		errors:        r.Counter("ripley_benchmark_errors_total", "Failed benchmark runs by error class.", "benchmark", "error_class"),
+		lastRun:       r.Gauge("ripley_benchmark_last_run_timestamp_seconds", "Unix time of the last run of each benchmark.", "benchmark"),
+		lastSuccess:   r.Gauge("ripley_benchmark_last_success_timestamp_seconds", "Unix time of the last passed run of each benchmark.", "benchmark"),
+		passRate:      r.Gauge("ripley_benchmark_rolling_pass_rate", "Pass rate (0-1) over the rolling window, outages excluded.", "benchmark"),
+		avgTokens:     r.Gauge("ripley_benchmark_rolling_avg_tokens", "Mean tokens used over the rolling window, outages excluded.", "benchmark"),
+		avgDuration:   r.Gauge("ripley_benchmark_rolling_avg_duration_seconds", "Mean run time over the rolling window, outages excluded.", "benchmark"),
+		cycles:        r.Counter("ripley_cycles_total", "Completed cycles by label (healthy, outage, partial, quality or unclassified).", "label"),
+		cycleDuration: r.Histogram("ripley_cycle_duration_seconds", "Time cycles took, from the first benchmark to the last notification.", cycleBuckets),
+		lastCycle:     r.Gauge("ripley_last_cycle_timestamp_seconds", "Unix time the last cycle completed."),
+	}
+	r.CounterFunc("ripley_db_write_failures_total", "Result writes and spool replays the database rejected.",
+		func() float64 { return float64(spool.Failures()) })
+	r.GaugeFunc("ripley_spool_pending", "Results spooled while waiting for the database.",
+		func() float64 { return float64(spool.Pending()) })
+	r.Gauge("ripley_info", "Always 1, labeled with the model the daemon benchmarks.", "model").Set(1, model)
+
+	for _, name := range benchmarks {
+		d.runs.Add(0, name, "pass")
+		d.runs.Add(0, name, "fail")
+	}
+	return d
+}
+
+// ObserveResults records the results of a cycle that ran at now.
+func (d *Daemon) ObserveResults(results []checker.Result, now time.Time) {
+	for _, r := range results {
+		d.duration.Observe(r.Duration.Seconds(), r.Name)
+		d.tokens.Observe(float64(r.TokensUsed), r.Name)
+		d.lastRun.Set(float64(now.Unix()), r.Name)
+		if r.Passed {
+			d.runs.Inc(r.Name, "pass")
+			d.lastSuccess.Set(float64(now.Unix()), r.Name)
+		} else {
+			d.runs.Inc(r.Name, "fail")
+		}
+		if r.Effort != "" {
+			d.effort.Inc(r.Name, r.Effort)
+		}
+		if r.ErrorClass != "" {
+			d.errors.Inc(r.Name, r.ErrorClass)
+		}
+	}
+}
+
+// ObserveStats records the rolling statistics of a benchmark.
+func (d *Daemon) ObserveStats(name string, avgTokens, avgDuration, passRate float64) {
+	d.passRate.Set(passRate, name)
+	d.avgTokens.Set(avgTokens, name)
+	d.avgDuration.Set(avgDuration, name)
+}
+
+// ObserveCycle records a cycle that completed at now after duration, with its
+// label; empty if the cycle was not classified.
+func (d *Daemon) ObserveCycle(label string, duration time.Duration, now time.Time) {
+	if label == "" {
+		label = "unclassified"
+	}
+	d.cycles.Inc(label)
+	d.cycleDuration.Observe(duration.Seconds())
+	d.lastCycle.Set(float64(now.Unix()))
+}
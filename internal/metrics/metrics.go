@@ -0,0 +1,318 @@
+// Package metrics keeps counters, gauges and histograms in memory and serves
+// them in the Prometheus text exposition format, so Ripley can be scraped and
+// graphed next to other services.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry is a set of metrics, written in the order they were registered.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// family is one metric name and its series, one per set of label values.
+type family struct {
+	name    string
+	help    string
+	typ     string // counter, gauge or histogram
+	labels  []string
+	buckets []float64      // Upper bounds, for histograms
+	fn      func() float64 // Computed when written, for metrics without labels
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64  // Counters and gauges; the sum for histograms
+	counts []uint64 // Per bucket, not cumulative, for histograms
+	count  uint64
+}
+
+func (r *Registry) register(f *family) *family {
+	f.series = make(map[string]*series)
+	if len(f.labels) == 0 && f.fn == nil {
+		// Metrics without labels are written from the start
+		f.get(nil)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, g := range r.families {
+		if g.name == f.name {
+			panic("metrics: " + f.name + " registered twice")
+		}
+	}
+	r.families = append(r.families, f)
+	return f
+}
+
+// get returns the series for values, creating it. The caller holds f.mu or
+// owns f.
+func (f *family) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: slices.Clone(values)}
+		if f.typ == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is a value that only goes up, e.g. the number of runs.
+type Counter struct{ f *family }
+
+// Counter registers a counter with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(&family{name: name, help: help, typ: "counter", labels: labels})}
+}
+
+// CounterFunc registers a counter without labels whose value is read from fn
+// whenever the registry is written.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, typ: "counter", fn: fn})
+}
+
+// Add adds v, which must not be negative, to the series with the given
+// label values.
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic("metrics: " + c.f.name + " cannot decrease")
+	}
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.get(values).value += v
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Gauge is a value that goes up and down, e.g. a timestamp.
+type Gauge struct{ f *family }
+
+// Gauge registers a gauge with the given label names.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(&family{name: name, help: help, typ: "gauge", labels: labels})}
+}
+
+// GaugeFunc registers a gauge without labels whose value is read from fn
+// whenever the registry is written.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, typ: "gauge", fn: fn})
+}
+
+// Set sets the series with the given label values to v.
+func (g *Gauge) Set(v float64, values ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(values).value = v
+}
+
+// Histogram counts observations, e.g. durations, into buckets.
+type Histogram struct{ f *family }
+
+// Histogram registers a histogram with the given bucket upper bounds, in
+// increasing order, and label names. The +Inf bucket is implied.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !slices.IsSorted(buckets) {
+		panic("metrics: buckets of " + name + " are not sorted")
+	}
+	return &Histogram{r.register(&family{name: name, help: help, typ: "histogram", labels: labels, buckets: buckets})}
+}
+
+// Observe adds v to the series with the given label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(values)
+	if i, _ := slices.BinarySearch(h.f.buckets, v); i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.value += v
+	s.count++
+}
+
+// Formats the registry can be written in
+const (
+	FormatPrometheus  = "prometheus"  // Prometheus text format 0.0.4
+	FormatOpenMetrics = "openmetrics" // OpenMetrics 1.0 text format
+)
+
+// WriteText writes every metric to w in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	return r.write(w, false)
+}
+
+// WriteOpenMetrics writes every metric to w in the OpenMetrics text format.
+func (r *Registry) WriteOpenMetrics(w io.Writer) error {
+	return r.write(w, true)
+}
+
+func (r *Registry) write(w io.Writer, openMetrics bool) error {
+	r.mu.Lock()
+	families := slices.Clone(r.families)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw, openMetrics)
+	}
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+// WriteFile atomically replaces the file at path with every metric in format,
+// so a collector reading it, such as node_exporter's textfile collector, never
+// sees a partial file.
+func (r *Registry) WriteFile(path, format string) error {
+	// A hidden name that does not end in .prom, which collectors skip
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if format == FormatOpenMetrics {
+		err = r.WriteOpenMetrics(tmp)
+	} else {
+		err = r.WriteText(tmp)
+	}
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	// Readable by a collector running as another user
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler serving the registry, e.g. on /metrics, in
+// the OpenMetrics format to scrapers that accept it and the Prometheus text
+// format to the rest.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+			r.WriteOpenMetrics(w)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// write writes f to w. OpenMetrics names a counter without its _total
+// suffix, which only its samples carry, and escapes quotes in help text.
+func (f *family) write(w *bufio.Writer, openMetrics bool) {
+	name, sample, help := f.name, f.name, escapeHelp(f.help)
+	if openMetrics {
+		help = labelEscaper.Replace(f.help)
+		if f.typ == "counter" {
+			name = strings.TrimSuffix(f.name, "_total")
+			sample = name + "_total"
+		}
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, f.typ)
+	if f.fn != nil {
+		fmt.Fprintf(w, "%s %s\n", sample, formatValue(f.fn()))
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		s := f.series[k]
+		if f.typ != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", sample, f.labelPairs(s.values, ""), formatValue(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, bound := range f.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelPairs(s.values, formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelPairs(s.values, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labelPairs(s.values, ""), formatValue(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labelPairs(s.values, ""), s.count)
+	}
+}
+
+// labelPairs formats the labels of a series, with le for histogram buckets
+// unless it is empty.
+func (f *family) labelPairs(values []string, le string) string {
+	var pairs []string
+	for i, name := range f.labels {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/checker"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	runs := r.Counter("runs_total", "Runs by result.", "benchmark", "result")
+	runs.Inc("B", "pass")
+	runs.Add(2, "A", "fail")
+	r.Gauge("info", "Build info.\nSecond line.", "model").Set(1, `Son"net\`)
+	h := r.Histogram("duration_seconds", "Durations.", []float64{1, 5})
+	h.Observe(0.5)
+	h.Observe(1)
+	h.Observe(7)
+	r.GaugeFunc("pending", "Pending.", func() float64 { return 3 })
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	want := `# HELP runs_total Runs by result.
+# TYPE runs_total counter
+runs_total{benchmark="A",result="fail"} 2
+runs_total{benchmark="B",result="pass"} 1
+# HELP info Build info.\nSecond line.
+# TYPE info gauge
+info{model="Son\"net\\"} 1
+# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="5"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 8.5
+duration_seconds_count 3
+# HELP pending Pending.
+# TYPE pending gauge
+pending 3
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("runs_total", `Runs, "all" of them.`, "result").Inc("pass")
+	r.CounterFunc("failures_total", "Failures.", func() float64 { return 2 })
+	r.Gauge("pending", "Pending.").Set(1)
+
+	var b strings.Builder
+	if err := r.WriteOpenMetrics(&b); err != nil {
+		t.Fatalf("WriteOpenMetrics failed: %v", err)
+	}
+	want := `# HELP runs Runs, \"all\" of them.
+# TYPE runs counter
+runs_total{result="pass"} 1
+# HELP failures Failures.
+# TYPE failures counter
+failures_total 2
+# HELP pending Pending.
+# TYPE pending gauge
+pending 1
+# EOF
+`
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("pending", "Pending.").Set(1)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ripley.prom")
+
+	for _, format := range []string{FormatPrometheus, FormatOpenMetrics} {
+		if err := r.WriteFile(path, format); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read metrics: %v", err)
+		}
+		if !strings.Contains(string(data), "pending 1\n") || strings.HasSuffix(string(data), "# EOF\n") != (format == FormatOpenMetrics) {
+			t.Errorf("Unexpected %s file:\n%s", format, data)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat metrics: %v", err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("Expected mode 0644, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files left, got %d entries", len(entries))
+	}
+
+	if err := r.WriteFile(filepath.Join(dir, "missing", "ripley.prom"), FormatPrometheus); err == nil {
+		t.Error("Expected error for a missing directory, got nil")
+	}
+}
+
+func TestHandlerNegotiates(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("runs_total", "Runs.")
+
+	tests := []struct {
+		accept      string
+		contentType string
+		eof         bool
+	}{
+		{"", "text/plain; version=0.0.4", false},
+		{"text/plain", "text/plain; version=0.0.4", false},
+		{"application/openmetrics-text;version=1.0.0,text/plain;q=0.5", "application/openmetrics-text; version=1.0.0", true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, req)
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("Expected content type %q for Accept %q, got %q", tt.contentType, tt.accept, ct)
+		}
+		if strings.HasSuffix(rec.Body.String(), "# EOF\n") != tt.eof {
+			t.Errorf("Unexpected body for Accept %q:\n%s", tt.accept, rec.Body.String())
+		}
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(r *Registry)
+	}{
+		{"duplicate name", func(r *Registry) { r.Counter("x", ""); r.Gauge("x", "") }},
+		{"wrong label count", func(r *Registry) { r.Counter("x", "", "a").Inc() }},
+		{"negative counter", func(r *Registry) { r.Counter("x", "").Add(-1) }},
+		{"unsorted buckets", func(r *Registry) { r.Histogram("x", "", []float64{2, 1}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for %s", tt.name)
+				}
+			}()
+			tt.fn(NewRegistry())
+		})
+	}
+}
+
+func TestDaemon(t *testing.T) {
+	spool, err := storage.NewSpool(storage.NewMemory(), filepath.Join(t.TempDir(), "spool.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	d := NewDaemon("Sonnet", []string{"Sum1to100", "Capital"}, spool)
+
+	now := time.Unix(1750000000, 0)
+	d.ObserveResults([]checker.Result{
+		{Name: "Sum1to100", Passed: true, TokensUsed: 120, Duration: 3 * time.Second, Effort: "good"},
+		{Name: "Capital", Passed: false, TokensUsed: 300, Duration: 40 * time.Second, Effort: "poor", ErrorClass: checker.ErrorTimeout},
+	}, now)
+	d.ObserveStats("Sum1to100", 120, 3, 0.9)
+	d.ObserveCycle("", 45*time.Second, now)
+
+	rec := httptest.NewRecorder()
+	d.Registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus content type, got %q", ct)
+	}
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		`ripley_benchmark_runs_total{benchmark="Capital",result="fail"} 1`,
+		`ripley_benchmark_runs_total{benchmark="Capital",result="pass"} 0`,
+		`ripley_benchmark_runs_total{benchmark="Sum1to100",result="pass"} 1`,
+		`ripley_benchmark_duration_seconds_bucket{benchmark="Sum1to100",le="5"} 1`,
+		`ripley_benchmark_tokens_sum{benchmark="Capital"} 300`,
+		`ripley_benchmark_effort_total{benchmark="Capital",effort="poor"} 1`,
+		`ripley_benchmark_errors_total{benchmark="Capital",error_class="timeout"} 1`,
+		`ripley_benchmark_last_run_timestamp_seconds{benchmark="Capital"} 1.75e+09`,
+		`ripley_benchmark_last_success_timestamp_seconds{benchmark="Sum1to100"} 1.75e+09`,
+		`ripley_benchmark_rolling_pass_rate{benchmark="Sum1to100"} 0.9`,
+		`ripley_benchmark_rolling_avg_duration_seconds{benchmark="Sum1to100"} 3`,
+		`ripley_cycles_total{label="unclassified"} 1`,
+		`ripley_cycle_duration_seconds_count 1`,
+		`ripley_db_write_failures_total 0`,
+		`ripley_spool_pending 0`,
+		`ripley_info{model="Sonnet"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `ripley_benchmark_last_success_timestamp_seconds{benchmark="Capital"}`) {
+		t.Error("Expected no last success for a benchmark that has not passed")
+	}
+}
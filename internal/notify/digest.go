@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/analysis"
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// DigestSchedule is when a daily or weekly digest is due.
+type DigestSchedule struct {
+	Weekly bool
+	Day    time.Weekday // For weekly digests
+	Hour   int
+	Minute int
+	Loc    *time.Location
+}
+
+// NewDigestSchedule returns the schedule of the digest configured in c, with
+// times of day in loc.
+func NewDigestSchedule(c config.Email, loc *time.Location) (DigestSchedule, error) {
+	at, err := time.Parse("15:04", c.DigestAt)
+	if err != nil {
+		return DigestSchedule{}, fmt.Errorf("invalid digest_at: %w", err)
+	}
+	day, err := config.Weekday(c.DigestDay)
+	if err != nil {
+		return DigestSchedule{}, err
+	}
+	return DigestSchedule{Weekly: c.Digest == "weekly", Day: day, Hour: at.Hour(), Minute: at.Minute(), Loc: loc}, nil
+}
+
+// Period returns the period covered by the latest digest due at or before
+// now: the day or week up to its scheduled time.
+func (s DigestSchedule) Period(now time.Time) (from, to time.Time) {
+	local := now.In(s.Loc)
+	to = time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, s.Loc)
+	if s.Weekly {
+		to = to.AddDate(0, 0, -((int(to.Weekday()) - int(s.Day) + 7) % 7))
+	}
+	if to.After(now) {
+		if s.Weekly {
+			to = to.AddDate(0, 0, -7)
+		} else {
+			to = to.AddDate(0, 0, -1)
+		}
+	}
+
+	from = to.AddDate(0, 0, -1)
+	if s.Weekly {
+		from = to.AddDate(0, 0, -7)
+	}
+	return from, to
+}
+
+// Digester sends the digests of the email destinations that have one when
+// they fall due. Digests due while the daemon was not running are skipped.
+type Digester struct {
+	model   string // Model the digests cover
+	digests []scheduledDigest
+}
+
+type scheduledDigest struct {
+	email    *Email
+	schedule DigestSchedule
+	last     time.Time // End of the period of the last digest sent
+}
+
+// NewDigester returns a digester for the email destinations in cfg, with
+// times of day in loc. Only digests due after now are sent.
+func NewDigester(cfg *config.Config, loc *time.Location, now time.Time) (*Digester, error) {
+	d := &Digester{model: cfg.Claude.Model}
+	for _, c := range cfg.Notify.Email {
+		if c.Digest == "" {
+			continue
+		}
+		email, err := NewEmail(c)
+		if err != nil {
+			return nil, fmt.Errorf("notify destination %s: %w", c.Name, err)
+		}
+		schedule, err := NewDigestSchedule(c, loc)
+		if err != nil {
+			return nil, fmt.Errorf("notify destination %s: %w", c.Name, err)
+		}
+		_, last := schedule.Period(now)
+		d.digests = append(d.digests, scheduledDigest{email: email, schedule: schedule, last: last})
+	}
+	return d, nil
+}
+
+// Send sends every digest that has fallen due since the last call and
+// returns how many it sent.
+func (d *Digester) Send(ctx context.Context, db storage.Store, now time.Time) (int, error) {
+	var errs []error
+	sent := 0
+	for i := range d.digests {
+		sd := &d.digests[i]
+		from, to := sd.schedule.Period(now)
+		if !to.After(sd.last) {
+			continue
+		}
+		// Whatever happens, do not retry every cycle
+		sd.last = to
+
+		digest, err := analysis.BuildDigest(db, d.model, from, to)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", sd.email.Name, err))
+			continue
+		}
+		if err := sd.email.Send(ctx, DigestSubject(digest, sd.schedule.Loc), DigestText(digest, sd.schedule.Loc)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+// DigestSubject returns the subject line of a digest email.
+func DigestSubject(d analysis.Digest, loc *time.Location) string {
+	incidents := "incidents"
+	if d.Incidents.Started == 1 {
+		incidents = "incident"
+	}
+	return fmt.Sprintf("[Ripley] Digest %s to %s: %.0f%% passed, %d %s",
+		d.From.In(loc).Format("Jan 2"), d.To.In(loc).Format("Jan 2"), d.PassRate()*100, d.Incidents.Started, incidents)
+}
+
+// DigestText renders a digest as plain text with times in loc.
+func DigestText(d analysis.Digest, loc *time.Location) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ripley digest for %s to %s\n\n",
+		d.From.In(loc).Format("Mon Jan 2 15:04"), d.To.In(loc).Format("Mon Jan 2 15:04 MST"))
+
+	if d.Results == 0 {
+		b.WriteString("No results were stored in this period. Is the daemon running?\n")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Results: %d, %.1f%% passed\n", d.Results, d.PassRate()*100)
+	fmt.Fprintf(&b, "Cycles: %s\n", countsText(d.Cycles, []string{
+		storage.CycleHealthy, storage.CyclePartial, storage.CycleQuality, storage.CycleOutage}))
+	fmt.Fprintf(&b, "Effort: %s\n", countsText(d.Effort, []string{"good", "medium", "poor"}))
+
+	b.WriteString("\nPass rates (previous period in parentheses)\n")
+	for _, bd := range d.Benchmarks {
+		if bd.Results == 0 {
+			continue
+		}
+		trend := ""
+		if previous, ok := bd.PreviousPassRate(); ok {
+			trend = fmt.Sprintf(" %s (%.0f%%)", TrendArrow(Stats{PassRate: bd.PassRate(), PreviousPassRate: &previous}), previous*100)
+		}
+		fmt.Fprintf(&b, "  %-20s %4.0f%%%s  %d runs, avg effort %.0f\n", bd.Name, bd.PassRate()*100, trend, bd.Results, bd.AvgEffort)
+	}
+
+	fmt.Fprintf(&b, "\nIncidents: %d started, %d resolved", d.Incidents.Started, d.Incidents.Resolved)
+	if d.Incidents.Resolved > 0 {
+		fmt.Fprintf(&b, ", MTTR %s", d.Incidents.MTTR.Round(time.Second))
+	}
+	fmt.Fprintf(&b, ", uptime %.2f%%\n", d.Incidents.Uptime()*100)
+	for _, inc := range d.Opened {
+		fmt.Fprintf(&b, "  %s\n", analysis.DescribeIncident(inc, d.To))
+	}
+
+	if d.Best != nil && d.Worst != nil {
+		b.WriteString("\nNotable quotes\n")
+		fmt.Fprintf(&b, "  Best (%s, effort %.0f): \"%s\"\n", d.Best.Name, d.Best.EffortScore, d.Best.Quote)
+		if d.Worst.ID != d.Best.ID {
+			fmt.Fprintf(&b, "  Worst (%s, effort %.0f): \"%s\"\n", d.Worst.Name, d.Worst.EffortScore, d.Worst.Quote)
+		}
+	}
+	return b.String()
+}
+
+// countsText lists counts in the given order, then any other keys sorted,
+// e.g. "40 healthy, 2 partial".
+func countsText(counts map[string]int, order []string) string {
+	var keys []string
+	for k := range counts {
+		if !slices.Contains(order, k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var parts []string
+	for _, k := range slices.Concat(order, keys) {
+		if counts[k] == 0 {
+			continue
+		}
+		label := k
+		if label == "" {
+			label = "unscored"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts[k], label))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
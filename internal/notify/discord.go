@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// Discord posts events to a Discord channel webhook as embeds.
+type Discord struct {
+	*Webhook
+}
+
+// NewDiscord returns the Discord destination configured in c.
+func NewDiscord(c config.Destination) (*Discord, error) {
+	w, err := newWebhook(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Discord{w}, nil
+}
+
+// Notify posts e as a Discord message.
+func (d *Discord) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(discordMessage(e))
+	if err != nil {
+		return fmt.Errorf("failed to encode Discord message: %w", err)
+	}
+	return d.post(ctx, e.Kind, body)
+}
+
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// Embed colors by severity
+const (
+	discordRed   = 0xD32F2F
+	discordAmber = 0xF9A825
+	discordBlue  = 0x1976D2
+	discordGreen = 0x388E3C
+)
+
+// discordMaxFields is the most fields Discord accepts in one embed.
+const discordMaxFields = 25
+
+// discordMessage renders e as one embed colored by severity, with Ripley's
+// quote in the footer.
+func discordMessage(e Event) discordPayload {
+	embed := discordEmbed{
+		Title:       icon(e) + " " + Title(e),
+		Description: e.Summary,
+		Color:       discordBlue,
+	}
+	switch {
+	case e.Kind == KindIncidentResolved || e.Kind == KindAlertResolved:
+		embed.Color = discordGreen
+	case e.Severity == SeverityCritical:
+		embed.Color = discordRed
+	case e.Severity == SeverityWarning:
+		embed.Color = discordAmber
+	}
+
+	for _, f := range fields(e) {
+		if len(embed.Fields) == discordMaxFields {
+			break
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: f.Name, Value: f.Value, Inline: true})
+	}
+	if e.Quote != "" {
+		embed.Footer = &discordFooter{Text: fmt.Sprintf("“%s” — Ripley", e.Quote)}
+	}
+	if !e.Time.IsZero() {
+		embed.Timestamp = e.Time.UTC().Format(time.RFC3339)
+	}
+	return discordPayload{Username: "Ripley", Embeds: []discordEmbed{embed}}
+}
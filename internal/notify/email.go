@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// Email sends alerts by SMTP. With TLS "starttls" the server must offer
+// STARTTLS; credentials are never sent over an unencrypted connection except
+// to localhost.
+type Email struct {
+	Name       string
+	Host       string
+	Port       int
+	TLS        string // "starttls", "implicit" or "none"
+	Username   string // Empty to send without authenticating
+	Password   string
+	From       string
+	To         []string
+	Timeout    time.Duration // For the whole exchange with the server
+	DigestOnly bool          // Skip alerts; only Send digests
+	TLSConfig  *tls.Config   // nil to verify the server against the system roots
+}
+
+// NewEmail returns the email destination configured in c.
+func NewEmail(c config.Email) (*Email, error) {
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return &Email{
+		Name:       c.Name,
+		Host:       c.Host,
+		Port:       c.Port,
+		TLS:        c.TLS,
+		Username:   c.Username,
+		Password:   c.Password,
+		From:       c.From,
+		To:         c.To,
+		Timeout:    timeout,
+		DigestOnly: c.DigestOnly,
+	}, nil
+}
+
+// Notify sends e as a plain text email, unless m only gets digests.
+func (m *Email) Notify(ctx context.Context, e Event) error {
+	if m.DigestOnly {
+		return nil
+	}
+	return m.Send(ctx, "[Ripley] "+Title(e)+": "+e.Summary, alertText(e))
+}
+
+// alertText renders e as the body of an email.
+func alertText(e Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n%s\n\n", Title(e), e.Summary)
+	for _, f := range fields(e) {
+		fmt.Fprintf(&b, "%s: %s\n", f.Name, f.Value)
+	}
+	if e.Quote != "" {
+		fmt.Fprintf(&b, "\n\"%s\"\n  -- Ripley\n", e.Quote)
+	}
+	if !e.Time.IsZero() {
+		fmt.Fprintf(&b, "\nSent by Ripley at %s\n", e.Time.UTC().Format(time.RFC1123))
+	}
+	return b.String()
+}
+
+// Send emails subject and the plain text body to every recipient.
+func (m *Email) Send(ctx context.Context, subject, body string) error {
+	if err := m.send(ctx, subject, body); err != nil {
+		return fmt.Errorf("email %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+func (m *Email) send(ctx context.Context, subject, body string) error {
+	deadline := time.Now().Add(m.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	tlsConfig := m.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: m.Host}
+	}
+
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(deadline)
+	if m.TLS == "implicit" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if m.TLS == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not offer STARTTLS; set tls to 'none' to send unencrypted", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(m.From); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message(m.From, m.To, subject, body, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message formats a plain text email. The subject is encoded for non-ASCII
+// text and the body is quoted-printable, so lines of any length and emoji
+// survive every relay.
+func message(from string, to []string, subject, body string, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	qp.Close()
+	return b.Bytes()
+}
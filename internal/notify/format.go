@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// field is a labeled value shown in a chat message.
+type field struct {
+	Name  string
+	Value string
+}
+
+// Title returns a short heading for e, e.g. "Benchmark degraded".
+func Title(e Event) string {
+	if e.Alert != nil && e.Alert.Repeat {
+		return title(e) + " (still firing)"
+	}
+	return title(e)
+}
+
+func title(e Event) string {
+	switch e.Kind {
+	case KindDegraded:
+		if e.Benchmark == "" {
+			return "Suite degraded"
+		}
+		return "Benchmark degraded"
+	case KindIncidentOpened:
+		return "Incident opened"
+	case KindIncidentResolved:
+		return "Incident resolved"
+	case KindSLOBurn:
+		return "SLO burning error budget"
+	case KindAlertResolved:
+		return "Alert resolved"
+	case KindAlertFlapping:
+		return "Alert flapping"
+	case KindTest:
+		return "Test notification"
+	}
+	return e.Kind
+}
+
+// icon returns the emoji shown before the title of e.
+func icon(e Event) string {
+	switch {
+	case e.Kind == KindIncidentResolved || e.Kind == KindAlertResolved:
+		return "✅"
+	case e.Severity == SeverityCritical:
+		return "🚨"
+	case e.Severity == SeverityWarning:
+		return "⚠️"
+	}
+	return "ℹ️"
+}
+
+// TrendArrow shows how the pass rate moved against the window before: ↑, ↓
+// or → for no change, and nothing without enough history.
+func TrendArrow(s Stats) string {
+	if s.PreviousPassRate == nil {
+		return ""
+	}
+	const epsilon = 1e-9
+	switch previous := *s.PreviousPassRate; {
+	case s.PassRate > previous+epsilon:
+		return "↑"
+	case s.PassRate < previous-epsilon:
+		return "↓"
+	}
+	return "→"
+}
+
+// fields returns the details of e worth showing next to its summary.
+func fields(e Event) []field {
+	var fs []field
+	if e.Benchmark != "" {
+		fs = append(fs, field{"Benchmark", e.Benchmark})
+	}
+	if e.Model != "" {
+		fs = append(fs, field{"Model", e.Model})
+	}
+	if len(e.Labels) > 0 {
+		labels := make([]string, 0, len(e.Labels))
+		for k, v := range e.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		slices.Sort(labels)
+		fs = append(fs, field{"Labels", strings.Join(labels, ", ")})
+	}
+
+	if s := e.Stats; s != nil {
+		passRate := fmt.Sprintf("%.0f%%", s.PassRate*100)
+		if arrow := TrendArrow(*s); arrow != "" {
+			passRate += fmt.Sprintf(" %s (was %.0f%%)", arrow, *s.PreviousPassRate*100)
+		}
+		fs = append(fs,
+			field{fmt.Sprintf("Pass rate (last %d runs)", s.Window), passRate},
+			field{"Avg tokens", fmt.Sprintf("%.1f", s.AvgTokens)},
+			field{"Avg duration", fmt.Sprintf("%.2fs", s.AvgDuration)},
+		)
+	}
+
+	if inc := e.Incident; inc != nil {
+		fs = append(fs, field{"Incident", fmt.Sprintf("#%d (%s)", inc.ID, inc.Scope)})
+		if len(inc.Benchmarks) > 0 {
+			fs = append(fs, field{"Failing benchmarks", strings.Join(inc.Benchmarks, ", ")})
+		}
+		if len(inc.ErrorClasses) > 0 {
+			fs = append(fs, field{"Error classes", errorClasses(inc.ErrorClasses)})
+		}
+		fs = append(fs, field{"Started", inc.StartedAt.UTC().Format("2006-01-02 15:04 MST")})
+		if inc.EndedAt != nil {
+			fs = append(fs, field{"Duration", inc.EndedAt.Sub(inc.StartedAt).Round(time.Second).String()})
+		}
+	}
+
+	if a := e.Alert; a != nil {
+		fs = append(fs, field{"Alert", fmt.Sprintf("%s, %s since %s", a.Rule, a.State, a.Since.UTC().Format("2006-01-02 15:04 MST"))})
+	}
+	return fs
+}
+
+// errorClasses lists error classes by count, most frequent first, e.g.
+// "timeout ×3, over_budget ×1".
+func errorClasses(counts map[string]int) string {
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	slices.SortFunc(classes, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	parts := make([]string, len(classes))
+	for i, class := range classes {
+		parts[i] = fmt.Sprintf("%s ×%d", class, counts[class])
+	}
+	return strings.Join(parts, ", ")
+}
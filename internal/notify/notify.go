@@ -0,0 +1,222 @@
+// Package notify delivers Ripley's warnings, such as degraded benchmarks and
+// incidents, to external services.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+// Event kinds
+const (
+	KindDegraded         = "benchmark_degraded"
+	KindIncidentOpened   = "incident_opened"
+	KindIncidentResolved = "incident_resolved"
+	KindSLOBurn          = "slo_burn"
+	KindAlertResolved    = "alert_resolved"
+	KindAlertFlapping    = "alert_flapping"
+	KindTest             = "test"
+)
+
+// Severities, from least to most urgent
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Event is something worth telling people about. It is sent as JSON, so the
+// field names are part of the webhook payload.
+type Event struct {
+	Kind      string            `json:"kind"`
+	Severity  string            `json:"severity"`
+	Time      time.Time         `json:"time"`
+	Summary   string            `json:"summary"`          // One line, e.g. "Sum1to100 pass rate 40% is below 70%"
+	Tags      []string          `json:"tags,omitempty"`   // For routing, e.g. "incident" and the benchmark name
+	Labels    map[string]string `json:"labels,omitempty"` // From the alert rule; routed on as "key=value" tags
+	Benchmark string            `json:"benchmark,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	Quote     string            `json:"quote,omitempty"`
+	Stats     *Stats            `json:"stats,omitempty"`
+	Incident  *Incident         `json:"incident,omitempty"`
+	Alert     *Alert            `json:"alert,omitempty"`
+}
+
+// Stats are the rolling statistics of a benchmark.
+type Stats struct {
+	Window      int     `json:"window"` // Runs the statistics cover
+	AvgTokens   float64 `json:"avg_tokens"`
+	AvgDuration float64 `json:"avg_duration_seconds"`
+	PassRate    float64 `json:"pass_rate"` // 0.0-1.0
+
+	// Pass rate over the window of runs before these; nil without enough history
+	PreviousPassRate *float64 `json:"previous_pass_rate,omitempty"`
+}
+
+// Alert describes the state of the alert that sent an event.
+type Alert struct {
+	Rule   string    `json:"rule"`
+	State  string    `json:"state"`            // "firing", "resolved" or "flapping"
+	Since  time.Time `json:"since"`            // When the alert entered State
+	Repeat bool      `json:"repeat,omitempty"` // A reminder of an alert already notified
+}
+
+// Incident describes the incident an event is about.
+type Incident struct {
+	ID           int64          `json:"id"`
+	Scope        string         `json:"scope"` // "suite" or a benchmark name
+	StartedAt    time.Time      `json:"started_at"`
+	EndedAt      *time.Time     `json:"ended_at,omitempty"`
+	Benchmarks   []string       `json:"benchmarks"`
+	ErrorClasses map[string]int `json:"error_classes"`
+}
+
+// NewIncident returns the payload form of inc.
+func NewIncident(inc storage.Incident) *Incident {
+	i := &Incident{
+		ID:           inc.ID,
+		Scope:        inc.Scope,
+		StartedAt:    inc.StartedAt,
+		Benchmarks:   inc.Benchmarks,
+		ErrorClasses: inc.ErrorClasses,
+	}
+	if !inc.EndedAt.IsZero() {
+		ended := inc.EndedAt
+		i.EndedAt = &ended
+	}
+	return i
+}
+
+// Notifier delivers events to one destination.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Multi delivers every event to each of its notifiers.
+type Multi []Notifier
+
+// Notify delivers e to every notifier, even when some of them fail, and
+// returns their errors joined.
+func (m Multi) Notify(ctx context.Context, e Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SeverityRank orders severities, from 0 for info to 2 for critical; unknown
+// ones rank with info.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 2
+	case SeverityWarning:
+		return 1
+	}
+	return 0
+}
+
+// Route selects the events a destination gets.
+type Route struct {
+	MinSeverity string   // Empty for every severity
+	Tags        []string // Events need at least one of these tags; empty for every event
+}
+
+// Matches reports whether e is routed to the destination.
+func (r Route) Matches(e Event) bool {
+	if SeverityRank(e.Severity) < SeverityRank(r.MinSeverity) {
+		return false
+	}
+	if len(r.Tags) == 0 {
+		return true
+	}
+	for _, tag := range r.Tags {
+		if slices.Contains(e.Tags, tag) {
+			return true
+		}
+		if key, value, ok := strings.Cut(tag, "="); ok {
+			if label, labeled := e.Labels[key]; labeled && label == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Destination is a named notifier that only gets the events routed to it.
+type Destination struct {
+	Name     string
+	Route    Route
+	Notifier Notifier
+}
+
+// Notify delivers e if it is routed to d.
+func (d Destination) Notify(ctx context.Context, e Event) error {
+	if !d.Route.Matches(e) {
+		return nil
+	}
+	return d.Notifier.Notify(ctx, e)
+}
+
+// Destinations returns every destination configured in cfg: the webhooks,
+// the Slack and the Discord channels, then the email recipients.
+func Destinations(cfg *config.Config) ([]Destination, error) {
+	var all []Destination
+	add := func(c config.Destination, n Notifier, err error) error {
+		if err != nil {
+			return fmt.Errorf("notify destination %s: %w", c.Name, err)
+		}
+		all = append(all, Destination{Name: c.Name, Route: Route{MinSeverity: c.MinSeverity, Tags: c.Tags}, Notifier: n})
+		return nil
+	}
+
+	for _, c := range cfg.Notify.Webhooks {
+		w, err := NewWebhook(c)
+		if err := add(c.Destination, w, err); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range cfg.Notify.Slack {
+		s, err := NewSlack(c)
+		if err := add(c, s, err); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range cfg.Notify.Discord {
+		d, err := NewDiscord(c)
+		if err := add(c, d, err); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range cfg.Notify.Email {
+		m, err := NewEmail(c)
+		if err := add(config.Destination{Name: c.Name, MinSeverity: c.MinSeverity, Tags: c.Tags}, m, err); err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+// FromConfig returns a notifier delivering each event to the destinations
+// in cfg it is routed to.
+func FromConfig(cfg *config.Config) (Multi, error) {
+	destinations, err := Destinations(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var m Multi
+	for _, d := range destinations {
+		m = append(m, d)
+	}
+	return m, nil
+}
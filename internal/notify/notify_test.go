@@ -0,0 +1,770 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/storage"
+)
+
+func testEvent() Event {
+	return Event{
+		Kind:      KindDegraded,
+		Severity:  SeverityWarning,
+		Time:      time.Date(2025, 12, 16, 9, 30, 0, 0, time.UTC),
+		Summary:   "Sum1to100 pass rate 40% is below 70%",
+		Benchmark: "Sum1to100",
+		Quote:     "Do better. Or I will notice.",
+		Stats:     &Stats{Window: 10, AvgTokens: 120, AvgDuration: 3.5, PassRate: 0.4},
+	}
+}
+
+func TestWebhookDelivers(t *testing.T) {
+	var got Event
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		header = r.Header
+		if r.Header.Get(SignatureHeader) != Sign("s3cret", body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		json.Unmarshal(body, &got)
+	}))
+	defer server.Close()
+
+	w := &Webhook{Name: "test", URL: server.URL, Secret: "s3cret", Headers: map[string]string{"Authorization": "Bearer token"}, Attempts: 1}
+	if err := w.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Expected delivery, got %v", err)
+	}
+
+	if got.Kind != KindDegraded || got.Benchmark != "Sum1to100" || got.Stats == nil || got.Stats.PassRate != 0.4 {
+		t.Errorf("Unexpected payload: %+v", got)
+	}
+	if header.Get(EventHeader) != KindDegraded || header.Get(DeliveryHeader) == "" {
+		t.Errorf("Expected event and delivery headers, got %v", header)
+	}
+	if header.Get("Authorization") != "Bearer token" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected configured and content type headers, got %v", header)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		attempts  int
+		expectErr bool
+		expected  int32 // Requests made
+	}{
+		{"success", []int{200}, 3, false, 1},
+		{"server error then success", []int{500, 502, 200}, 3, false, 3},
+		{"rate limited then success", []int{429, 204}, 3, false, 2},
+		{"server error every time", []int{500, 500, 500, 500}, 3, true, 3},
+		{"client error is final", []int{400, 200}, 3, true, 1},
+		{"single attempt", []int{503, 200}, 1, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			var deliveries []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1)
+				deliveries = append(deliveries, r.Header.Get(DeliveryHeader))
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			w := &Webhook{Name: "test", URL: server.URL, Attempts: tt.attempts, Backoff: time.Millisecond}
+			err := w.Notify(context.Background(), testEvent())
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Expected error %v, got %v", tt.expectErr, err)
+			}
+			if requests != tt.expected {
+				t.Errorf("Expected %d requests, got %d", tt.expected, requests)
+			}
+			for _, d := range deliveries {
+				if d != deliveries[0] {
+					t.Errorf("Expected one delivery ID across retries, got %v", deliveries)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	w := &Webhook{Name: "test", URL: server.URL, Attempts: 2, Backoff: time.Millisecond,
+		Client: &http.Client{Timeout: 20 * time.Millisecond}}
+
+	start := time.Now()
+	if err := w.Notify(context.Background(), testEvent()); err == nil {
+		t.Error("Expected timeout error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected delivery to give up quickly, took %v", elapsed)
+	}
+}
+
+func TestWebhookCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	w := &Webhook{Name: "test", URL: server.URL, Attempts: 10, Backoff: time.Hour}
+	err := w.Notify(ctx, testEvent())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var delivered int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer ok.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer broken.Close()
+
+	cfg := config.LoadWithDefaults()
+	cfg.Notify.Webhooks = []config.Webhook{
+		{Destination: config.Destination{Name: "broken", URL: broken.URL, Timeout: "1s", Attempts: 1, Backoff: "0s"}},
+		{Destination: config.Destination{Name: "ok", URL: ok.URL, Timeout: "1s", Attempts: 1, Backoff: "0s"}},
+	}
+	m, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Notify(context.Background(), testEvent())
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected error naming the broken webhook, got %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("Expected delivery to the working webhook, got %d", delivered)
+	}
+}
+
+func TestNewIncident(t *testing.T) {
+	started := time.Date(2025, 12, 16, 9, 0, 0, 0, time.UTC)
+	inc := storage.Incident{ID: 7, Scope: storage.ScopeSuite, StartedAt: started,
+		Benchmarks: []string{"Sum1to100"}, ErrorClasses: map[string]int{"timeout": 3}}
+
+	if got := NewIncident(inc); got.EndedAt != nil || got.ID != 7 || got.ErrorClasses["timeout"] != 3 {
+		t.Errorf("Unexpected open incident payload: %+v", got)
+	}
+
+	inc.EndedAt = started.Add(time.Hour)
+	if got := NewIncident(inc); got.EndedAt == nil || !got.EndedAt.Equal(inc.EndedAt) {
+		t.Errorf("Expected end time %v, got %v", inc.EndedAt, got.EndedAt)
+	}
+}
+
+func TestWebhookRetryAfter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	// The server asks for a second, far longer than the backoff
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	w := &Webhook{Name: "test", URL: server.URL, Attempts: 3, Backoff: time.Millisecond}
+	if err := w.Notify(ctx, testEvent()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while waiting, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request before Retry-After elapsed, got %d", requests)
+	}
+}
+
+func TestWebhookRetryAfterCapped(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "99999999999999999")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Centuries are cut down to MaxWait, so both attempts are made in time
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w := &Webhook{Name: "test", URL: server.URL, Attempts: 2, Backoff: time.Millisecond, MaxWait: 10 * time.Millisecond}
+	err := w.Notify(ctx, testEvent())
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the final status error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    Route
+		severity string
+		tags     []string
+		expected bool
+	}{
+		{"empty route", Route{}, SeverityInfo, nil, true},
+		{"severity reached", Route{MinSeverity: SeverityWarning}, SeverityCritical, nil, true},
+		{"severity too low", Route{MinSeverity: SeverityCritical}, SeverityWarning, nil, false},
+		{"tag matches", Route{Tags: []string{"incident", "slo"}}, SeverityInfo, []string{"slo", "liveness"}, true},
+		{"no tag matches", Route{Tags: []string{"incident"}}, SeverityCritical, []string{"benchmark"}, false},
+		{"untagged event", Route{Tags: []string{"incident"}}, SeverityCritical, nil, false},
+		{"both must match", Route{MinSeverity: SeverityCritical, Tags: []string{"incident"}}, SeverityInfo, []string{"incident"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.Matches(Event{Severity: tt.severity, Tags: tt.tags}); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// Labels are routed on as key=value tags
+	labeled := Event{Severity: SeverityWarning, Labels: map[string]string{"team": "infra"}}
+	if !(Route{Tags: []string{"team=infra"}}).Matches(labeled) {
+		t.Error("Expected team=infra to match the label")
+	}
+	if (Route{Tags: []string{"team=evals"}}).Matches(labeled) || (Route{Tags: []string{"owner="}}).Matches(labeled) {
+		t.Error("Expected other labels not to match")
+	}
+}
+
+func TestDestinationsRoute(t *testing.T) {
+	var delivered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = append(delivered, r.URL.Path)
+	}))
+	defer server.Close()
+
+	cfg := config.LoadWithDefaults()
+	cfg.Notify.Webhooks = []config.Webhook{
+		{Destination: config.Destination{Name: "all", URL: server.URL + "/all", Timeout: "1s", Attempts: 1, Backoff: "0s"}},
+	}
+	cfg.Notify.Slack = []config.Destination{
+		{Name: "pager", URL: server.URL + "/pager", MinSeverity: SeverityCritical, Timeout: "1s", Attempts: 1, Backoff: "0s"},
+	}
+	cfg.Notify.Discord = []config.Destination{
+		{Name: "incidents", URL: server.URL + "/incidents", Tags: []string{"incident"}, Timeout: "1s", Attempts: 1, Backoff: "0s"},
+	}
+
+	destinations, err := Destinations(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(destinations) != 3 || destinations[1].Name != "pager" {
+		t.Fatalf("Unexpected destinations: %+v", destinations)
+	}
+	if _, ok := destinations[2].Notifier.(*Discord); !ok {
+		t.Errorf("Expected a Discord notifier, got %T", destinations[2].Notifier)
+	}
+
+	m, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Notify(context.Background(), testEvent())
+	m.Notify(context.Background(), Event{Kind: KindIncidentOpened, Severity: SeverityCritical, Tags: []string{"incident"}})
+
+	expected := "/all /all /pager /incidents"
+	if got := strings.Join(delivered, " "); got != expected {
+		t.Errorf("Expected deliveries %q, got %q", expected, got)
+	}
+}
+
+func TestTrendArrow(t *testing.T) {
+	rate := func(r float64) *float64 { return &r }
+	tests := []struct {
+		passRate float64
+		previous *float64
+		expected string
+	}{
+		{0.4, nil, ""},
+		{0.4, rate(0.7), "↓"},
+		{0.9, rate(0.7), "↑"},
+		{0.7, rate(0.7), "→"},
+	}
+
+	for _, tt := range tests {
+		if got := TrendArrow(Stats{PassRate: tt.passRate, PreviousPassRate: tt.previous}); got != tt.expected {
+			t.Errorf("Expected %q for %.1f, got %q", tt.expected, tt.passRate, got)
+		}
+	}
+}
+
+func TestSlackMessage(t *testing.T) {
+	var got slackPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	e := testEvent()
+	previous := 0.7
+	e.Stats.PreviousPassRate = &previous
+	e.Summary = "Sum1to100 pass rate 40% is below 70% <here>"
+
+	s := &Slack{&Webhook{Name: "slack", URL: server.URL, Attempts: 1}}
+	if err := s.Notify(context.Background(), e); err != nil {
+		t.Fatalf("Expected delivery, got %v", err)
+	}
+
+	if len(got.Blocks) != 4 {
+		t.Fatalf("Expected header, summary, fields and quote blocks, got %+v", got.Blocks)
+	}
+	if got.Blocks[0].Type != "header" || got.Blocks[0].Text.Text != "⚠️ Benchmark degraded" {
+		t.Errorf("Unexpected header: %+v", got.Blocks[0])
+	}
+	if !strings.Contains(got.Blocks[1].Text.Text, "&lt;here&gt;") {
+		t.Errorf("Expected escaped summary, got %q", got.Blocks[1].Text.Text)
+	}
+	if !strings.Contains(got.Text, e.Summary) {
+		t.Errorf("Expected fallback text with the summary, got %q", got.Text)
+	}
+
+	var passRate string
+	for _, f := range got.Blocks[2].Fields {
+		if strings.HasPrefix(f.Text, "*Pass rate") {
+			passRate = f.Text
+		}
+	}
+	if !strings.Contains(passRate, "40% ↓ (was 70%)") {
+		t.Errorf("Expected pass rate with trend, got %q", passRate)
+	}
+	if quote := got.Blocks[3].Elements[0].Text; !strings.Contains(quote, "Do better. Or I will notice.") {
+		t.Errorf("Expected Ripley's quote, got %q", quote)
+	}
+}
+
+func TestDiscordMessage(t *testing.T) {
+	ended := time.Date(2025, 12, 16, 10, 15, 0, 0, time.UTC)
+	e := Event{
+		Kind:     KindIncidentResolved,
+		Severity: SeverityInfo,
+		Time:     ended,
+		Summary:  "Suite incident #3 resolved after 1h15m0s",
+		Incident: &Incident{ID: 3, Scope: storage.ScopeSuite, StartedAt: ended.Add(-75 * time.Minute), EndedAt: &ended,
+			Benchmarks: []string{"Sum1to100", "ListReverse"}, ErrorClasses: map[string]int{"timeout": 4, "over_budget": 1}},
+	}
+
+	msg := discordMessage(e)
+	if len(msg.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %d", len(msg.Embeds))
+	}
+	embed := msg.Embeds[0]
+	if embed.Color != discordGreen || embed.Title != "✅ Incident resolved" || embed.Timestamp != "2025-12-16T10:15:00Z" {
+		t.Errorf("Unexpected embed: %+v", embed)
+	}
+
+	values := make(map[string]string)
+	for _, f := range embed.Fields {
+		values[f.Name] = f.Value
+	}
+	expected := map[string]string{
+		"Failing benchmarks": "Sum1to100, ListReverse",
+		"Error classes":      "timeout ×4, over_budget ×1",
+		"Duration":           "1h15m0s",
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("Expected %s %q, got %q", name, value, values[name])
+		}
+	}
+
+	e.Severity, e.Kind = SeverityCritical, KindIncidentOpened
+	if color := discordMessage(e).Embeds[0].Color; color != discordRed {
+		t.Errorf("Expected red for critical events, got %#x", color)
+	}
+}
+
+// fakeSMTP is a minimal SMTP server that records the messages it receives.
+type fakeSMTP struct {
+	addr     string
+	tls      *tls.Config // Offers STARTTLS if set, unless implicit
+	implicit bool        // TLS from the first byte
+	username string      // Requires AUTH PLAIN if set
+	password string
+
+	mu       sync.Mutex
+	messages []fakeMail
+}
+
+type fakeMail struct {
+	From   string
+	To     []string
+	Data   []byte
+	Secure bool
+	Authed bool
+}
+
+// testTLS returns a server config with a self-signed certificate for
+// 127.0.0.1 and a client config that trusts it.
+func testTLS(t *testing.T) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	return server, client
+}
+
+func startSMTP(t *testing.T, s *fakeSMTP) *fakeSMTP {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.implicit {
+		l = tls.NewListener(l, s.tls)
+	}
+	t.Cleanup(func() { l.Close() })
+	s.addr = l.Addr().String()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+
+	m := fakeMail{Secure: s.implicit}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			lines := []string{"fake"}
+			if s.tls != nil && !m.Secure {
+				lines = append(lines, "STARTTLS")
+			}
+			if s.username != "" {
+				lines = append(lines, "AUTH PLAIN")
+			}
+			for i, l := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				tp.PrintfLine("250%s%s", sep, l)
+			}
+		case "STARTTLS":
+			tp.PrintfLine("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, m.Secure = tlsConn, textproto.NewConn(tlsConn), true
+		case "AUTH":
+			_, encoded, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			if string(decoded) != "\x00"+s.username+"\x00"+s.password {
+				tp.PrintfLine("535 Authentication failed")
+				continue
+			}
+			m.Authed = true
+			tp.PrintfLine("235 Authenticated")
+		case "MAIL":
+			if s.username != "" && !m.Authed {
+				tp.PrintfLine("530 Authentication required")
+				continue
+			}
+			m.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			m.To = append(m.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			m.Data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, m)
+			s.mu.Unlock()
+			tp.PrintfLine("250 Queued")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTP) received() []fakeMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+// testEmail returns an email destination that sends to s.
+func testEmail(s *fakeSMTP, clientTLS *tls.Config) *Email {
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	return &Email{Name: "team", Host: host, Port: p, TLS: "none", From: "ripley@example.com",
+		To: []string{"ops@example.com", "dev@example.com"}, Timeout: 5 * time.Second, TLSConfig: clientTLS}
+}
+
+// readMail decodes the subject and body of a received message.
+func readMail(t *testing.T, data []byte) (subject, body string) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("Failed to decode subject: %v", err)
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	return subject, string(decoded)
+}
+
+func TestEmailSends(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	tests := []struct {
+		name     string
+		server   *fakeSMTP
+		tls      string
+		username string
+		password string
+		expected string // Error substring; empty for success
+	}{
+		{"starttls with auth", &fakeSMTP{tls: serverTLS, username: "ripley", password: "s3cret"}, "starttls", "ripley", "s3cret", ""},
+		{"implicit tls", &fakeSMTP{tls: serverTLS, implicit: true}, "implicit", "", "", ""},
+		{"plain to localhost", &fakeSMTP{}, "none", "", "", ""},
+		{"starttls not offered", &fakeSMTP{}, "starttls", "", "", "STARTTLS"},
+		{"wrong password", &fakeSMTP{tls: serverTLS, username: "ripley", password: "s3cret"}, "starttls", "ripley", "guess", "authentication failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startSMTP(t, tt.server)
+			m := testEmail(server, clientTLS)
+			m.TLS, m.Username, m.Password = tt.tls, tt.username, tt.password
+
+			err := m.Notify(context.Background(), testEvent())
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) {
+					t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+				}
+				if len(server.received()) != 0 {
+					t.Error("Expected no message to be sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the email to be sent, got %v", err)
+			}
+
+			received := server.received()
+			if len(received) != 1 {
+				t.Fatalf("Expected 1 message, got %d", len(received))
+			}
+			got := received[0]
+			if got.Secure != (tt.tls != "none") || got.Authed != (tt.username != "") {
+				t.Errorf("Unexpected security: secure %v, authenticated %v", got.Secure, got.Authed)
+			}
+			if got.From != "ripley@example.com" || len(got.To) != 2 {
+				t.Errorf("Unexpected envelope: %s to %v", got.From, got.To)
+			}
+
+			subject, body := readMail(t, got.Data)
+			if subject != "[Ripley] Benchmark degraded: Sum1to100 pass rate 40% is below 70%" {
+				t.Errorf("Unexpected subject %q", subject)
+			}
+			if !strings.Contains(body, "Pass rate (last 10 runs): 40%") || !strings.Contains(body, "Do better. Or I will notice.") {
+				t.Errorf("Expected stats and quote in the body, got %q", body)
+			}
+		})
+	}
+}
+
+func TestEmailDigestOnly(t *testing.T) {
+	server := startSMTP(t, &fakeSMTP{})
+	m := testEmail(server, nil)
+	m.DigestOnly = true
+
+	if err := m.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(server.received()) != 0 {
+		t.Error("Expected no alert for a digest-only destination")
+	}
+}
+
+func TestDigestSchedule(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	daily := DigestSchedule{Hour: 8, Loc: loc}
+	weekly := DigestSchedule{Weekly: true, Day: time.Monday, Hour: 8, Minute: 30, Loc: loc}
+
+	// Wednesday, December 17, 2025
+	tests := []struct {
+		name     string
+		schedule DigestSchedule
+		now      time.Time
+		from     time.Time
+		to       time.Time
+	}{
+		{"daily after the time", daily, time.Date(2025, 12, 17, 9, 0, 0, 0, loc),
+			time.Date(2025, 12, 16, 8, 0, 0, 0, loc), time.Date(2025, 12, 17, 8, 0, 0, 0, loc)},
+		{"daily before the time", daily, time.Date(2025, 12, 17, 7, 59, 0, 0, loc),
+			time.Date(2025, 12, 15, 8, 0, 0, 0, loc), time.Date(2025, 12, 16, 8, 0, 0, 0, loc)},
+		{"daily in another zone", daily, time.Date(2025, 12, 17, 6, 0, 0, 0, time.UTC),
+			time.Date(2025, 12, 16, 8, 0, 0, 0, loc), time.Date(2025, 12, 17, 8, 0, 0, 0, loc)},
+		{"weekly midweek", weekly, time.Date(2025, 12, 17, 9, 0, 0, 0, loc),
+			time.Date(2025, 12, 8, 8, 30, 0, 0, loc), time.Date(2025, 12, 15, 8, 30, 0, 0, loc)},
+		{"weekly on the day before the time", weekly, time.Date(2025, 12, 15, 8, 0, 0, 0, loc),
+			time.Date(2025, 12, 1, 8, 30, 0, 0, loc), time.Date(2025, 12, 8, 8, 30, 0, 0, loc)},
+		{"weekly at the time", weekly, time.Date(2025, 12, 15, 8, 30, 0, 0, loc),
+			time.Date(2025, 12, 8, 8, 30, 0, 0, loc), time.Date(2025, 12, 15, 8, 30, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := tt.schedule.Period(tt.now)
+			if !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Errorf("Expected %v to %v, got %v to %v", tt.from, tt.to, from, to)
+			}
+		})
+	}
+}
+
+func TestDigester(t *testing.T) {
+	server := startSMTP(t, &fakeSMTP{})
+	host, port, _ := net.SplitHostPort(server.addr)
+	p, _ := strconv.Atoi(port)
+
+	cfg := config.LoadWithDefaults()
+	cfg.Notify.Email = []config.Email{{Name: "team", Host: host, Port: p, TLS: "none", From: "ripley@example.com",
+		To: []string{"ops@example.com"}, Timeout: "5s", Digest: "daily", DigestAt: "08:00", DigestDay: "monday"}}
+
+	db := storage.NewMemory()
+	start := time.Date(2025, 12, 16, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		db.InsertRecord(storage.BenchmarkRecord{Name: "Sum1to100", Model: "Sonnet", Passed: i != 3, EffortScore: float64(60 + 10*i),
+			Effort: "medium", Quote: fmt.Sprintf("quote %d", i), Timestamp: start.Add(time.Duration(i) * time.Hour)})
+	}
+
+	d, err := NewDigester(cfg, time.UTC, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		now      time.Time
+		expected int
+	}{
+		{start, 0},                     // The digest due at startup was skipped
+		{start.Add(22 * time.Hour), 0}, // 07:00 the next day
+		{start.Add(23 * time.Hour), 1}, // 08:00 the next day
+		{start.Add(24 * time.Hour), 0}, // Already sent
+	}
+	for i, step := range steps {
+		sent, err := d.Send(context.Background(), db, step.now)
+		if err != nil || sent != step.expected {
+			t.Errorf("Step %d: expected %d digests sent, got %d (err %v)", i, step.expected, sent, err)
+		}
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 digest, got %d", len(received))
+	}
+	subject, body := readMail(t, received[0].Data)
+	if subject != "[Ripley] Digest Dec 16 to Dec 17: 75% passed, 0 incidents" {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+	for _, expected := range []string{"Results: 4, 75.0% passed", "Effort: 4 medium", "Sum1to100", `Best (Sum1to100, effort 90): "quote 3"`, `Worst (Sum1to100, effort 60): "quote 0"`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in the digest, got:\n%s", expected, body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// Slack posts events to a Slack incoming webhook as Block Kit messages.
+// Incoming webhooks are tied to one channel, so each channel is its own
+// destination.
+type Slack struct {
+	*Webhook
+}
+
+// NewSlack returns the Slack destination configured in c.
+func NewSlack(c config.Destination) (*Slack, error) {
+	w, err := newWebhook(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{w}, nil
+}
+
+// Notify posts e as a Slack message.
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(slackMessage(e))
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+	return s.post(ctx, e.Kind, body)
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"` // Shown in notifications and by clients without blocks
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// slackMaxFields is the most fields Slack accepts in one section block.
+const slackMaxFields = 10
+
+// slackMessage renders e as a header, the summary, its fields and Ripley's
+// quote.
+func slackMessage(e Event) slackPayload {
+	title := icon(e) + " " + Title(e)
+	msg := slackPayload{
+		Text: title + ": " + e.Summary,
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{"plain_text", title}},
+			{Type: "section", Text: &slackText{"mrkdwn", slackEscape(e.Summary)}},
+		},
+	}
+
+	var fs []slackText
+	for _, f := range fields(e) {
+		fs = append(fs, slackText{"mrkdwn", fmt.Sprintf("*%s*\n%s", slackEscape(f.Name), slackEscape(f.Value))})
+	}
+	for len(fs) > 0 {
+		n := min(len(fs), slackMaxFields)
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Fields: fs[:n]})
+		fs = fs[n:]
+	}
+
+	if e.Quote != "" {
+		msg.Blocks = append(msg.Blocks, slackBlock{
+			Type:     "context",
+			Elements: []slackText{{"mrkdwn", fmt.Sprintf("_“%s”_ — Ripley", slackEscape(e.Quote))}},
+		})
+	}
+	return msg
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn text.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
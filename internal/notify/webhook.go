@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cryptopatrick/ripley/internal/config"
+)
+
+// Headers set on every webhook request
+const (
+	SignatureHeader = "X-Ripley-Signature-256" // "sha256=" and the hex HMAC-SHA256 of the body
+	EventHeader     = "X-Ripley-Event"         // The event kind
+	DeliveryHeader  = "X-Ripley-Delivery"      // Random ID, the same across retries of one delivery
+)
+
+// DefaultMaxWait is the longest Retry-After delay honored by webhooks that
+// do not set their own.
+const DefaultMaxWait = time.Minute
+
+// Webhook posts events as JSON to a URL. Deliveries that fail on a network
+// error, a timeout, a 429 or a 5xx response are retried with exponential
+// backoff, or after the delay in a Retry-After header up to MaxWait or the
+// backoff, whichever is longer; other responses are final.
+type Webhook struct {
+	Name     string
+	URL      string
+	Secret   string // HMAC-SHA256 key; empty leaves requests unsigned
+	Headers  map[string]string
+	Attempts int           // Total attempts per event
+	Backoff  time.Duration // Wait before the first retry, doubled after each
+	MaxWait  time.Duration // Longest Retry-After honored; zero uses DefaultMaxWait
+	Client   *http.Client
+}
+
+// NewWebhook returns the webhook configured in c.
+func NewWebhook(c config.Webhook) (*Webhook, error) {
+	w, err := newWebhook(c.Destination)
+	if err != nil {
+		return nil, err
+	}
+	w.Secret = c.Secret
+	w.Headers = c.Headers
+	return w, nil
+}
+
+// newWebhook returns an unsigned webhook posting to the destination d.
+func newWebhook(d config.Destination) (*Webhook, error) {
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	backoff, err := time.ParseDuration(d.Backoff)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backoff: %w", err)
+	}
+	return &Webhook{
+		Name:     d.Name,
+		URL:      d.URL,
+		Attempts: d.Attempts,
+		Backoff:  backoff,
+		Client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Sign returns the signature header value of body under secret. Receivers
+// recompute it over the raw request body and compare with hmac.Equal.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify posts e, retrying transient failures.
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return w.post(ctx, e.Kind, body)
+}
+
+// post delivers body, retrying transient failures. Slack and Discord use it
+// for their own payloads.
+func (w *Webhook) post(ctx context.Context, kind string, body []byte) error {
+	delivery, err := deliveryID()
+	if err != nil {
+		return err
+	}
+
+	backoff := w.Backoff
+	maxWait := w.MaxWait
+	if maxWait == 0 {
+		maxWait = DefaultMaxWait
+	}
+	for attempt := 1; ; attempt++ {
+		retry, wait, err := w.attempt(ctx, kind, delivery, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.Attempts {
+			return fmt.Errorf("webhook %s: %w", w.Name, err)
+		}
+		if wait == 0 {
+			wait = backoff
+		}
+		wait = min(wait, max(backoff, maxWait))
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("webhook %s: %w (after %v)", w.Name, ctx.Err(), err)
+		}
+		backoff *= 2
+	}
+}
+
+// attempt makes one request and reports whether a failure is worth retrying
+// and, if the server said so, how long to wait first.
+func (w *Webhook) attempt(ctx context.Context, kind, delivery string, body []byte) (retry bool, wait time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ripley")
+	req.Header.Set(EventHeader, kind)
+	req.Header.Set(DeliveryHeader, delivery)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, 0, nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err = fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	if seconds, convErr := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64); convErr == nil && seconds > 0 {
+		// Huge values would overflow into a negative wait
+		wait = min(time.Duration(seconds), math.MaxInt64/time.Second) * time.Second
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, wait, err
+}
+
+func deliveryID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate delivery ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
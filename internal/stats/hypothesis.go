@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// FisherExact returns the two-sided p-value of Fisher's exact test for the
+// 2x2 contingency table
+//
+//	| a | b |
+//	| c | d |
+//
+// e.g. passes and failures (a, b) in one sample against passes and failures
+// (c, d) in another.
+func FisherExact(a, b, c, d int) float64 {
+	row1, col1, n := a+b, a+c, a+b+c+d
+	if n == 0 {
+		return 1
+	}
+
+	// Probability of the table whose top-left cell is x, margins fixed
+	logP := func(x int) float64 {
+		return logChoose(col1, x) + logChoose(n-col1, row1-x) - logChoose(n, row1)
+	}
+
+	observed := logP(a)
+	lo, hi := max(0, row1+col1-n), min(row1, col1)
+
+	var p float64
+	for x := lo; x <= hi; x++ {
+		// Tables at least as extreme as the observed one, with a relative
+		// tolerance for floating point error
+		if lp := logP(x); lp <= observed+1e-7 {
+			p += math.Exp(lp)
+		}
+	}
+	return math.Min(p, 1)
+}
+
+// logChoose returns log(n choose k).
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// MannWhitney returns the U statistic of xs and the two-sided p-value of the
+// Mann-Whitney U test that xs and ys come from the same distribution. The
+// p-value uses the normal approximation with tie and continuity correction,
+// which is adequate from about eight values per sample.
+func MannWhitney(xs, ys []float64) (u, p float64) {
+	n1, n2 := len(xs), len(ys)
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+
+	type value struct {
+		v     float64
+		first bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, x := range xs {
+		all = append(all, value{x, true})
+	}
+	for _, y := range ys {
+		all = append(all, value{y, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Assign average ranks to ties and accumulate the tie correction
+	var rankSum, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // ranks i+1..j averaged
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	u = rankSum - fn1*(fn1+1)/2
+
+	mu := fn1 * fn2 / 2
+	sigma := math.Sqrt(fn1 * fn2 / 12 * ((n + 1) - tieTerm/(n*(n-1))))
+	if sigma == 0 {
+		return u, 1
+	}
+
+	z := (math.Abs(u-mu) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return u, math.Min(math.Erfc(z/math.Sqrt2), 1)
+}
+
+// Median returns the median of xs, or 0 for an empty slice.
+func Median(xs []float64) float64 {
+	return Quantile(xs, 0.5)
+}
+
+// Quantile returns the q-th quantile (0.0-1.0) of xs using linear
+// interpolation between closest ranks, or 0 for an empty slice.
+func Quantile(xs []float64, q float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(xs))
+	copy(sorted, xs)
+	sort.Float64s(sorted)
+
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
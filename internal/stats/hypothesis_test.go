@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFisherExact(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d int
+		want       float64
+	}{
+		// Reference values from R's fisher.test
+		{"tea tasting", 3, 1, 1, 3, 0.4857},
+		{"clear drop", 10, 0, 3, 7, 0.0031},
+		{"identical", 5, 5, 5, 5, 1.0},
+		{"empty", 0, 0, 0, 0, 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FisherExact(tt.a, tt.b, tt.c, tt.d)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("FisherExact(%d, %d, %d, %d) = %.4f, want %.4f", tt.a, tt.b, tt.c, tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	xs := []float64{7, 8, 7, 6, 7, 8, 7, 7, 6, 7}
+	ys := []float64{12, 11, 13, 12, 12, 11, 13, 12, 12, 14}
+
+	u, p := MannWhitney(xs, ys)
+	if u != 0 {
+		t.Errorf("Expected U=0 for fully separated samples, got %.1f", u)
+	}
+	if p > 0.001 {
+		t.Errorf("Expected p < 0.001 for fully separated samples, got %.4f", p)
+	}
+
+	_, p = MannWhitney(xs, xs)
+	if p < 0.9 {
+		t.Errorf("Expected p close to 1 for identical samples, got %.4f", p)
+	}
+
+	_, p = MannWhitney([]float64{5, 5, 5}, []float64{5, 5})
+	if p != 1 {
+		t.Errorf("Expected p=1 when all values tie, got %.4f", p)
+	}
+
+	_, p = MannWhitney(nil, ys)
+	if p != 1 {
+		t.Errorf("Expected p=1 for an empty sample, got %.4f", p)
+	}
+}
+
+func TestMannWhitneyReference(t *testing.T) {
+	// R: wilcox.test(c(1.83,0.50,1.62,2.48,1.68,1.88,1.55,3.06,1.30),
+	//                c(0.878,0.647,0.598,2.05,1.06,1.29,1.06,3.14,1.29), exact=FALSE)
+	// W = 58, p-value = 0.1329
+	xs := []float64{1.83, 0.50, 1.62, 2.48, 1.68, 1.88, 1.55, 3.06, 1.30}
+	ys := []float64{0.878, 0.647, 0.598, 2.05, 1.06, 1.29, 1.06, 3.14, 1.29}
+
+	u, p := MannWhitney(xs, ys)
+	if u != 58 {
+		t.Errorf("Expected U=58, got %.1f", u)
+	}
+	if math.Abs(p-0.1329) > 0.002 {
+		t.Errorf("Expected p=0.1329, got %.4f", p)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	xs := []float64{4, 1, 3, 2}
+	if got := Median(xs); got != 2.5 {
+		t.Errorf("Median() = %v, want 2.5", got)
+	}
+	if got := Quantile(xs, 1); got != 4 {
+		t.Errorf("Quantile(1) = %v, want 4", got)
+	}
+	if got := Quantile([]float64{1, 2, 3, 4, 5}, 0.95); math.Abs(got-4.8) > 1e-9 {
+		t.Errorf("Quantile(0.95) = %v, want 4.8", got)
+	}
+	if got := Median(nil); got != 0 {
+		t.Errorf("Median(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,49 @@
+package stats
+
+import "math"
+
+// Z95 is the standard normal quantile for a two-sided 95% interval.
+const Z95 = 1.959964
+
+// StdDev returns the sample standard deviation of xs, or 0 for fewer than
+// two values.
+func StdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mean := Mean(xs)
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// Wilson returns the Wilson score interval for a proportion of successes
+// out of n trials at normal quantile z. Unlike the normal approximation it
+// stays within [0, 1] and behaves sensibly for small n and proportions near
+// 0 or 1. With no trials the interval is [0, 1].
+func Wilson(successes, n int, z float64) (lo, hi float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	fn := float64(n)
+	p := float64(successes) / fn
+	z2 := z * z
+
+	center := (p + z2/(2*fn)) / (1 + z2/fn)
+	half := z * math.Sqrt(p*(1-p)/fn+z2/(4*fn*fn)) / (1 + z2/fn)
+	return math.Max(0, center-half), math.Min(1, center+half)
+}
+
+// MeanCI returns the mean of xs with a normal-approximation confidence
+// interval at quantile z. The interval collapses to the mean for fewer than
+// two values.
+func MeanCI(xs []float64, z float64) (mean, lo, hi float64) {
+	mean = Mean(xs)
+	if len(xs) < 2 {
+		return mean, mean, mean
+	}
+	half := z * StdDev(xs) / math.Sqrt(float64(len(xs)))
+	return mean, mean - half, mean + half
+}
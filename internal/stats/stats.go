@@ -0,0 +1,72 @@
+// Package stats implements the statistical primitives used to analyze
+// benchmark history.
+package stats
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Mean returns the arithmetic mean of xs, or 0 for an empty slice.
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// CUSUM locates the most likely single shift in the mean of xs using the
+// cumulative sum of deviations from the mean, and estimates its confidence
+// by bootstrapping: the range of the cumulative sum is compared against the
+// range obtained from random reorderings of the same data, which have no
+// shift. index is the position of the first value after the shift and
+// confidence is the fraction of reorderings with a smaller range (0.0-1.0).
+//
+// rng drives the reorderings; pass a seeded source for reproducible results.
+func CUSUM(xs []float64, bootstraps int, rng *rand.Rand) (index int, confidence float64) {
+	if len(xs) < 2 {
+		return 0, 0
+	}
+
+	mean := Mean(xs)
+	sDiff, index := cusumRange(xs, mean)
+	if sDiff == 0 || bootstraps <= 0 {
+		return index, 0
+	}
+
+	shuffled := make([]float64, len(xs))
+	copy(shuffled, xs)
+
+	smaller := 0
+	for i := 0; i < bootstraps; i++ {
+		rng.Shuffle(len(shuffled), func(a, b int) {
+			shuffled[a], shuffled[b] = shuffled[b], shuffled[a]
+		})
+		if d, _ := cusumRange(shuffled, mean); d < sDiff {
+			smaller++
+		}
+	}
+
+	return index, float64(smaller) / float64(bootstraps)
+}
+
+// cusumRange returns max(S)-min(S) of the cumulative sum S of deviations
+// from mean, and the index following the point where |S| peaks.
+func cusumRange(xs []float64, mean float64) (float64, int) {
+	var s, lo, hi, peak float64
+	index := 0
+	for i, x := range xs {
+		s += x - mean
+		lo = math.Min(lo, s)
+		hi = math.Max(hi, s)
+		if math.Abs(s) > peak {
+			peak = math.Abs(s)
+			index = i + 1
+		}
+	}
+	return hi - lo, index
+}
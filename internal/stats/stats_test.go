@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMean(t *testing.T) {
+	if got := Mean([]float64{1, 2, 3, 4}); got != 2.5 {
+		t.Errorf("Mean() = %v, want 2.5", got)
+	}
+	if got := Mean(nil); got != 0 {
+		t.Errorf("Mean(nil) = %v, want 0", got)
+	}
+}
+
+func TestCUSUM(t *testing.T) {
+	tests := []struct {
+		name          string
+		xs            []float64
+		wantIndex     int
+		minConfidence float64
+		maxConfidence float64
+	}{
+		{
+			name:          "clear step up",
+			xs:            []float64{7, 7, 8, 7, 6, 7, 8, 7, 7, 7, 12, 13, 12, 11, 12, 13, 12, 12},
+			wantIndex:     10,
+			minConfidence: 0.99,
+			maxConfidence: 1,
+		},
+		{
+			name:          "pass rate drop",
+			xs:            []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 1, 0, 0, 0, 0, 0},
+			wantIndex:     10,
+			minConfidence: 0.99,
+			maxConfidence: 1,
+		},
+		{
+			name:          "constant",
+			xs:            []float64{5, 5, 5, 5, 5, 5},
+			wantIndex:     0,
+			minConfidence: 0,
+			maxConfidence: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, confidence := CUSUM(tt.xs, 1000, rand.New(rand.NewSource(1)))
+			if index != tt.wantIndex {
+				t.Errorf("CUSUM() index = %d, want %d", index, tt.wantIndex)
+			}
+			if confidence < tt.minConfidence || confidence > tt.maxConfidence {
+				t.Errorf("CUSUM() confidence = %.3f, want within [%.2f, %.2f]", confidence, tt.minConfidence, tt.maxConfidence)
+			}
+		})
+	}
+}
+
+func TestCUSUMNoise(t *testing.T) {
+	// Series without a shift should rarely look significant: at a 0.95
+	// threshold roughly 5% of them may, by chance.
+	significant := 0
+	for seed := int64(0); seed < 100; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		xs := make([]float64, 60)
+		for i := range xs {
+			xs[i] = 10 + rng.NormFloat64()
+		}
+
+		if _, confidence := CUSUM(xs, 200, rand.New(rand.NewSource(1))); confidence >= 0.95 {
+			significant++
+		}
+	}
+
+	if significant > 15 {
+		t.Errorf("Expected few false positives in pure noise, got %d/100", significant)
+	}
+}
+
+func TestWilson(t *testing.T) {
+	tests := []struct {
+		successes, n int
+		lo, hi       float64
+	}{
+		// Reference values from R's prop.test(correct = FALSE)
+		{8, 10, 0.4902, 0.9433},
+		{10, 10, 0.7225, 1.0},
+		{0, 10, 0.0, 0.2775},
+		{0, 0, 0.0, 1.0},
+	}
+
+	for _, tt := range tests {
+		lo, hi := Wilson(tt.successes, tt.n, Z95)
+		if math.Abs(lo-tt.lo) > 0.001 || math.Abs(hi-tt.hi) > 0.001 {
+			t.Errorf("Wilson(%d, %d) = [%.4f, %.4f], want [%.4f, %.4f]", tt.successes, tt.n, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestMeanCI(t *testing.T) {
+	mean, lo, hi := MeanCI([]float64{2, 4, 4, 4, 5, 5, 7, 9}, Z95)
+	// Sample standard deviation 2.138, standard error 0.756
+	if mean != 5 || math.Abs(lo-3.518) > 0.001 || math.Abs(hi-6.482) > 0.001 {
+		t.Errorf("MeanCI() = %.3f [%.3f, %.3f], want 5 [3.518, 6.482]", mean, lo, hi)
+	}
+
+	mean, lo, hi = MeanCI([]float64{3}, Z95)
+	if mean != 3 || lo != 3 || hi != 3 {
+		t.Errorf("Expected a collapsed interval for one value, got %.1f [%.1f, %.1f]", mean, lo, hi)
+	}
+}
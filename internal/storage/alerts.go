@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Alert states
+const (
+	AlertOK     = "ok"
+	AlertFiring = "firing"
+)
+
+// Alert is the persisted state of one alert: a rule evaluated for one
+// benchmark, or for the whole suite.
+type Alert struct {
+	Model     string
+	Rule      string
+	Benchmark string // Empty for suite-wide alerts
+	State     string // AlertOK or AlertFiring
+	Severity  string // Of the latest evaluation that fired
+	Summary   string // Of the latest evaluation
+	Since     time.Time
+	Flips     []time.Time // State changes within the flap window, oldest first
+	Flapping  bool
+
+	// What was last notified: "", "firing", "resolved" or "flapping", with
+	// its severity and time
+	Notified         string
+	NotifiedSeverity string
+	NotifiedAt       time.Time
+}
+
+// SaveAlert stores the state of an alert, replacing the previous one.
+func (s *Storage) SaveAlert(a Alert) error {
+	flips, err := json.Marshal(utcTimes(a.Flips))
+	if err != nil {
+		return fmt.Errorf("failed to encode alert flips: %w", err)
+	}
+
+	query := `
+		INSERT INTO alerts (model, rule, benchmark, state, severity, summary, since, flips, flapping, notified, notified_severity, notified_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (model, rule, benchmark) DO UPDATE SET
+			state = excluded.state,
+			severity = excluded.severity,
+			summary = excluded.summary,
+			since = excluded.since,
+			flips = excluded.flips,
+			flapping = excluded.flapping,
+			notified = excluded.notified,
+			notified_severity = excluded.notified_severity,
+			notified_at = excluded.notified_at
+	`
+	err = s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), a.Model, a.Rule, a.Benchmark, a.State, a.Severity, a.Summary, a.Since.UTC(),
+			string(flips), a.Flapping, a.Notified, a.NotifiedSeverity, nullTime(a.NotifiedAt))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save alert: %w", err)
+	}
+	return nil
+}
+
+// ListAlerts returns the state of every alert of model, by rule and benchmark.
+func (s *Storage) ListAlerts(model string) ([]Alert, error) {
+	query := `
+		SELECT model, rule, benchmark, state, severity, summary, since, flips, flapping, notified, notified_severity, notified_at
+		FROM alerts
+	`
+	var args []any
+	if model != "" {
+		query += ` WHERE model = ?`
+		args = append(args, model)
+	}
+	query += ` ORDER BY rule, benchmark, model`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		var flips string
+		var notifiedAt sql.NullTime
+		if err := rows.Scan(&a.Model, &a.Rule, &a.Benchmark, &a.State, &a.Severity, &a.Summary, &a.Since, &flips,
+			&a.Flapping, &a.Notified, &a.NotifiedSeverity, &notifiedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		if err := json.Unmarshal([]byte(flips), &a.Flips); err != nil {
+			return nil, fmt.Errorf("failed to decode alert flips: %w", err)
+		}
+		a.NotifiedAt = notifiedAt.Time
+		alerts = append(alerts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+func utcTimes(times []time.Time) []time.Time {
+	utc := make([]time.Time, len(times))
+	for i, t := range times {
+		utc[i] = t.UTC()
+	}
+	return utc
+}
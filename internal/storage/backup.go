@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupPrefix and backupSuffix frame the timestamp in scheduled backup names.
+const (
+	backupPrefix = "ripley-"
+	backupSuffix = ".db"
+)
+
+// Backup writes a consistent snapshot of store to path using SQLite's online
+// backup API, so it is safe to call while the daemon is writing. The snapshot
+// is written next to path and renamed into place, so path never holds a
+// partial copy. Only SQLite stores can be backed up this way; use pg_dump
+// for Postgres.
+func Backup(store Store, path string) error {
+	if spool, ok := store.(*Spool); ok {
+		store = spool.Store
+	}
+
+	s, ok := store.(*Storage)
+	if !ok || s.dialect != dialectSQLite {
+		return fmt.Errorf("online backup is only supported for SQLite databases")
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := s.backupTo(tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// backupTo copies every page of the main database into a new file at path.
+func (s *Storage) backupTo(path string) error {
+	ctx := context.Background()
+
+	src, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	destDB, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer destDB.Close()
+
+	dest, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	return dest.Raw(func(destDriver any) error {
+		return src.Raw(func(srcDriver any) error {
+			destConn, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destDriver)
+			}
+			srcConn, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", srcDriver)
+			}
+
+			b, err := destConn.Backup("main", srcConn, "main")
+			if err != nil {
+				return err
+			}
+
+			// Step(-1) copies all remaining pages in one go
+			if _, err := b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+			return b.Finish()
+		})
+	})
+}
+
+// BackupRotated writes a timestamped backup of store into dir and deletes
+// the oldest scheduled backups so that at most keep remain. keep <= 0 keeps
+// every backup. Returns the path of the new backup.
+func BackupRotated(store Store, dir string, keep int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(dir, backupPrefix+now.UTC().Format("20060102T150405Z")+backupSuffix)
+	if err := Backup(store, path); err != nil {
+		return "", err
+	}
+
+	if keep > 0 {
+		if err := pruneBackups(dir, keep); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// pruneBackups removes all but the newest keep scheduled backups in dir.
+// Timestamped names sort chronologically, so the newest sort last.
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Restore replaces the SQLite database at livePath with the backup at
+// backupPath. The backup must be an intact Ripley database whose schema is
+// not newer than this build; older schemas are migrated on the next open.
+// The current database, if any, is first saved next to livePath with a
+// ".pre-restore" suffix, whose path is returned; it is empty if there was no
+// database to save. The daemon must not be running during a restore.
+func Restore(backupPath, livePath string) (string, error) {
+	if strings.Contains(livePath, "://") || livePath == "memory:" {
+		return "", fmt.Errorf("restore is only supported for SQLite databases")
+	}
+
+	version, err := inspectBackup(backupPath)
+	if err != nil {
+		return "", err
+	}
+	if version > SchemaVersion {
+		return "", fmt.Errorf("backup schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+
+	var previous string
+	if _, err := os.Stat(livePath); err == nil {
+		live, err := New(livePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open live database: %w", err)
+		}
+		previous = livePath + ".pre-restore"
+		err = Backup(live, previous)
+		live.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	tmp := livePath + ".restore.tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to stage backup: %w", err)
+	}
+
+	// A WAL left over from the old database would be replayed into the
+	// restored one, so it has to go before the new file is moved in.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(livePath + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmp)
+			return "", fmt.Errorf("failed to remove %s: %w", livePath+suffix, err)
+		}
+	}
+
+	if err := os.Rename(tmp, livePath); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to replace live database: %w", err)
+	}
+	return previous, nil
+}
+
+// inspectBackup checks that path is an intact Ripley database and returns
+// its schema version.
+func inspectBackup(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	// immutable=1 keeps SQLite from creating -wal/-shm files next to the backup
+	db, err := sql.Open("sqlite3", sqliteDSN(path, true)+"&immutable=1")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&integrity); err != nil {
+		return 0, fmt.Errorf("backup is not a readable SQLite database: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("backup failed integrity check: %s", integrity)
+	}
+
+	s := &Storage{db: db, dialect: dialectSQLite, readOnly: true}
+	version, err := s.schemaVersion()
+	if err != nil {
+		return 0, fmt.Errorf("backup is not a Ripley database: %w", err)
+	}
+	if version == 0 {
+		return 0, fmt.Errorf("backup is not a Ripley database: no schema version")
+	}
+	return version, nil
+}
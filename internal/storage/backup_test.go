@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	livePath := filepath.Join(dir, "ripley.db")
+	backupPath := filepath.Join(dir, "snapshot.db")
+
+	live, err := New(livePath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	for _, name := range []string{"A", "B"} {
+		if err := live.InsertRecord(BenchmarkRecord{RunID: "run-1", Name: name, Passed: true, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	// Back up while the database is open, then keep writing
+	if err := Backup(live, backupPath); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err := live.InsertRecord(BenchmarkRecord{RunID: "run-2", Name: "C", Passed: true, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+	live.Close()
+
+	previous, err := Restore(backupPath, livePath)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if previous != livePath+".pre-restore" {
+		t.Errorf("Expected the live database kept at %s.pre-restore, got %q", livePath, previous)
+	}
+
+	restored, err := New(livePath)
+	if err != nil {
+		t.Fatalf("Failed to open restored database: %v", err)
+	}
+	defer restored.Close()
+
+	runs, err := restored.ListRuns("", 10)
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].RunID != "run-1" || runs[0].Total != 2 {
+		t.Errorf("Expected only run-1 after restore, got %+v", runs)
+	}
+
+	// The replaced database is kept aside
+	if _, err := os.Stat(livePath + ".pre-restore"); err != nil {
+		t.Errorf("Expected pre-restore copy: %v", err)
+	}
+}
+
+func TestRestoreRejectsInvalidBackups(t *testing.T) {
+	dir := t.TempDir()
+	livePath := filepath.Join(dir, "ripley.db")
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("definitely not sqlite"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	foreign := filepath.Join(dir, "foreign.db")
+	db, err := sql.Open("sqlite3", foreign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE other (id INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	newer := filepath.Join(dir, "newer.db")
+	s, err := New(newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec(`UPDATE schema_version SET version = ?`, SchemaVersion+1); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	for name, path := range map[string]string{
+		"missing": filepath.Join(dir, "missing.db"),
+		"garbage": garbage,
+		"foreign": foreign,
+		"newer":   newer,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Restore(path, livePath); err == nil {
+				t.Errorf("Expected restore of %s backup to fail", name)
+			}
+			if _, err := os.Stat(livePath); !os.IsNotExist(err) {
+				t.Errorf("Live database must not be created by a failed restore")
+			}
+		})
+	}
+}
+
+func TestBackupRotated(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := New(filepath.Join(dir, "ripley.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	backupDir := filepath.Join(dir, "backups")
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if _, err := BackupRotated(s, backupDir, 2, base.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("BackupRotated failed: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"ripley-20250101T020000Z.db", "ripley-20250101T030000Z.db"}
+	if len(names) != 2 || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestBackupUnsupportedStore(t *testing.T) {
+	if err := Backup(NewMemory(), filepath.Join(t.TempDir(), "x.db")); err == nil {
+		t.Error("Expected backup of a memory store to fail")
+	}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Baseline is a named reference period that later windows are compared
+// against. It selects either the records in a time range or the records of
+// a fixed set of runs.
+type Baseline struct {
+	Name      string
+	Since     time.Time // Inclusive; zero when selected by runs
+	Until     time.Time // Exclusive; zero for no upper bound
+	RunIDs    []string  // Runs making up the baseline, if not a time range
+	CreatedAt time.Time
+}
+
+// Records returns the records of model selected by b, oldest first. An
+// empty model matches every model.
+func (b Baseline) Records(db Store, model string) ([]BenchmarkRecord, error) {
+	if len(b.RunIDs) == 0 {
+		return db.ListRecords(RecordFilter{Model: model, Since: b.Since, Until: b.Until})
+	}
+
+	var records []BenchmarkRecord
+	for _, id := range b.RunIDs {
+		run, err := db.GetRun(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range run {
+			if model == "" || r.Model == model {
+				records = append(records, r)
+			}
+		}
+	}
+	return records, nil
+}
+
+// nullTime maps the zero time to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// PinBaseline saves b, replacing any baseline with the same name.
+func (s *Storage) PinBaseline(b Baseline) error {
+	runIDs, err := json.Marshal(b.RunIDs)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO baselines (name, since, until, run_ids, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			since = excluded.since,
+			until = excluded.until,
+			run_ids = excluded.run_ids,
+			created_at = excluded.created_at
+	`
+	err = s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), b.Name, nullTime(b.Since), nullTime(b.Until), string(runIDs), b.CreatedAt.UTC())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pin baseline: %w", err)
+	}
+	return nil
+}
+
+// GetBaseline returns the baseline with the given name, or ErrNotFound.
+func (s *Storage) GetBaseline(name string) (Baseline, error) {
+	query := `SELECT name, since, until, run_ids, created_at FROM baselines WHERE name = ?`
+
+	b, err := scanBaseline(s.db.QueryRow(s.rebind(query), name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Baseline{}, ErrNotFound
+	}
+	if err != nil {
+		return Baseline{}, fmt.Errorf("failed to query baseline: %w", err)
+	}
+	return b, nil
+}
+
+// ListBaselines returns all pinned baselines ordered by name.
+func (s *Storage) ListBaselines() ([]Baseline, error) {
+	rows, err := s.db.Query(`SELECT name, since, until, run_ids, created_at FROM baselines ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query baselines: %w", err)
+	}
+	defer rows.Close()
+
+	var baselines []Baseline
+	for rows.Next() {
+		b, err := scanBaseline(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan baseline: %w", err)
+		}
+		baselines = append(baselines, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query baselines: %w", err)
+	}
+	return baselines, nil
+}
+
+func scanBaseline(row rowScanner) (Baseline, error) {
+	var b Baseline
+	var since, until sql.NullTime
+	var runIDs string
+	if err := row.Scan(&b.Name, &since, &until, &runIDs, &b.CreatedAt); err != nil {
+		return Baseline{}, err
+	}
+	b.Since, b.Until = since.Time, until.Time
+	if err := json.Unmarshal([]byte(runIDs), &b.RunIDs); err != nil {
+		return Baseline{}, fmt.Errorf("failed to decode baseline runs: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChangePoint is a statistically significant shift in a benchmark metric.
+type ChangePoint struct {
+	ID         int64
+	Model      string
+	Benchmark  string
+	Metric     string    // "pass_rate", "tokens" or "latency"
+	ChangeAt   time.Time // Timestamp of the first run after the shift
+	DetectedAt time.Time
+	BeforeMean float64
+	AfterMean  float64
+	BeforeRuns int
+	AfterRuns  int
+	Confidence float64 // 0.0-1.0
+}
+
+// Magnitude returns the size of the shift (after minus before).
+func (cp ChangePoint) Magnitude() float64 {
+	return cp.AfterMean - cp.BeforeMean
+}
+
+// InsertChangePoint saves cp unless it is already stored.
+func (s *Storage) InsertChangePoint(cp ChangePoint) (bool, error) {
+	query := `
+		INSERT INTO change_points
+			(model, benchmark, metric, change_at, detected_at, before_mean, after_mean, before_runs, after_runs, confidence)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (model, benchmark, metric, change_at) DO NOTHING
+	`
+
+	var inserted int64
+	err := s.withRetry(func() error {
+		res, err := s.db.Exec(s.rebind(query), cp.Model, cp.Benchmark, cp.Metric, cp.ChangeAt.UTC(), cp.DetectedAt.UTC(),
+			cp.BeforeMean, cp.AfterMean, cp.BeforeRuns, cp.AfterRuns, cp.Confidence)
+		if err != nil {
+			return err
+		}
+		inserted, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to insert change point: %w", err)
+	}
+
+	return inserted > 0, nil
+}
+
+// ListChangePoints returns stored change points of model, newest first.
+func (s *Storage) ListChangePoints(model, benchmark string, since time.Time) ([]ChangePoint, error) {
+	query := `
+		SELECT id, model, benchmark, metric, change_at, detected_at, before_mean, after_mean, before_runs, after_runs, confidence
+		FROM change_points
+		WHERE change_at >= ?
+	`
+	args := []any{since.UTC()}
+	if model != "" {
+		query += ` AND model = ?`
+		args = append(args, model)
+	}
+	if benchmark != "" {
+		query += ` AND benchmark = ?`
+		args = append(args, benchmark)
+	}
+	query += ` ORDER BY change_at DESC, id DESC`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query change points: %w", err)
+	}
+	defer rows.Close()
+
+	var points []ChangePoint
+	for rows.Next() {
+		var cp ChangePoint
+		if err := rows.Scan(&cp.ID, &cp.Model, &cp.Benchmark, &cp.Metric, &cp.ChangeAt, &cp.DetectedAt,
+			&cp.BeforeMean, &cp.AfterMean, &cp.BeforeRuns, &cp.AfterRuns, &cp.Confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan change point: %w", err)
+		}
+		points = append(points, cp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query change points: %w", err)
+	}
+
+	return points, nil
+}
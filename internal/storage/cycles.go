@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Cycle labels
+const (
+	CycleHealthy = "healthy" // Every benchmark passed
+	CycleOutage  = "outage"  // The CLI or the service was down
+	CyclePartial = "partial" // Some benchmarks failed on infrastructure errors
+	CycleQuality = "quality" // Benchmarks failed on the answers themselves
+)
+
+// Cycle is the classification of one benchmark cycle (run).
+type Cycle struct {
+	RunID        string
+	Model        string
+	StartedAt    time.Time
+	Label        string // One of the Cycle* labels
+	Total        int
+	Failed       int
+	ErrorClasses map[string]int
+	Reason       string
+}
+
+// SaveCycle stores the classification of a cycle, replacing any earlier one.
+func (s *Storage) SaveCycle(c Cycle) error {
+	classes, err := json.Marshal(c.ErrorClasses)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cycles (run_id, model, started_at, label, total, failed, error_classes, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (run_id) DO UPDATE SET
+			model = excluded.model,
+			started_at = excluded.started_at,
+			label = excluded.label,
+			total = excluded.total,
+			failed = excluded.failed,
+			error_classes = excluded.error_classes,
+			reason = excluded.reason
+	`
+	err = s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), c.RunID, c.Model, c.StartedAt.UTC(), c.Label, c.Total, c.Failed, string(classes), c.Reason)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save cycle: %w", err)
+	}
+	return nil
+}
+
+// GetCycle returns the classification of a cycle, or ErrNotFound.
+func (s *Storage) GetCycle(runID string) (Cycle, error) {
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE run_id = ?`
+
+	c, err := scanCycle(s.db.QueryRow(s.rebind(query), runID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Cycle{}, ErrNotFound
+	}
+	if err != nil {
+		return Cycle{}, fmt.Errorf("failed to query cycle: %w", err)
+	}
+	return c, nil
+}
+
+// ListCycles returns the cycles of model classified since since, newest first.
+func (s *Storage) ListCycles(model string, since time.Time) ([]Cycle, error) {
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE started_at >= ?`
+	args := []any{since.UTC()}
+	if model != "" {
+		query += ` AND model = ?`
+		args = append(args, model)
+	}
+	query += ` ORDER BY started_at DESC`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cycles: %w", err)
+	}
+	defer rows.Close()
+
+	var cycles []Cycle
+	for rows.Next() {
+		c, err := scanCycle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cycle: %w", err)
+		}
+		cycles = append(cycles, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query cycles: %w", err)
+	}
+	return cycles, nil
+}
+
+// cycleColumns lists the cycles columns read by scanCycle, in order.
+const cycleColumns = `run_id, model, started_at, label, total, failed, error_classes, reason`
+
+func scanCycle(row rowScanner) (Cycle, error) {
+	var c Cycle
+	var classes string
+	if err := row.Scan(&c.RunID, &c.Model, &c.StartedAt, &c.Label, &c.Total, &c.Failed, &classes, &c.Reason); err != nil {
+		return Cycle{}, err
+	}
+	if err := json.Unmarshal([]byte(classes), &c.ErrorClasses); err != nil {
+		return Cycle{}, fmt.Errorf("failed to decode cycle error classes: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kinds of AnswerDrift.
+const (
+	DriftNewForm         = "new_form"         // An answer form not seen before appeared
+	DriftDominantChanged = "dominant_changed" // The most common answer form changed
+)
+
+// AnswerDrift is a change in the answers a benchmark produces.
+type AnswerDrift struct {
+	ID           int64
+	Model        string
+	Benchmark    string
+	Kind         string // DriftNewForm or DriftDominantChanged
+	Hash         string // Fingerprint of the new answer form
+	Answer       string // Normalized new answer
+	PreviousHash string // Fingerprint of the previously dominant form, if any
+	Previous     string
+	Share        float64   // Share of recent answers in the new form (0.0-1.0)
+	OccurredAt   time.Time // Timestamp of the run that showed the change
+	DetectedAt   time.Time
+}
+
+// InsertAnswerDrift saves d unless it is already stored.
+func (s *Storage) InsertAnswerDrift(d AnswerDrift) (bool, error) {
+	query := `
+		INSERT INTO answer_drift
+			(model, benchmark, kind, hash, answer, previous_hash, previous, share, occurred_at, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (model, benchmark, kind, hash, occurred_at) DO NOTHING
+	`
+
+	var inserted int64
+	err := s.withRetry(func() error {
+		res, err := s.db.Exec(s.rebind(query), d.Model, d.Benchmark, d.Kind, d.Hash, d.Answer, d.PreviousHash, d.Previous,
+			d.Share, d.OccurredAt.UTC(), d.DetectedAt.UTC())
+		if err != nil {
+			return err
+		}
+		inserted, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to insert answer drift: %w", err)
+	}
+
+	return inserted > 0, nil
+}
+
+// ListAnswerDrift returns stored answer drift of model, newest first.
+func (s *Storage) ListAnswerDrift(model, benchmark string, since time.Time) ([]AnswerDrift, error) {
+	query := `
+		SELECT id, model, benchmark, kind, hash, answer, previous_hash, previous, share, occurred_at, detected_at
+		FROM answer_drift
+		WHERE occurred_at >= ?
+	`
+	args := []any{since.UTC()}
+	if model != "" {
+		query += ` AND model = ?`
+		args = append(args, model)
+	}
+	if benchmark != "" {
+		query += ` AND benchmark = ?`
+		args = append(args, benchmark)
+	}
+	query += ` ORDER BY occurred_at DESC, id DESC`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query answer drift: %w", err)
+	}
+	defer rows.Close()
+
+	var drift []AnswerDrift
+	for rows.Next() {
+		var d AnswerDrift
+		if err := rows.Scan(&d.ID, &d.Model, &d.Benchmark, &d.Kind, &d.Hash, &d.Answer, &d.PreviousHash, &d.Previous,
+			&d.Share, &d.OccurredAt, &d.DetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan answer drift: %w", err)
+		}
+		drift = append(drift, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query answer drift: %w", err)
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Flakiness is the last assessment of how erratically a benchmark passes
+// and fails.
+type Flakiness struct {
+	Model       string
+	Benchmark   string
+	Runs        int     // Results assessed
+	Alternation float64 // Share of consecutive results with a different outcome (0.0-1.0)
+	Entropy     float64 // Binary entropy of the outcomes in bits (0.0-1.0)
+	Flaky       bool
+	Quarantined bool      // Excluded from alerts and suite-wide aggregates
+	Since       time.Time // When Flaky last changed
+	UpdatedAt   time.Time
+}
+
+// SaveFlakiness stores the assessment of a benchmark, replacing the previous one.
+func (s *Storage) SaveFlakiness(f Flakiness) error {
+	query := `
+		INSERT INTO flakiness (model, benchmark, runs, alternation, entropy, flaky, quarantined, since, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (model, benchmark) DO UPDATE SET
+			runs = excluded.runs,
+			alternation = excluded.alternation,
+			entropy = excluded.entropy,
+			flaky = excluded.flaky,
+			quarantined = excluded.quarantined,
+			since = excluded.since,
+			updated_at = excluded.updated_at
+	`
+	err := s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), f.Model, f.Benchmark, f.Runs, f.Alternation, f.Entropy,
+			f.Flaky, f.Quarantined, f.Since.UTC(), f.UpdatedAt.UTC())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save flakiness: %w", err)
+	}
+	return nil
+}
+
+// ListFlakiness returns the last assessment of every benchmark of model, by name.
+func (s *Storage) ListFlakiness(model string) ([]Flakiness, error) {
+	query := `SELECT model, benchmark, runs, alternation, entropy, flaky, quarantined, since, updated_at FROM flakiness`
+	var args []any
+	if model != "" {
+		query += ` WHERE model = ?`
+		args = append(args, model)
+	}
+	query += ` ORDER BY benchmark, model`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query flakiness: %w", err)
+	}
+	defer rows.Close()
+
+	var assessments []Flakiness
+	for rows.Next() {
+		var f Flakiness
+		if err := rows.Scan(&f.Model, &f.Benchmark, &f.Runs, &f.Alternation, &f.Entropy, &f.Flaky, &f.Quarantined, &f.Since, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan flakiness: %w", err)
+		}
+		assessments = append(assessments, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query flakiness: %w", err)
+	}
+	return assessments, nil
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScopeSuite is the Incident scope for failures of the whole benchmark suite.
+// Other incidents are scoped to the benchmark they affect.
+const ScopeSuite = "suite"
+
+// Incident is a period during which a benchmark, or the suite as a whole,
+// kept failing.
+type Incident struct {
+	ID           int64
+	Model        string
+	Scope        string    // ScopeSuite or a benchmark name
+	StartedAt    time.Time // First failure of the streak that opened the incident
+	EndedAt      time.Time // First recovered result; zero while the incident is open
+	Benchmarks   []string  // Affected benchmarks
+	ErrorClasses map[string]int
+}
+
+// Open reports whether the incident is still ongoing.
+func (i Incident) Open() bool {
+	return i.EndedAt.IsZero()
+}
+
+// Duration returns how long the incident lasted, or has lasted until now.
+func (i Incident) Duration(now time.Time) time.Duration {
+	if i.Open() {
+		return now.Sub(i.StartedAt)
+	}
+	return i.EndedAt.Sub(i.StartedAt)
+}
+
+// IncidentEvent is an entry in the timeline of an incident.
+type IncidentEvent struct {
+	ID         int64
+	IncidentID int64
+	At         time.Time
+	Kind       string // "opened", "failing" or "resolved"
+	Message    string
+}
+
+// InsertIncident saves a new incident and returns its ID.
+func (s *Storage) InsertIncident(inc Incident) (int64, error) {
+	benchmarks, classes, err := encodeIncident(inc)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO incidents (model, scope, started_at, ended_at, benchmarks, error_classes)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	var id int64
+	err = s.withRetry(func() error {
+		return s.db.QueryRow(s.rebind(query), inc.Model, inc.Scope, inc.StartedAt.UTC(), nullTime(inc.EndedAt), benchmarks, classes).Scan(&id)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert incident: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateIncident saves the end, affected benchmarks and error classes of an
+// existing incident.
+func (s *Storage) UpdateIncident(inc Incident) error {
+	benchmarks, classes, err := encodeIncident(inc)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE incidents SET ended_at = ?, benchmarks = ?, error_classes = ? WHERE id = ?`
+	err = s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), nullTime(inc.EndedAt), benchmarks, classes, inc.ID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update incident: %w", err)
+	}
+	return nil
+}
+
+// GetIncident returns an incident, or ErrNotFound.
+func (s *Storage) GetIncident(id int64) (Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM incidents WHERE id = ?`
+
+	inc, err := scanIncident(s.db.QueryRow(s.rebind(query), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Incident{}, ErrNotFound
+	}
+	if err != nil {
+		return Incident{}, fmt.Errorf("failed to query incident: %w", err)
+	}
+	return inc, nil
+}
+
+// ListIncidents returns the incidents of model that were open at any time
+// since since, newest first.
+func (s *Storage) ListIncidents(model string, since time.Time) ([]Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM incidents
+		WHERE (ended_at IS NULL OR ended_at >= ?)
+	`
+	args := []any{since.UTC()}
+	if model != "" {
+		query += ` AND model = ?`
+		args = append(args, model)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []Incident
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		incidents = append(incidents, inc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// InsertIncidentEvent adds an entry to the timeline of an incident.
+func (s *Storage) InsertIncidentEvent(e IncidentEvent) error {
+	query := `INSERT INTO incident_events (incident_id, at, kind, message) VALUES (?, ?, ?, ?)`
+	err := s.withRetry(func() error {
+		_, err := s.db.Exec(s.rebind(query), e.IncidentID, e.At.UTC(), e.Kind, e.Message)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert incident event: %w", err)
+	}
+	return nil
+}
+
+// ListIncidentEvents returns the timeline of an incident, oldest first.
+func (s *Storage) ListIncidentEvents(incidentID int64) ([]IncidentEvent, error) {
+	query := `
+		SELECT id, incident_id, at, kind, message
+		FROM incident_events
+		WHERE incident_id = ?
+		ORDER BY at, id
+	`
+
+	rows, err := s.db.Query(s.rebind(query), incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incident events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []IncidentEvent
+	for rows.Next() {
+		var e IncidentEvent
+		if err := rows.Scan(&e.ID, &e.IncidentID, &e.At, &e.Kind, &e.Message); err != nil {
+			return nil, fmt.Errorf("failed to scan incident event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query incident events: %w", err)
+	}
+	return events, nil
+}
+
+// incidentColumns lists the incidents columns read by scanIncident, in order.
+const incidentColumns = `id, model, scope, started_at, ended_at, benchmarks, error_classes`
+
+func scanIncident(row rowScanner) (Incident, error) {
+	var inc Incident
+	var ended sql.NullTime
+	var benchmarks, classes string
+	if err := row.Scan(&inc.ID, &inc.Model, &inc.Scope, &inc.StartedAt, &ended, &benchmarks, &classes); err != nil {
+		return Incident{}, err
+	}
+	inc.EndedAt = ended.Time
+	if err := json.Unmarshal([]byte(benchmarks), &inc.Benchmarks); err != nil {
+		return Incident{}, fmt.Errorf("failed to decode incident benchmarks: %w", err)
+	}
+	if err := json.Unmarshal([]byte(classes), &inc.ErrorClasses); err != nil {
+		return Incident{}, fmt.Errorf("failed to decode incident error classes: %w", err)
+	}
+	if inc.ErrorClasses == nil {
+		inc.ErrorClasses = make(map[string]int)
+	}
+	return inc, nil
+}
+
+func encodeIncident(inc Incident) (benchmarks, classes string, err error) {
+	b, err := json.Marshal(inc.Benchmarks)
+	if err != nil {
+		return "", "", err
+	}
+	c, err := json.Marshal(inc.ErrorClasses)
+	if err != nil {
+		return "", "", err
+	}
+	return string(b), string(c), nil
+}
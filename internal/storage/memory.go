@@ -0,0 +1,522 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps all records in memory.
+// It is safe for concurrent use and is intended for tests.
+type MemoryStore struct {
+	mu           sync.Mutex
+	records      []BenchmarkRecord
+	changePoints []ChangePoint
+	baselines    map[string]Baseline
+	drift        []AnswerDrift
+	cycles       map[string]Cycle
+	flakiness    map[[2]string]Flakiness // By model and benchmark
+	alerts       map[[3]string]Alert     // By model, rule and benchmark
+	incidents    []Incident
+	events       []IncidentEvent
+	nextID       int64
+}
+
+// NewMemory returns an empty MemoryStore.
+func NewMemory() *MemoryStore {
+	return &MemoryStore{baselines: make(map[string]Baseline), cycles: make(map[string]Cycle), flakiness: make(map[[2]string]Flakiness), alerts: make(map[[3]string]Alert), nextID: 1}
+}
+
+// Ping implements Store. It always succeeds.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close implements Store. It is a no-op.
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// InsertRecord implements Store.
+func (m *MemoryStore) InsertRecord(record BenchmarkRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record.ID = m.nextID
+	record.Timestamp = record.Timestamp.UTC()
+	m.nextID++
+	m.records = append(m.records, record)
+	return nil
+}
+
+// GetRollingStats implements Store.
+func (m *MemoryStore) GetRollingStats(model, benchmarkName string, window int) (avgTokens, avgDuration, passRate float64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	recent := m.latest(model, benchmarkName, window)
+	if len(recent) == 0 {
+		return 0, 0, 0, nil
+	}
+
+	var tokens, durationMs, passed float64
+	for _, r := range recent {
+		tokens += float64(r.TokensUsed)
+		durationMs += float64(r.Duration.Milliseconds())
+		if r.Passed {
+			passed++
+		}
+	}
+
+	n := float64(len(recent))
+	return tokens / n, durationMs / n / 1000.0, passed / n, nil
+}
+
+// latest returns up to window records of model for a benchmark, newest
+// first, leaving out runs from outage cycles. The caller must hold m.mu.
+func (m *MemoryStore) latest(model, benchmarkName string, window int) []BenchmarkRecord {
+	var matching []BenchmarkRecord
+	for _, r := range m.records {
+		if r.Name == benchmarkName && (model == "" || r.Model == model) && m.cycles[r.RunID].Label != CycleOutage {
+			matching = append(matching, r)
+		}
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		if matching[i].Timestamp.Equal(matching[j].Timestamp) {
+			return matching[i].ID > matching[j].ID
+		}
+		return matching[i].Timestamp.After(matching[j].Timestamp)
+	})
+
+	if window >= 0 && len(matching) > window {
+		matching = matching[:window]
+	}
+	return matching
+}
+
+// ListRuns implements Store.
+func (m *MemoryStore) ListRuns(model string, limit int) ([]RunSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	byID := make(map[string]*RunSummary)
+	var runs []*RunSummary
+	for _, r := range m.records {
+		if r.RunID == "" || (model != "" && r.Model != model) {
+			continue
+		}
+
+		run, ok := byID[r.RunID]
+		if !ok {
+			run = &RunSummary{RunID: r.RunID, Model: r.Model, StartedAt: r.Timestamp, EndedAt: r.Timestamp}
+			byID[r.RunID] = run
+			runs = append(runs, run)
+		}
+		if r.Timestamp.Before(run.StartedAt) {
+			run.StartedAt = r.Timestamp
+		}
+		if r.Timestamp.After(run.EndedAt) {
+			run.EndedAt = r.Timestamp
+		}
+		run.Total++
+		if r.Passed {
+			run.Passed++
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+
+	var out []RunSummary
+	for _, run := range runs {
+		if len(out) == limit {
+			break
+		}
+		run.Label = m.cycles[run.RunID].Label
+		out = append(out, *run)
+	}
+	return out, nil
+}
+
+// GetRun implements Store.
+func (m *MemoryStore) GetRun(runID string) ([]BenchmarkRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var records []BenchmarkRecord
+	for _, r := range m.records {
+		if r.RunID == runID {
+			r.Transcript = nil
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// GetRecord implements Store.
+func (m *MemoryStore) GetRecord(id int64) (BenchmarkRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, r := range m.records {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return BenchmarkRecord{}, ErrNotFound
+}
+
+// ListRecords implements Store.
+func (m *MemoryStore) ListRecords(filter RecordFilter) ([]BenchmarkRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var records []BenchmarkRecord
+	for _, r := range m.records {
+		if filter.Name != "" && r.Name != filter.Name {
+			continue
+		}
+		if filter.Model != "" && r.Model != filter.Model {
+			continue
+		}
+		if !filter.Since.IsZero() && r.Timestamp.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !r.Timestamp.Before(filter.Until) {
+			continue
+		}
+		r.Transcript = nil
+		records = append(records, r)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].ID < records[j].ID
+		}
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
+
+// InsertChangePoint implements Store.
+func (m *MemoryStore) InsertChangePoint(cp ChangePoint) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.changePoints {
+		if existing.Model == cp.Model && existing.Benchmark == cp.Benchmark && existing.Metric == cp.Metric && existing.ChangeAt.Equal(cp.ChangeAt) {
+			return false, nil
+		}
+	}
+
+	cp.ID = int64(len(m.changePoints) + 1)
+	m.changePoints = append(m.changePoints, cp)
+	return true, nil
+}
+
+// ListChangePoints implements Store.
+func (m *MemoryStore) ListChangePoints(model, benchmark string, since time.Time) ([]ChangePoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var points []ChangePoint
+	for _, cp := range m.changePoints {
+		if (model == "" || cp.Model == model) && (benchmark == "" || cp.Benchmark == benchmark) && !cp.ChangeAt.Before(since) {
+			points = append(points, cp)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].ChangeAt.Equal(points[j].ChangeAt) {
+			return points[i].ID > points[j].ID
+		}
+		return points[i].ChangeAt.After(points[j].ChangeAt)
+	})
+	return points, nil
+}
+
+// PinBaseline implements Store.
+func (m *MemoryStore) PinBaseline(b Baseline) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b.RunIDs = append([]string(nil), b.RunIDs...)
+	m.baselines[b.Name] = b
+	return nil
+}
+
+// GetBaseline implements Store.
+func (m *MemoryStore) GetBaseline(name string) (Baseline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.baselines[name]
+	if !ok {
+		return Baseline{}, ErrNotFound
+	}
+	return b, nil
+}
+
+// ListBaselines implements Store.
+func (m *MemoryStore) ListBaselines() ([]Baseline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	baselines := make([]Baseline, 0, len(m.baselines))
+	for _, b := range m.baselines {
+		baselines = append(baselines, b)
+	}
+	sort.Slice(baselines, func(i, j int) bool { return baselines[i].Name < baselines[j].Name })
+	return baselines, nil
+}
+
+// InsertAnswerDrift implements Store.
+func (m *MemoryStore) InsertAnswerDrift(d AnswerDrift) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.drift {
+		if existing.Model == d.Model && existing.Benchmark == d.Benchmark && existing.Kind == d.Kind && existing.Hash == d.Hash && existing.OccurredAt.Equal(d.OccurredAt) {
+			return false, nil
+		}
+	}
+
+	d.ID = int64(len(m.drift) + 1)
+	m.drift = append(m.drift, d)
+	return true, nil
+}
+
+// ListAnswerDrift implements Store.
+func (m *MemoryStore) ListAnswerDrift(model, benchmark string, since time.Time) ([]AnswerDrift, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var drift []AnswerDrift
+	for _, d := range m.drift {
+		if (model == "" || d.Model == model) && (benchmark == "" || d.Benchmark == benchmark) && !d.OccurredAt.Before(since) {
+			drift = append(drift, d)
+		}
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].OccurredAt.Equal(drift[j].OccurredAt) {
+			return drift[i].ID > drift[j].ID
+		}
+		return drift[i].OccurredAt.After(drift[j].OccurredAt)
+	})
+	return drift, nil
+}
+
+// InsertIncident implements Store.
+func (m *MemoryStore) InsertIncident(inc Incident) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inc = copyIncident(inc)
+	inc.ID = int64(len(m.incidents) + 1)
+	m.incidents = append(m.incidents, inc)
+	return inc.ID, nil
+}
+
+// UpdateIncident implements Store.
+func (m *MemoryStore) UpdateIncident(inc Incident) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.incidents {
+		if existing.ID == inc.ID {
+			existing.EndedAt = inc.EndedAt.UTC()
+			existing.Benchmarks = inc.Benchmarks
+			existing.ErrorClasses = inc.ErrorClasses
+			m.incidents[i] = copyIncident(existing)
+			return nil
+		}
+	}
+	return nil
+}
+
+// GetIncident implements Store.
+func (m *MemoryStore) GetIncident(id int64) (Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, inc := range m.incidents {
+		if inc.ID == id {
+			return copyIncident(inc), nil
+		}
+	}
+	return Incident{}, ErrNotFound
+}
+
+// ListIncidents implements Store.
+func (m *MemoryStore) ListIncidents(model string, since time.Time) ([]Incident, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var incidents []Incident
+	for _, inc := range m.incidents {
+		if (model == "" || inc.Model == model) && (inc.Open() || !inc.EndedAt.Before(since)) {
+			incidents = append(incidents, copyIncident(inc))
+		}
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		if incidents[i].StartedAt.Equal(incidents[j].StartedAt) {
+			return incidents[i].ID > incidents[j].ID
+		}
+		return incidents[i].StartedAt.After(incidents[j].StartedAt)
+	})
+	return incidents, nil
+}
+
+// InsertIncidentEvent implements Store.
+func (m *MemoryStore) InsertIncidentEvent(e IncidentEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.ID = int64(len(m.events) + 1)
+	e.At = e.At.UTC()
+	m.events = append(m.events, e)
+	return nil
+}
+
+// ListIncidentEvents implements Store.
+func (m *MemoryStore) ListIncidentEvents(incidentID int64) ([]IncidentEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []IncidentEvent
+	for _, e := range m.events {
+		if e.IncidentID == incidentID {
+			events = append(events, e)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+// copyIncident returns inc with its own copies of the slice and map, in UTC.
+func copyIncident(inc Incident) Incident {
+	inc.StartedAt = inc.StartedAt.UTC()
+	if !inc.EndedAt.IsZero() {
+		inc.EndedAt = inc.EndedAt.UTC()
+	}
+	inc.Benchmarks = append([]string(nil), inc.Benchmarks...)
+	classes := make(map[string]int, len(inc.ErrorClasses))
+	for class, n := range inc.ErrorClasses {
+		classes[class] = n
+	}
+	inc.ErrorClasses = classes
+	return inc
+}
+
+// SaveCycle implements Store.
+func (m *MemoryStore) SaveCycle(c Cycle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c.StartedAt = c.StartedAt.UTC()
+	m.cycles[c.RunID] = c
+	return nil
+}
+
+// GetCycle implements Store.
+func (m *MemoryStore) GetCycle(runID string) (Cycle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.cycles[runID]
+	if !ok {
+		return Cycle{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// ListCycles implements Store.
+func (m *MemoryStore) ListCycles(model string, since time.Time) ([]Cycle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cycles []Cycle
+	for _, c := range m.cycles {
+		if (model == "" || c.Model == model) && !c.StartedAt.Before(since) {
+			cycles = append(cycles, c)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].StartedAt.After(cycles[j].StartedAt) })
+	return cycles, nil
+}
+
+// SaveFlakiness implements Store.
+func (m *MemoryStore) SaveFlakiness(f Flakiness) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f.Since, f.UpdatedAt = f.Since.UTC(), f.UpdatedAt.UTC()
+	m.flakiness[[2]string{f.Model, f.Benchmark}] = f
+	return nil
+}
+
+// ListFlakiness implements Store.
+func (m *MemoryStore) ListFlakiness(model string) ([]Flakiness, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assessments := make([]Flakiness, 0, len(m.flakiness))
+	for _, f := range m.flakiness {
+		if model == "" || f.Model == model {
+			assessments = append(assessments, f)
+		}
+	}
+	sort.Slice(assessments, func(i, j int) bool {
+		if assessments[i].Benchmark != assessments[j].Benchmark {
+			return assessments[i].Benchmark < assessments[j].Benchmark
+		}
+		return assessments[i].Model < assessments[j].Model
+	})
+	return assessments, nil
+}
+
+// SaveAlert implements Store.
+func (m *MemoryStore) SaveAlert(a Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	a.Since, a.NotifiedAt, a.Flips = a.Since.UTC(), a.NotifiedAt.UTC(), utcTimes(a.Flips)
+	m.alerts[[3]string{a.Model, a.Rule, a.Benchmark}] = a
+	return nil
+}
+
+// ListAlerts implements Store.
+func (m *MemoryStore) ListAlerts(model string) ([]Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(m.alerts))
+	for _, a := range m.alerts {
+		if model != "" && a.Model != model {
+			continue
+		}
+		a.Flips = slices.Clone(a.Flips)
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		if alerts[i].Benchmark != alerts[j].Benchmark {
+			return alerts[i].Benchmark < alerts[j].Benchmark
+		}
+		return alerts[i].Model < alerts[j].Model
+	})
+	return alerts, nil
+}
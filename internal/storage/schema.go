@@ -0,0 +1,547 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is a single schema change, written once per SQL dialect.
+type migration struct {
+	sqlite   string
+	postgres string
+}
+
+// migrations are applied in order; the schema version of a database is the
+// number of migrations applied to it. Never edit or reorder an entry once it
+// has shipped — append a new one instead.
+var migrations = []migration{
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS benchmarks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	passed BOOLEAN NOT NULL,
+	tokens_used INTEGER NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	quote TEXT NOT NULL,
+	output TEXT,
+	timestamp DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_benchmarks_name ON benchmarks(name);
+CREATE INDEX IF NOT EXISTS idx_benchmarks_timestamp ON benchmarks(timestamp);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS benchmarks (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	passed BOOLEAN NOT NULL,
+	tokens_used INTEGER NOT NULL,
+	duration_ms BIGINT NOT NULL,
+	quote TEXT NOT NULL,
+	output TEXT,
+	timestamp TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_benchmarks_name ON benchmarks(name);
+CREATE INDEX IF NOT EXISTS idx_benchmarks_timestamp ON benchmarks(timestamp);
+`,
+	},
+	{
+		sqlite: `
+ALTER TABLE benchmarks ADD COLUMN run_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_benchmarks_run_id ON benchmarks(run_id);
+`,
+		postgres: `
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS run_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_benchmarks_run_id ON benchmarks(run_id);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS transcript_blobs (
+	hash TEXT PRIMARY KEY,
+	size INTEGER NOT NULL,
+	data BLOB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transcripts (
+	record_id INTEGER PRIMARY KEY REFERENCES benchmarks(id),
+	argv TEXT NOT NULL,
+	prompt_hash TEXT NOT NULL,
+	stdout_hash TEXT NOT NULL,
+	stderr_hash TEXT NOT NULL,
+	events_hash TEXT NOT NULL
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS transcript_blobs (
+	hash TEXT PRIMARY KEY,
+	size BIGINT NOT NULL,
+	data BYTEA NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transcripts (
+	record_id BIGINT PRIMARY KEY REFERENCES benchmarks(id),
+	argv TEXT NOT NULL,
+	prompt_hash TEXT NOT NULL,
+	stdout_hash TEXT NOT NULL,
+	stderr_hash TEXT NOT NULL,
+	events_hash TEXT NOT NULL
+);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS change_points (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	benchmark TEXT NOT NULL,
+	metric TEXT NOT NULL,
+	change_at DATETIME NOT NULL,
+	detected_at DATETIME NOT NULL,
+	before_mean REAL NOT NULL,
+	after_mean REAL NOT NULL,
+	before_runs INTEGER NOT NULL,
+	after_runs INTEGER NOT NULL,
+	confidence REAL NOT NULL,
+	UNIQUE (benchmark, metric, change_at)
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS change_points (
+	id BIGSERIAL PRIMARY KEY,
+	benchmark TEXT NOT NULL,
+	metric TEXT NOT NULL,
+	change_at TIMESTAMPTZ NOT NULL,
+	detected_at TIMESTAMPTZ NOT NULL,
+	before_mean DOUBLE PRECISION NOT NULL,
+	after_mean DOUBLE PRECISION NOT NULL,
+	before_runs INTEGER NOT NULL,
+	after_runs INTEGER NOT NULL,
+	confidence DOUBLE PRECISION NOT NULL,
+	UNIQUE (benchmark, metric, change_at)
+);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS baselines (
+	name TEXT PRIMARY KEY,
+	since DATETIME,
+	until DATETIME,
+	run_ids TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS baselines (
+	name TEXT PRIMARY KEY,
+	since TIMESTAMPTZ,
+	until TIMESTAMPTZ,
+	run_ids TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+`,
+	},
+	{
+		sqlite: `
+ALTER TABLE benchmarks ADD COLUMN effort_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE benchmarks ADD COLUMN effort TEXT NOT NULL DEFAULT '';
+`,
+		postgres: `
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS effort_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS effort TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		// Every earlier result was run against the then hardcoded Sonnet model
+		sqlite: `
+ALTER TABLE benchmarks ADD COLUMN model TEXT NOT NULL DEFAULT '';
+UPDATE benchmarks SET model = 'Sonnet';
+CREATE INDEX IF NOT EXISTS idx_benchmarks_model ON benchmarks(model);
+`,
+		postgres: `
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE benchmarks SET model = 'Sonnet';
+CREATE INDEX IF NOT EXISTS idx_benchmarks_model ON benchmarks(model);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS answer_drift (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	benchmark TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	answer TEXT NOT NULL,
+	previous_hash TEXT NOT NULL,
+	previous TEXT NOT NULL,
+	share REAL NOT NULL,
+	occurred_at DATETIME NOT NULL,
+	detected_at DATETIME NOT NULL,
+	UNIQUE (benchmark, kind, hash, occurred_at)
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS answer_drift (
+	id BIGSERIAL PRIMARY KEY,
+	benchmark TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	answer TEXT NOT NULL,
+	previous_hash TEXT NOT NULL,
+	previous TEXT NOT NULL,
+	share DOUBLE PRECISION NOT NULL,
+	occurred_at TIMESTAMPTZ NOT NULL,
+	detected_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (benchmark, kind, hash, occurred_at)
+);
+`,
+	},
+	{
+		// Before error classes, a timeout was only recorded in the output
+		sqlite: `
+ALTER TABLE benchmarks ADD COLUMN error_class TEXT NOT NULL DEFAULT '';
+UPDATE benchmarks SET error_class = 'timeout' WHERE NOT passed AND output = 'Timed out';
+UPDATE benchmarks SET error_class = 'unknown' WHERE NOT passed AND error_class = '';
+
+CREATE TABLE IF NOT EXISTS incidents (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	scope TEXT NOT NULL,
+	started_at DATETIME NOT NULL,
+	ended_at DATETIME,
+	benchmarks TEXT NOT NULL,
+	error_classes TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_incidents_scope ON incidents(scope, ended_at);
+
+CREATE TABLE IF NOT EXISTS incident_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	incident_id INTEGER NOT NULL REFERENCES incidents(id),
+	at DATETIME NOT NULL,
+	kind TEXT NOT NULL,
+	message TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_incident_events_incident ON incident_events(incident_id);
+`,
+		postgres: `
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS error_class TEXT NOT NULL DEFAULT '';
+UPDATE benchmarks SET error_class = 'timeout' WHERE NOT passed AND output = 'Timed out';
+UPDATE benchmarks SET error_class = 'unknown' WHERE NOT passed AND error_class = '';
+
+CREATE TABLE IF NOT EXISTS incidents (
+	id BIGSERIAL PRIMARY KEY,
+	scope TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	ended_at TIMESTAMPTZ,
+	benchmarks TEXT NOT NULL,
+	error_classes TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_incidents_scope ON incidents(scope, ended_at);
+
+CREATE TABLE IF NOT EXISTS incident_events (
+	id BIGSERIAL PRIMARY KEY,
+	incident_id BIGINT NOT NULL REFERENCES incidents(id),
+	at TIMESTAMPTZ NOT NULL,
+	kind TEXT NOT NULL,
+	message TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_incident_events_incident ON incident_events(incident_id);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS cycles (
+	run_id TEXT PRIMARY KEY,
+	started_at DATETIME NOT NULL,
+	label TEXT NOT NULL,
+	total INTEGER NOT NULL,
+	failed INTEGER NOT NULL,
+	error_classes TEXT NOT NULL,
+	reason TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cycles_label ON cycles(label);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS cycles (
+	run_id TEXT PRIMARY KEY,
+	started_at TIMESTAMPTZ NOT NULL,
+	label TEXT NOT NULL,
+	total INTEGER NOT NULL,
+	failed INTEGER NOT NULL,
+	error_classes TEXT NOT NULL,
+	reason TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_cycles_label ON cycles(label);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS flakiness (
+	benchmark TEXT PRIMARY KEY,
+	runs INTEGER NOT NULL,
+	alternation REAL NOT NULL,
+	entropy REAL NOT NULL,
+	flaky BOOLEAN NOT NULL,
+	quarantined BOOLEAN NOT NULL,
+	since DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS flakiness (
+	benchmark TEXT PRIMARY KEY,
+	runs INTEGER NOT NULL,
+	alternation DOUBLE PRECISION NOT NULL,
+	entropy DOUBLE PRECISION NOT NULL,
+	flaky BOOLEAN NOT NULL,
+	quarantined BOOLEAN NOT NULL,
+	since TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+`,
+	},
+	{
+		sqlite: `
+CREATE TABLE IF NOT EXISTS alerts (
+	rule TEXT NOT NULL,
+	benchmark TEXT NOT NULL,
+	state TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	since DATETIME NOT NULL,
+	flips TEXT NOT NULL,
+	flapping BOOLEAN NOT NULL,
+	notified TEXT NOT NULL,
+	notified_severity TEXT NOT NULL,
+	notified_at DATETIME,
+	PRIMARY KEY (rule, benchmark)
+);
+`,
+		postgres: `
+CREATE TABLE IF NOT EXISTS alerts (
+	rule TEXT NOT NULL,
+	benchmark TEXT NOT NULL,
+	state TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	since TIMESTAMPTZ NOT NULL,
+	flips TEXT NOT NULL,
+	flapping BOOLEAN NOT NULL,
+	notified TEXT NOT NULL,
+	notified_severity TEXT NOT NULL,
+	notified_at TIMESTAMPTZ,
+	PRIMARY KEY (rule, benchmark)
+);
+`,
+	},
+	{
+		// Scope derived state by model, so daemons benchmarking different
+		// models can share a database. Earlier state came from a single
+		// daemon: it is attributed to the model of the latest result, and
+		// cycles to the model of their own results. SQLite cannot change a
+		// table's keys, so the keyed tables are rebuilt.
+		sqlite: `
+CREATE TABLE change_points_by_model (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	model TEXT NOT NULL DEFAULT '',
+	benchmark TEXT NOT NULL,
+	metric TEXT NOT NULL,
+	change_at DATETIME NOT NULL,
+	detected_at DATETIME NOT NULL,
+	before_mean REAL NOT NULL,
+	after_mean REAL NOT NULL,
+	before_runs INTEGER NOT NULL,
+	after_runs INTEGER NOT NULL,
+	confidence REAL NOT NULL,
+	UNIQUE (model, benchmark, metric, change_at)
+);
+INSERT INTO change_points_by_model
+	SELECT id, COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), ''),
+		benchmark, metric, change_at, detected_at, before_mean, after_mean, before_runs, after_runs, confidence
+	FROM change_points;
+DROP TABLE change_points;
+ALTER TABLE change_points_by_model RENAME TO change_points;
+
+CREATE TABLE answer_drift_by_model (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	model TEXT NOT NULL DEFAULT '',
+	benchmark TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	hash TEXT NOT NULL,
+	answer TEXT NOT NULL,
+	previous_hash TEXT NOT NULL,
+	previous TEXT NOT NULL,
+	share REAL NOT NULL,
+	occurred_at DATETIME NOT NULL,
+	detected_at DATETIME NOT NULL,
+	UNIQUE (model, benchmark, kind, hash, occurred_at)
+);
+INSERT INTO answer_drift_by_model
+	SELECT id, COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), ''),
+		benchmark, kind, hash, answer, previous_hash, previous, share, occurred_at, detected_at
+	FROM answer_drift;
+DROP TABLE answer_drift;
+ALTER TABLE answer_drift_by_model RENAME TO answer_drift;
+
+CREATE TABLE flakiness_by_model (
+	model TEXT NOT NULL DEFAULT '',
+	benchmark TEXT NOT NULL,
+	runs INTEGER NOT NULL,
+	alternation REAL NOT NULL,
+	entropy REAL NOT NULL,
+	flaky BOOLEAN NOT NULL,
+	quarantined BOOLEAN NOT NULL,
+	since DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (model, benchmark)
+);
+INSERT INTO flakiness_by_model
+	SELECT COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), ''),
+		benchmark, runs, alternation, entropy, flaky, quarantined, since, updated_at
+	FROM flakiness;
+DROP TABLE flakiness;
+ALTER TABLE flakiness_by_model RENAME TO flakiness;
+
+CREATE TABLE alerts_by_model (
+	model TEXT NOT NULL DEFAULT '',
+	rule TEXT NOT NULL,
+	benchmark TEXT NOT NULL,
+	state TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	since DATETIME NOT NULL,
+	flips TEXT NOT NULL,
+	flapping BOOLEAN NOT NULL,
+	notified TEXT NOT NULL,
+	notified_severity TEXT NOT NULL,
+	notified_at DATETIME,
+	PRIMARY KEY (model, rule, benchmark)
+);
+INSERT INTO alerts_by_model
+	SELECT COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), ''),
+		rule, benchmark, state, severity, summary, since, flips, flapping, notified, notified_severity, notified_at
+	FROM alerts;
+DROP TABLE alerts;
+ALTER TABLE alerts_by_model RENAME TO alerts;
+
+ALTER TABLE cycles ADD COLUMN model TEXT NOT NULL DEFAULT '';
+UPDATE cycles SET model = COALESCE((SELECT MIN(model) FROM benchmarks WHERE benchmarks.run_id = cycles.run_id), '');
+
+ALTER TABLE incidents ADD COLUMN model TEXT NOT NULL DEFAULT '';
+UPDATE incidents SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+CREATE INDEX IF NOT EXISTS idx_incidents_model ON incidents(model, ended_at);
+`,
+		postgres: `
+ALTER TABLE change_points ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE change_points SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+ALTER TABLE change_points DROP CONSTRAINT IF EXISTS change_points_benchmark_metric_change_at_key;
+ALTER TABLE change_points ADD UNIQUE (model, benchmark, metric, change_at);
+
+ALTER TABLE answer_drift ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE answer_drift SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+ALTER TABLE answer_drift DROP CONSTRAINT IF EXISTS answer_drift_benchmark_kind_hash_occurred_at_key;
+ALTER TABLE answer_drift ADD UNIQUE (model, benchmark, kind, hash, occurred_at);
+
+ALTER TABLE flakiness ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE flakiness SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+ALTER TABLE flakiness DROP CONSTRAINT IF EXISTS flakiness_pkey;
+ALTER TABLE flakiness ADD PRIMARY KEY (model, benchmark);
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE alerts SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_pkey;
+ALTER TABLE alerts ADD PRIMARY KEY (model, rule, benchmark);
+
+ALTER TABLE cycles ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE cycles SET model = COALESCE((SELECT MIN(model) FROM benchmarks WHERE benchmarks.run_id = cycles.run_id), '');
+
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT '';
+UPDATE incidents SET model = COALESCE((SELECT model FROM benchmarks ORDER BY id DESC LIMIT 1), '');
+CREATE INDEX IF NOT EXISTS idx_incidents_model ON incidents(model, ended_at);
+`,
+	},
+}
+
+// SchemaVersion is the schema version written by this build of Ripley.
+var SchemaVersion = len(migrations)
+
+// migrate brings the database schema up to SchemaVersion.
+func (s *Storage) migrate() error {
+	err := s.withRetry(func() error {
+		_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	current, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", current, len(migrations))
+	}
+
+	for v := current; v < len(migrations); v++ {
+		stmt := migrations[v].sqlite
+		if s.dialect == dialectPostgres {
+			stmt = migrations[v].postgres
+		}
+
+		version := v + 1
+		if err := s.withRetry(func() error { return s.applyMigration(version, stmt) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs stmt and records version in a single transaction.
+func (s *Storage) applyMigration(version int, stmt string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(stmt); err != nil {
+		return fmt.Errorf("failed to apply migration %d: %w", version, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_version`); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", version, err)
+	}
+	if _, err := tx.Exec(s.rebind(`INSERT INTO schema_version (version) VALUES (?)`), version); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", version, err)
+	}
+	return nil
+}
+
+// schemaVersion returns the number of migrations applied to the database.
+func (s *Storage) schemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// checkVersion verifies that the database schema matches this build, which
+// read-only stores rely on because they cannot migrate.
+func (s *Storage) checkVersion() error {
+	version, err := s.schemaVersion()
+	if err != nil {
+		return err
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("database schema version %d does not match supported version %d; start ripleyd once to migrate it", version, SchemaVersion)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Spool is a Store that does not lose results when the underlying store is
+// unavailable. Records that fail to insert are appended to a local JSONL
+// file and replayed, in their original order, before the next insert and
+// when the spool is opened. Reads go straight to the underlying store.
+//
+// Records the underlying store rejects for good (see ErrRejected) are moved
+// to a file next to the spool, with the suffix ".rejected", so that they do
+// not hold up the records behind them.
+type Spool struct {
+	Store
+
+	path     string
+	mu       sync.Mutex
+	pending  int
+	failures int
+}
+
+// NewSpool wraps store with a spool file at path and replays any records
+// left over from a previous run. A failed replay is not an error: the
+// records stay spooled and are retried on the next insert.
+func NewSpool(store Store, path string) (*Spool, error) {
+	s := &Spool{Store: store, path: path}
+
+	lines, err := s.readLines()
+	if err != nil {
+		return nil, err
+	}
+	s.pending = len(lines)
+
+	if s.pending > 0 {
+		slog.Warn("Spooled records found, replaying", "path", path, "pending", s.pending)
+		if _, err := s.Replay(); err != nil {
+			slog.Warn("Spool replay failed", "path", path, "pending", s.Pending(), "error", err)
+		}
+	}
+
+	return s, nil
+}
+
+// Pending returns the number of records waiting in the spool file.
+func (s *Spool) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Failures returns the number of inserts and replays the underlying store has
+// rejected since the spool was opened.
+func (s *Spool) Failures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failures
+}
+
+// InsertRecord stores record, spooling it if the underlying store fails, or
+// setting it aside if the store rejects it. It only returns an error if the
+// record could neither be stored nor written to either file.
+func (s *Spool) InsertRecord(record BenchmarkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending > 0 {
+		if _, err := s.replayLocked(); err != nil {
+			slog.Warn("Spool replay failed", "path", s.path, "pending", s.pending, "error", err)
+		}
+	}
+
+	// Keep records in order: while older records are still spooled the new
+	// one has to queue up behind them.
+	if s.pending == 0 {
+		err := s.Store.InsertRecord(record)
+		if err == nil {
+			return nil
+		}
+		s.failures++
+		if errors.Is(err, ErrRejected) {
+			line, merr := json.Marshal(record)
+			if merr != nil {
+				return fmt.Errorf("failed to set aside rejected record: %w", merr)
+			}
+			return s.reject(line, err)
+		}
+		slog.Warn("Failed to store result, spooling it", "run_id", record.RunID, "benchmark", record.Name, "path", s.path, "error", err)
+	}
+
+	if err := s.appendLocked(record); err != nil {
+		return fmt.Errorf("failed to spool record: %w", err)
+	}
+	return nil
+}
+
+// Replay inserts spooled records into the underlying store in order, stopping
+// at the first failure other than a rejection. It returns the number of
+// records replayed. The spool file is rewritten after every record, so the
+// records replayed before a failure are not replayed again.
+func (s *Spool) Replay() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replayLocked()
+}
+
+func (s *Spool) replayLocked() (int, error) {
+	lines, err := s.readLines()
+	if err != nil {
+		return 0, err
+	}
+	s.pending = len(lines)
+
+	replayed := 0
+	var insertErr error
+	for len(lines) > 0 {
+		var record BenchmarkRecord
+		if err := json.Unmarshal(lines[0], &record); err != nil {
+			// A torn line from a crash mid-append cannot be recovered
+			slog.Warn("Dropping corrupt spool entry", "path", s.path, "error", err)
+		} else if err := s.Store.InsertRecord(record); errors.Is(err, ErrRejected) {
+			s.failures++
+			if err := s.reject(lines[0], err); err != nil {
+				insertErr = err
+				break
+			}
+		} else if err != nil {
+			s.failures++
+			insertErr = err
+			break
+		} else {
+			replayed++
+		}
+
+		if err := s.rewrite(lines[1:]); err != nil {
+			return replayed, err
+		}
+		lines = lines[1:]
+		s.pending = len(lines)
+	}
+
+	if replayed > 0 {
+		slog.Info("Replayed spooled records", "path", s.path, "replayed", replayed, "pending", s.pending)
+	}
+	return replayed, insertErr
+}
+
+// reject appends the spool line of a record the underlying store rejected to
+// the rejected file.
+func (s *Spool) reject(line []byte, cause error) error {
+	path := s.path + ".rejected"
+	if err := appendLine(path, line); err != nil {
+		return fmt.Errorf("failed to set aside rejected record: %w", err)
+	}
+	slog.Error("Result rejected by the database, set aside", "path", path, "error", cause)
+	return nil
+}
+
+// appendLocked adds record to the end of the spool file and syncs it to disk.
+func (s *Spool) appendLocked(record BenchmarkRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := appendLine(s.path, line); err != nil {
+		return err
+	}
+
+	s.pending++
+	return nil
+}
+
+// appendLine adds line to the end of the file at path and syncs it to disk.
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readLines returns the non-empty lines of the spool file.
+func (s *Spool) readLines() ([][]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lines = append(lines, append([]byte(nil), line...))
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// rewrite atomically replaces the spool file with lines, removing it when
+// nothing is left.
+func (s *Spool) rewrite(lines [][]byte) error {
+	if len(lines) == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear spool: %w", err)
+		}
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to rewrite spool: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, line := range lines {
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to rewrite spool: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to rewrite spool: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to rewrite spool: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to rewrite spool: %w", err)
+	}
+	return nil
+}
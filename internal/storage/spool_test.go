@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyStore is a MemoryStore whose inserts fail while down is set or for
+// the benchmark failOn, and which always rejects the records of the
+// benchmarks in reject.
+type flakyStore struct {
+	*MemoryStore
+	down   bool
+	failOn string
+	reject map[string]bool
+}
+
+func (f *flakyStore) InsertRecord(record BenchmarkRecord) error {
+	if f.down || record.Name == f.failOn {
+		return errors.New("disk full")
+	}
+	if f.reject[record.Name] {
+		return fmt.Errorf("%w: CHECK constraint failed", ErrRejected)
+	}
+	return f.MemoryStore.InsertRecord(record)
+}
+
+func TestSpoolBuffersFailedInserts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+	backend := &flakyStore{MemoryStore: NewMemory(), down: true}
+
+	spool, err := NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+
+	base := time.Now()
+	for i, name := range []string{"A", "B"} {
+		rec := BenchmarkRecord{RunID: "run-1", Name: name, Passed: true, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := spool.InsertRecord(rec); err != nil {
+			t.Fatalf("Expected spooled insert to succeed, got %v", err)
+		}
+	}
+
+	if spool.Pending() != 2 {
+		t.Errorf("Expected 2 pending records, got %d", spool.Pending())
+	}
+	// The failed insert of A, then the failed replay of A before B
+	if spool.Failures() != 2 {
+		t.Errorf("Expected 2 write failures, got %d", spool.Failures())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected spool file to exist: %v", err)
+	}
+
+	// The next successful write replays the backlog first
+	backend.down = false
+	if err := spool.InsertRecord(BenchmarkRecord{RunID: "run-1", Name: "C", Passed: true, Timestamp: base.Add(2 * time.Second)}); err != nil {
+		t.Fatalf("Failed to insert record: %v", err)
+	}
+
+	if spool.Pending() != 0 {
+		t.Errorf("Expected no pending records, got %d", spool.Pending())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected spool file to be removed, got %v", err)
+	}
+
+	records, err := spool.GetRun("run-1")
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if len(records) != 3 || records[0].Name != "A" || records[1].Name != "B" || records[2].Name != "C" {
+		t.Errorf("Expected records A, B, C in order, got %+v", records)
+	}
+}
+
+func TestSpoolReplaysOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+
+	down := &flakyStore{MemoryStore: NewMemory(), down: true}
+	spool, err := NewSpool(down, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	rec := BenchmarkRecord{RunID: "run-1", Name: "A", Passed: true, TokensUsed: 7, Duration: 1500 * time.Millisecond, Timestamp: time.Now()}
+	if err := spool.InsertRecord(rec); err != nil {
+		t.Fatalf("Failed to spool record: %v", err)
+	}
+
+	// A fresh process finds the spool file and replays it
+	backend := NewMemory()
+	spool, err = NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to reopen spool: %v", err)
+	}
+	if spool.Pending() != 0 {
+		t.Errorf("Expected no pending records after startup replay, got %d", spool.Pending())
+	}
+
+	records, _ := backend.GetRun("run-1")
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 replayed record, got %d", len(records))
+	}
+	if records[0].TokensUsed != 7 || records[0].Duration != 1500*time.Millisecond {
+		t.Errorf("Replayed record does not match original: %+v", records[0])
+	}
+}
+
+func TestSpoolKeepsRecordsWhileStoreIsDown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+	backend := &flakyStore{MemoryStore: NewMemory(), down: true}
+
+	spool, err := NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := spool.InsertRecord(BenchmarkRecord{Name: "A", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("Failed to spool record: %v", err)
+		}
+	}
+
+	n, err := spool.Replay()
+	if err == nil || n != 0 {
+		t.Errorf("Expected replay to fail without progress, got n=%d err=%v", n, err)
+	}
+	if spool.Pending() != 3 {
+		t.Errorf("Expected 3 pending records, got %d", spool.Pending())
+	}
+}
+
+func TestSpoolDropsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+	content := `{"Name":"A","Passed":true,"Timestamp":"2025-01-01T00:00:00Z"}
+{"Name":"B","Pas
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewMemory()
+	spool, err := NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	if spool.Pending() != 0 {
+		t.Errorf("Expected no pending records, got %d", spool.Pending())
+	}
+
+	avgTokens, _, passRate, _ := backend.GetRollingStats("", "A", 10)
+	if passRate != 1.0 || avgTokens != 0 {
+		t.Errorf("Expected the valid record to be replayed, got passRate=%.2f", passRate)
+	}
+}
+
+func TestSpoolSetsAsideRejectedRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+	backend := &flakyStore{MemoryStore: NewMemory(), down: true, reject: map[string]bool{"Bad": true}}
+
+	spool, err := NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	base := time.Now()
+	for i, name := range []string{"A", "Bad", "B"} {
+		rec := BenchmarkRecord{RunID: "run-1", Name: name, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := spool.InsertRecord(rec); err != nil {
+			t.Fatalf("Failed to spool record: %v", err)
+		}
+	}
+
+	// The rejected record must not hold up the ones behind it
+	backend.down = false
+	n, err := spool.Replay()
+	if err != nil || n != 2 {
+		t.Errorf("Expected 2 replayed records, got n=%d err=%v", n, err)
+	}
+	if spool.Pending() != 0 {
+		t.Errorf("Expected no pending records, got %d", spool.Pending())
+	}
+
+	// Rejected on a direct insert too
+	if err := spool.InsertRecord(BenchmarkRecord{RunID: "run-2", Name: "Bad", Timestamp: base}); err != nil {
+		t.Fatalf("Expected the rejected record to be set aside, got %v", err)
+	}
+
+	records, _ := spool.GetRun("run-1")
+	if len(records) != 2 || records[0].Name != "A" || records[1].Name != "B" {
+		t.Errorf("Expected records A and B, got %+v", records)
+	}
+	data, err := os.ReadFile(path + ".rejected")
+	if err != nil {
+		t.Fatalf("Failed to read rejected records: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 || !strings.Contains(string(data), `"Name":"Bad"`) {
+		t.Errorf("Expected the 2 rejected records, got %q", data)
+	}
+}
+
+func TestSpoolReplayDoesNotRepeatRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.spool.jsonl")
+	backend := &flakyStore{MemoryStore: NewMemory(), down: true}
+
+	spool, err := NewSpool(backend, path)
+	if err != nil {
+		t.Fatalf("Failed to create spool: %v", err)
+	}
+	for _, name := range []string{"A", "B", "C", "D"} {
+		if err := spool.InsertRecord(BenchmarkRecord{RunID: "run-1", Name: name, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("Failed to spool record: %v", err)
+		}
+	}
+
+	// A is stored and B rejected before the store fails again on C
+	backend.down = false
+	backend.reject = map[string]bool{"B": true}
+	backend.failOn = "C"
+	if n, err := spool.Replay(); err == nil || n != 1 {
+		t.Fatalf("Expected the replay to stop at C after 1 record, got n=%d err=%v", n, err)
+	}
+	if spool.Pending() != 2 {
+		t.Errorf("Expected C and D to stay spooled, got %d pending", spool.Pending())
+	}
+
+	backend.failOn = ""
+	if n, err := spool.Replay(); err != nil || n != 2 {
+		t.Fatalf("Expected C and D to be replayed, got n=%d err=%v", n, err)
+	}
+	records, _ := backend.GetRun("run-1")
+	if len(records) != 3 || records[0].Name != "A" || records[1].Name != "C" || records[2].Name != "D" {
+		t.Errorf("Expected A, C and D stored once each, got %+v", records)
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteBusyTimeout is how long a SQLite connection waits for a competing
+// lock to clear before giving up with "database is locked".
+const sqliteBusyTimeout = 5 * time.Second
+
+// Locked writes are retried with exponential backoff starting at
+// writeRetryDelay, for at most writeRetries attempts.
+const (
+	writeRetries    = 5
+	writeRetryDelay = 50 * time.Millisecond
+)
+
+// ErrReadOnly is returned when writing through a store opened read-only.
+var ErrReadOnly = errors.New("storage is read-only")
+
+// sqliteDSN builds the go-sqlite3 connection string for path.
+//
+// Writable databases use WAL journaling so that reporting tools can read
+// while the daemon writes, and take the write lock when a transaction begins
+// so that two writers cannot deadlock upgrading a read lock. Read-only
+// databases are opened with mode=ro and never change the journal mode.
+func sqliteDSN(path string, readOnly bool) string {
+	if path == ":memory:" {
+		return path
+	}
+
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(path)
+	params := fmt.Sprintf("_busy_timeout=%d", sqliteBusyTimeout.Milliseconds())
+	if readOnly {
+		params += "&mode=ro"
+	} else {
+		params += "&_journal_mode=WAL&_txlock=immediate"
+	}
+
+	return "file:" + escaped + "?" + params
+}
+
+// isLocked reports whether err is SQLite's busy or locked error.
+func isLocked(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
+
+// isSQLiteRejected reports whether err is a SQLite error that retrying the
+// same statement cannot fix: a constraint violation or data that does not
+// fit its column.
+func isSQLiteRejected(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrConstraint, sqlite3.ErrMismatch, sqlite3.ErrTooBig, sqlite3.ErrRange:
+		return true
+	}
+	return false
+}
+
+// withRetry runs write, retrying while the database reports a lock held by
+// another connection. The busy timeout already covers most contention; the
+// retries absorb the cases SQLite reports immediately, such as a WAL
+// checkpoint in progress.
+func (s *Storage) withRetry(write func() error) error {
+	if s.readOnly {
+		return ErrReadOnly
+	}
+
+	delay := writeRetryDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = write()
+		if err == nil || attempt == writeRetries || !isLocked(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestConcurrentWriterAndReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.db")
+
+	writer, err := New(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer writer.Close()
+
+	const (
+		writes  = 200
+		readers = 4
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writes+readers)
+	done := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < writes; i++ {
+			rec := BenchmarkRecord{
+				RunID:      fmt.Sprintf("run-%d", i/4),
+				Name:       "Sum1to100",
+				Passed:     true,
+				TokensUsed: 10,
+				Duration:   time.Second,
+				Quote:      "quote",
+				Output:     "5050",
+				Timestamp:  time.Now(),
+			}
+			if err := writer.InsertRecord(rec); err != nil {
+				errs <- fmt.Errorf("write %d: %w", i, err)
+				return
+			}
+		}
+	}()
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+
+			reader, err := NewReadOnly(path)
+			if err != nil {
+				errs <- fmt.Errorf("reader %d open: %w", r, err)
+				return
+			}
+			defer reader.Close()
+
+			for {
+				if _, _, _, err := reader.GetRollingStats("", "Sum1to100", 10); err != nil {
+					errs <- fmt.Errorf("reader %d stats: %w", r, err)
+					return
+				}
+				if _, err := reader.ListRuns("", 5); err != nil {
+					errs <- fmt.Errorf("reader %d runs: %w", r, err)
+					return
+				}
+
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}(r)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	runs, err := writer.ListRuns("", writes)
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != writes/4 {
+		t.Errorf("Expected %d runs, got %d", writes/4, len(runs))
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.db")
+
+	// Two independent handles on the same file, as when ripleyctl run
+	// executes while the daemon is writing.
+	var stores []*Storage
+	for i := 0; i < 2; i++ {
+		s, err := New(path)
+		if err != nil {
+			t.Fatalf("Failed to create storage: %v", err)
+		}
+		defer s.Close()
+		stores = append(stores, s)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i, s := range stores {
+		wg.Add(1)
+		go func(i int, s *Storage) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				rec := BenchmarkRecord{Name: fmt.Sprintf("writer-%d", i), Passed: true, Timestamp: time.Now()}
+				if err := s.InsertRecord(rec); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ripley.db")
+
+	s, err := New(path)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	ro, err := NewReadOnly(path)
+	if err != nil {
+		t.Fatalf("Failed to open read-only storage: %v", err)
+	}
+	defer ro.Close()
+
+	err = ro.InsertRecord(BenchmarkRecord{Name: "A", Timestamp: time.Now()})
+	if !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestReadOnlyMissingDatabase(t *testing.T) {
+	if _, err := NewReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("Expected error opening a missing database read-only, got nil")
+	}
+}
+
+func TestWALMode(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "ripley.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	var mode string
+	if err := s.db.QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil {
+		t.Fatalf("Failed to read journal mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("Expected journal_mode=wal, got %s", mode)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	s := &Storage{}
+
+	attempts := 0
+	err := s.withRetry(func() error {
+		attempts++
+		if attempts < 3 {
+			return sqlite3.Error{Code: sqlite3.ErrBusy}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got err=%v attempts=%d", err, attempts)
+	}
+
+	attempts = 0
+	err = s.withRetry(func() error {
+		attempts++
+		return errors.New("constraint failed")
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected non-lock error to fail without retry, got err=%v attempts=%d", err, attempts)
+	}
+}
+
+func TestInsertRecordRejected(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "ripley.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	// Stands in for a constraint the record violates
+	_, err = s.db.Exec(`CREATE TRIGGER reject_bad BEFORE INSERT ON benchmarks WHEN NEW.name = 'Bad'
+		BEGIN SELECT RAISE(ABORT, 'bad benchmark'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.InsertRecord(BenchmarkRecord{Name: "Bad", Timestamp: time.Now()})
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected ErrRejected, got %v", err)
+	}
+	if err := s.InsertRecord(BenchmarkRecord{Name: "Good", Timestamp: time.Now()}); err != nil {
+		t.Errorf("Expected the other record to be stored, got %v", err)
+	}
+}
@@ -1,9 +1,18 @@
-// Package storage provides SQLite-based persistence for benchmark results.
+// Package storage provides persistence for benchmark results.
+//
+// Results are written through the Store interface, which is implemented by
+// the SQLite-backed Storage (the default), a Postgres-backed Storage and an
+// in-memory MemoryStore intended for tests.
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -11,51 +20,257 @@ import (
 
 // BenchmarkRecord represents a single benchmark execution result.
 type BenchmarkRecord struct {
-	Name       string
-	Passed     bool
-	TokensUsed int
-	Duration   time.Duration
-	Quote      string
-	Output     string
-	Timestamp  time.Time
+	ID          int64
+	RunID       string // Groups the records produced by one benchmark cycle
+	Name        string
+	Model       string // Model the benchmark was run against
+	Passed      bool
+	TokensUsed  int
+	Duration    time.Duration
+	EffortScore float64 // Composite effort score, 0-100
+	Effort      string  // "good", "medium" or "poor"; empty for results stored before scoring
+	ErrorClass  string  // Why a failed run failed, e.g. "timeout"; empty for passed runs
+	Quote       string
+	Output      string
+	Timestamp   time.Time
+	Transcript  *Transcript `json:",omitempty"` // Full exchange; only loaded by GetRecord
 }
 
-// Storage wraps a SQLite database connection for benchmark data.
-type Storage struct {
-	db *sql.DB
+// RunSummary aggregates the records that share a run ID.
+type RunSummary struct {
+	RunID     string
+	Model     string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Total     int
+	Passed    int
+	Label     string // Cycle label, see Cycle; empty if the run was not classified
+}
+
+// RecordFilter selects records for ListRecords. Zero fields do not filter.
+type RecordFilter struct {
+	Name  string
+	Model string
+	Since time.Time // Inclusive
+	Until time.Time // Exclusive
+	Limit int       // Keep only the newest Limit records
+}
+
+// Store is implemented by every storage backend.
+//
+// Several daemons, each benchmarking its own model, can share one store.
+// Methods that take a model only see that model's results and the state
+// derived from them; an empty model matches every model.
+type Store interface {
+	// InsertRecord saves a benchmark result.
+	InsertRecord(record BenchmarkRecord) error
+
+	// GetRollingStats computes aggregate statistics for a benchmark over the last N runs
+	// of model. Returns average tokens used, average duration in seconds and pass rate
+	// (0.0-1.0). Runs from cycles labeled as outages are left out.
+	GetRollingStats(model, benchmarkName string, window int) (avgTokens, avgDuration, passRate float64, err error)
+
+	// ListRuns returns summaries of the most recent runs of model, newest first.
+	ListRuns(model string, limit int) ([]RunSummary, error)
+
+	// GetRun returns the records belonging to a run in insertion order.
+	GetRun(runID string) ([]BenchmarkRecord, error)
+
+	// GetRecord returns a single record, including its transcript if one was
+	// stored. Returns ErrNotFound if there is no record with that ID.
+	GetRecord(id int64) (BenchmarkRecord, error)
+
+	// ListRecords returns the records matching filter, oldest first.
+	ListRecords(filter RecordFilter) ([]BenchmarkRecord, error)
+
+	// InsertChangePoint saves a detected change point. It returns false if
+	// the same change point (benchmark, metric and time) is already stored.
+	InsertChangePoint(cp ChangePoint) (bool, error)
+
+	// ListChangePoints returns stored change points of model, newest first.
+	// An empty benchmark matches all benchmarks.
+	ListChangePoints(model, benchmark string, since time.Time) ([]ChangePoint, error)
+
+	// PinBaseline saves a named baseline, replacing one with the same name.
+	PinBaseline(b Baseline) error
+
+	// GetBaseline returns the named baseline, or ErrNotFound.
+	GetBaseline(name string) (Baseline, error)
+
+	// ListBaselines returns all pinned baselines ordered by name.
+	ListBaselines() ([]Baseline, error)
+
+	// InsertAnswerDrift saves detected answer drift. It returns false if the
+	// same drift (benchmark, kind, answer and time) is already stored.
+	InsertAnswerDrift(d AnswerDrift) (bool, error)
+
+	// ListAnswerDrift returns stored answer drift of model, newest first. An
+	// empty benchmark matches all benchmarks.
+	ListAnswerDrift(model, benchmark string, since time.Time) ([]AnswerDrift, error)
+
+	// SaveCycle stores the classification of a cycle, replacing any earlier one.
+	SaveCycle(c Cycle) error
+
+	// GetCycle returns the classification of a cycle, or ErrNotFound.
+	GetCycle(runID string) (Cycle, error)
+
+	// ListCycles returns the cycles of model classified since since, newest first.
+	ListCycles(model string, since time.Time) ([]Cycle, error)
+
+	// SaveFlakiness stores the assessment of a benchmark, replacing the previous one.
+	SaveFlakiness(f Flakiness) error
+
+	// ListFlakiness returns the last assessment of every benchmark of model, by name.
+	ListFlakiness(model string) ([]Flakiness, error)
+
+	// SaveAlert stores the state of an alert, replacing the previous one.
+	SaveAlert(a Alert) error
+
+	// ListAlerts returns the state of every alert of model, by rule and benchmark.
+	ListAlerts(model string) ([]Alert, error)
+
+	// InsertIncident saves a new incident and returns its ID.
+	InsertIncident(inc Incident) (int64, error)
+
+	// UpdateIncident saves the end, affected benchmarks and error classes of
+	// an existing incident.
+	UpdateIncident(inc Incident) error
+
+	// GetIncident returns an incident, or ErrNotFound.
+	GetIncident(id int64) (Incident, error)
+
+	// ListIncidents returns the incidents of model that were open at any time
+	// since since, newest first.
+	ListIncidents(model string, since time.Time) ([]Incident, error)
+
+	// InsertIncidentEvent adds an entry to the timeline of an incident.
+	InsertIncidentEvent(e IncidentEvent) error
+
+	// ListIncidentEvents returns the timeline of an incident, oldest first.
+	ListIncidentEvents(incidentID int64) ([]IncidentEvent, error)
+
+	// Ping checks that the store can be read, for health checks.
+	Ping(ctx context.Context) error
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// ErrRejected is returned, wrapping the database error, when a record can
+// never be inserted as it is, e.g. because it violates a constraint. Other
+// insert errors may succeed on a later attempt.
+var ErrRejected = errors.New("record rejected")
+
+// Open returns the Store selected by dsn:
+//
+//   - "postgres://..." or "postgresql://..." opens a Postgres database
+//   - "memory:" opens an empty in-memory store
+//   - anything else is treated as a SQLite database path
+func Open(dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return NewPostgres(dsn)
+	case dsn == "memory:":
+		return NewMemory(), nil
+	default:
+		return New(dsn)
+	}
+}
+
+// OpenReadOnly is like Open but the returned store rejects writes and never
+// migrates the schema. Reporting tools use it to read a database that the
+// daemon is writing to at the same time.
+func OpenReadOnly(dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return NewPostgresReadOnly(dsn)
+	case dsn == "memory:":
+		return NewMemory(), nil
+	default:
+		return NewReadOnly(dsn)
+	}
+}
+
+// Redact returns dsn with any password masked, for logging. A Postgres DSN
+// that cannot be parsed is reduced to its scheme.
+func Redact(dsn string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn[:strings.Index(dsn, "://")+3] + "..."
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
 }
 
-const schema = `
-CREATE TABLE IF NOT EXISTS benchmarks (
-	id INTEGER PRIMARY KEY AUTOINCREMENT,
-	name TEXT NOT NULL,
-	passed BOOLEAN NOT NULL,
-	tokens_used INTEGER NOT NULL,
-	duration_ms INTEGER NOT NULL,
-	quote TEXT NOT NULL,
-	output TEXT,
-	timestamp DATETIME NOT NULL
-);
+// dialect identifies the SQL flavour spoken by a Storage.
+type dialect int
 
-CREATE INDEX IF NOT EXISTS idx_benchmarks_name ON benchmarks(name);
-CREATE INDEX IF NOT EXISTS idx_benchmarks_timestamp ON benchmarks(timestamp);
-`
+const (
+	dialectSQLite dialect = iota
+	dialectPostgres
+)
+
+// Storage is a Store backed by a SQL database (SQLite or Postgres).
+type Storage struct {
+	db       *sql.DB
+	dialect  dialect
+	readOnly bool
+}
 
 // New creates or opens a SQLite database at the given path and initializes the schema.
 // Returns a Storage instance ready for use.
 func New(dbPath string) (*Storage, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", sqliteDSN(dbPath, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Every connection to ":memory:" gets its own database, so pin the pool
+	// to a single connection to keep the schema and data visible.
+	if dbPath == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	s := &Storage{db: db, dialect: dialectSQLite}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewReadOnly opens an existing SQLite database without write access.
+// The database must already be at SchemaVersion.
+func NewReadOnly(dbPath string) (*Storage, error) {
+	db, err := sql.Open("sqlite3", sqliteDSN(dbPath, true))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Initialize schema
-	if _, err := db.Exec(schema); err != nil {
+	s := &Storage{db: db, dialect: dialectSQLite, readOnly: true}
+	if err := s.checkVersion(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		return nil, err
 	}
 
-	return &Storage{db: db}, nil
+	return s, nil
+}
+
+// Ping reads the schema version, which takes a connection to the database and
+// fails if the file or server is unreadable.
+func (s *Storage) Ping(ctx context.Context) error {
+	var version int
+	return s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
 }
 
 // Close closes the underlying database connection.
@@ -63,49 +278,113 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-// InsertRecord saves a benchmark result to the database.
+// rebind rewrites the '?' placeholders in query into the form expected by
+// the storage dialect.
+func (s *Storage) rebind(query string) string {
+	if s.dialect != dialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// InsertRecord saves a benchmark result, and its transcript if present, to the database.
 func (s *Storage) InsertRecord(record BenchmarkRecord) error {
+	err := s.withRetry(func() error {
+		return s.insertRecord(record)
+	})
+
+	if isSQLiteRejected(err) || isPostgresRejected(err) {
+		return fmt.Errorf("failed to insert record: %w: %w", ErrRejected, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert record: %w", err)
+	}
+
+	return nil
+}
+
+// insertRecord writes record in a single transaction.
+func (s *Storage) insertRecord(record BenchmarkRecord) error {
 	query := `
-		INSERT INTO benchmarks (name, passed, tokens_used, duration_ms, quote, output, timestamp)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO benchmarks (run_id, name, model, passed, tokens_used, duration_ms, effort_score, effort, error_class, quote, output, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
 
-	_, err := s.db.Exec(
-		query,
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(
+		s.rebind(query),
+		record.RunID,
 		record.Name,
+		record.Model,
 		record.Passed,
 		record.TokensUsed,
 		record.Duration.Milliseconds(),
+		record.EffortScore,
+		record.Effort,
+		record.ErrorClass,
 		record.Quote,
 		record.Output,
-		record.Timestamp,
-	)
-
+		record.Timestamp.UTC(),
+	).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("failed to insert record: %w", err)
+		return err
 	}
 
-	return nil
+	if record.Transcript != nil {
+		if err := s.insertTranscript(tx, id, record.Transcript); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-// GetRollingStats computes aggregate statistics for a benchmark over the last N runs.
-// Returns average tokens used, average duration in seconds, pass rate (0.0-1.0), and any error.
-func (s *Storage) GetRollingStats(benchmarkName string, window int) (avgTokens, avgDuration, passRate float64, err error) {
+// GetRollingStats computes aggregate statistics for a benchmark over the last N runs
+// of model. Returns average tokens used, average duration in seconds, pass rate
+// (0.0-1.0), and any error. Runs from cycles labeled as outages are left out.
+func (s *Storage) GetRollingStats(model, benchmarkName string, window int) (avgTokens, avgDuration, passRate float64, err error) {
+	args := []any{benchmarkName}
+	byModel := ""
+	if model != "" {
+		byModel = `AND model = ?`
+		args = append(args, model)
+	}
+	args = append(args, window)
+
 	query := `
 		SELECT
 			COALESCE(AVG(tokens_used), 0) as avg_tokens,
 			COALESCE(AVG(duration_ms), 0) as avg_duration_ms,
-			COALESCE(AVG(CASE WHEN passed = 1 THEN 1.0 ELSE 0.0 END), 0) as pass_rate
+			COALESCE(AVG(CASE WHEN passed THEN 1.0 ELSE 0.0 END), 0) as pass_rate
 		FROM (
 			SELECT tokens_used, duration_ms, passed
 			FROM benchmarks
-			WHERE name = ?
-			ORDER BY timestamp DESC
+			WHERE name = ? ` + byModel + `
+				AND run_id NOT IN (SELECT run_id FROM cycles WHERE label = 'outage')
+			ORDER BY timestamp DESC, id DESC
 			LIMIT ?
-		)
+		) recent
 	`
 
-	row := s.db.QueryRow(query, benchmarkName, window)
+	row := s.db.QueryRow(s.rebind(query), args...)
 
 	var avgDurationMs float64
 	err = row.Scan(&avgTokens, &avgDurationMs, &passRate)
@@ -118,3 +397,214 @@ func (s *Storage) GetRollingStats(benchmarkName string, window int) (avgTokens,
 
 	return avgTokens, avgDuration, passRate, nil
 }
+
+// ListRuns returns summaries of the most recent runs of model, newest first.
+// Records inserted without a run ID are not part of any run.
+func (s *Storage) ListRuns(model string, limit int) ([]RunSummary, error) {
+	var args []any
+	byModel := ""
+	if model != "" {
+		byModel = `AND model = ?`
+		args = append(args, model)
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT
+			run_id,
+			MIN(model),
+			MIN(timestamp),
+			MAX(timestamp),
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN passed THEN 1 ELSE 0 END), 0),
+			COALESCE((SELECT label FROM cycles WHERE cycles.run_id = benchmarks.run_id), '')
+		FROM benchmarks
+		WHERE run_id <> '' ` + byModel + `
+		GROUP BY run_id
+		ORDER BY MIN(timestamp) DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []RunSummary
+	for rows.Next() {
+		var run RunSummary
+		var started, ended timeValue
+		if err := rows.Scan(&run.RunID, &run.Model, &started, &ended, &run.Total, &run.Passed, &run.Label); err != nil {
+			return nil, fmt.Errorf("failed to scan run: %w", err)
+		}
+		run.StartedAt, run.EndedAt = started.Time, ended.Time
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// recordColumns lists the benchmarks columns read by scanRecord, in order.
+const recordColumns = `id, run_id, name, model, passed, tokens_used, duration_ms, effort_score, effort, error_class, quote, COALESCE(output, ''), timestamp`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRecord reads a row selected with recordColumns.
+func scanRecord(row rowScanner) (BenchmarkRecord, error) {
+	var rec BenchmarkRecord
+	var durationMs int64
+	err := row.Scan(&rec.ID, &rec.RunID, &rec.Name, &rec.Model, &rec.Passed, &rec.TokensUsed,
+		&durationMs, &rec.EffortScore, &rec.Effort, &rec.ErrorClass, &rec.Quote, &rec.Output, &rec.Timestamp)
+	rec.Duration = time.Duration(durationMs) * time.Millisecond
+	return rec, err
+}
+
+// GetRun returns the records belonging to a run in insertion order.
+func (s *Storage) GetRun(runID string) ([]BenchmarkRecord, error) {
+	query := `SELECT ` + recordColumns + ` FROM benchmarks WHERE run_id = ? ORDER BY id`
+
+	rows, err := s.db.Query(s.rebind(query), runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run: %w", err)
+	}
+	defer rows.Close()
+
+	var records []BenchmarkRecord
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query run: %w", err)
+	}
+
+	return records, nil
+}
+
+// ListRecords returns the records matching filter, oldest first.
+func (s *Storage) ListRecords(filter RecordFilter) ([]BenchmarkRecord, error) {
+	query := `SELECT ` + recordColumns + ` FROM benchmarks WHERE 1 = 1`
+	var args []any
+
+	if filter.Name != "" {
+		query += ` AND name = ?`
+		args = append(args, filter.Name)
+	}
+	if filter.Model != "" {
+		query += ` AND model = ?`
+		args = append(args, filter.Model)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND timestamp < ?`
+		args = append(args, filter.Until.UTC())
+	}
+	query += ` ORDER BY timestamp DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []BenchmarkRecord
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query records: %w", err)
+	}
+
+	// Selected newest first so LIMIT keeps the latest; return oldest first
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// GetRecord returns a single record with its transcript.
+func (s *Storage) GetRecord(id int64) (BenchmarkRecord, error) {
+	query := `SELECT ` + recordColumns + ` FROM benchmarks WHERE id = ?`
+
+	rec, err := scanRecord(s.db.QueryRow(s.rebind(query), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return BenchmarkRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return BenchmarkRecord{}, fmt.Errorf("failed to query record: %w", err)
+	}
+
+	rec.Transcript, err = s.loadTranscript(id)
+	if err != nil {
+		return BenchmarkRecord{}, err
+	}
+
+	return rec, nil
+}
+
+// timeValue scans timestamps produced by aggregate functions. SQLite loses
+// the column type for MIN/MAX and returns the stored text, while Postgres
+// returns a time.Time.
+type timeValue struct {
+	Time time.Time
+}
+
+// sqliteTimeFormats lists the layouts go-sqlite3 uses when storing time.Time.
+var sqliteTimeFormats = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// Scan implements sql.Scanner.
+func (t *timeValue) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported timestamp type %T", src)
+	}
+}
+
+func (t *timeValue) parse(s string) error {
+	s = strings.TrimSuffix(s, "Z")
+	for _, layout := range sqliteTimeFormats {
+		if parsed, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognized timestamp %q", s)
+}
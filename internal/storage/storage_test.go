@@ -64,7 +64,7 @@ func TestGetRollingStats(t *testing.T) {
 	}
 
 	// Test rolling stats
-	avgTokens, avgDuration, passRate, err := db.GetRollingStats(benchmarkName, 10)
+	avgTokens, avgDuration, passRate, err := db.GetRollingStats("", benchmarkName, 10)
 	if err != nil {
 		t.Fatalf("Failed to get rolling stats: %v", err)
 	}
@@ -111,7 +111,7 @@ func TestGetRollingStatsWithWindow(t *testing.T) {
 	}
 
 	// Request window of 3 (should only consider last 3 records)
-	avgTokens, _, passRate, err := db.GetRollingStats(benchmarkName, 3)
+	avgTokens, _, passRate, err := db.GetRollingStats("", benchmarkName, 3)
 	if err != nil {
 		t.Fatalf("Failed to get rolling stats: %v", err)
 	}
@@ -133,7 +133,7 @@ func TestGetRollingStatsNoData(t *testing.T) {
 	defer db.Close()
 
 	// Query for non-existent benchmark
-	avgTokens, avgDuration, passRate, err := db.GetRollingStats("NonExistent", 10)
+	avgTokens, avgDuration, passRate, err := db.GetRollingStats("", "NonExistent", 10)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
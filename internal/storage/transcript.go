@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Transcript is the full exchange behind a benchmark result, kept separately
+// from the trimmed BenchmarkRecord.Output.
+type Transcript struct {
+	Prompt string
+	Argv   []string // Rendered command line
+	Stdout string
+	Stderr string
+	Events []json.RawMessage // JSON events parsed from stdout, if any
+}
+
+// Transcript parts are stored as gzip-compressed blobs keyed by the SHA-256
+// of their uncompressed content, so identical prompts and answers across
+// runs are stored once. Empty parts have an empty hash and no blob.
+
+// blobHash returns the content hash of data, or "" for empty data.
+func blobHash(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func compressBlob(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressBlob(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// putBlob stores data unless a blob with the same hash exists and returns its hash.
+func (s *Storage) putBlob(tx *sql.Tx, data []byte) (string, error) {
+	hash := blobHash(data)
+	if hash == "" {
+		return "", nil
+	}
+
+	compressed, err := compressBlob(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress transcript: %w", err)
+	}
+
+	query := `
+		INSERT INTO transcript_blobs (hash, size, data) VALUES (?, ?, ?)
+		ON CONFLICT (hash) DO NOTHING
+	`
+	if _, err := tx.Exec(s.rebind(query), hash, len(data), compressed); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// getBlob returns the uncompressed content stored under hash.
+func (s *Storage) getBlob(hash string) ([]byte, error) {
+	if hash == "" {
+		return nil, nil
+	}
+
+	var compressed []byte
+	err := s.db.QueryRow(s.rebind(`SELECT data FROM transcript_blobs WHERE hash = ?`), hash).Scan(&compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript blob %s: %w", hash, err)
+	}
+
+	data, err := decompressBlob(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress transcript blob %s: %w", hash, err)
+	}
+	return data, nil
+}
+
+// insertTranscript stores t for the record with the given ID.
+func (s *Storage) insertTranscript(tx *sql.Tx, recordID int64, t *Transcript) error {
+	argv, err := json.Marshal(t.Argv)
+	if err != nil {
+		return err
+	}
+
+	var events []byte
+	if len(t.Events) > 0 {
+		if events, err = json.Marshal(t.Events); err != nil {
+			return err
+		}
+	}
+
+	var hashes [4]string
+	for i, part := range [][]byte{[]byte(t.Prompt), []byte(t.Stdout), []byte(t.Stderr), events} {
+		if hashes[i], err = s.putBlob(tx, part); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO transcripts (record_id, argv, prompt_hash, stdout_hash, stderr_hash, events_hash)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.Exec(s.rebind(query), recordID, string(argv), hashes[0], hashes[1], hashes[2], hashes[3])
+	return err
+}
+
+// loadTranscript returns the transcript of a record, or nil if none was stored.
+func (s *Storage) loadTranscript(recordID int64) (*Transcript, error) {
+	query := `
+		SELECT argv, prompt_hash, stdout_hash, stderr_hash, events_hash
+		FROM transcripts
+		WHERE record_id = ?
+	`
+
+	var argv string
+	var hashes [4]string
+	err := s.db.QueryRow(s.rebind(query), recordID).Scan(&argv, &hashes[0], &hashes[1], &hashes[2], &hashes[3])
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transcript: %w", err)
+	}
+
+	t := &Transcript{}
+	if err := json.Unmarshal([]byte(argv), &t.Argv); err != nil {
+		return nil, fmt.Errorf("failed to decode transcript argv: %w", err)
+	}
+
+	var parts [4][]byte
+	for i, hash := range hashes {
+		if parts[i], err = s.getBlob(hash); err != nil {
+			return nil, err
+		}
+	}
+	t.Prompt, t.Stdout, t.Stderr = string(parts[0]), string(parts[1]), string(parts[2])
+
+	if len(parts[3]) > 0 {
+		if err := json.Unmarshal(parts[3], &t.Events); err != nil {
+			return nil, fmt.Errorf("failed to decode transcript events: %w", err)
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetRecordTranscript(t *testing.T) {
+	for name, open := range storeFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+
+			transcript := &Transcript{
+				Prompt: "Calculate the sum of integers from 1 to 100.",
+				Argv:   []string{"claude", "--model", "Sonnet"},
+				Stdout: "{\"type\":\"result\"}\n5050\n",
+				Stderr: "warning: slow network\n",
+				Events: []json.RawMessage{json.RawMessage(`{"type":"result"}`)},
+			}
+			rec := BenchmarkRecord{RunID: "run-1", Name: "Sum1to100", Passed: true, Output: "5050", Timestamp: time.Now(), Transcript: transcript}
+			if err := s.InsertRecord(rec); err != nil {
+				t.Fatalf("Failed to insert record: %v", err)
+			}
+
+			run, err := s.GetRun("run-1")
+			if err != nil || len(run) != 1 {
+				t.Fatalf("Failed to get run: %v (%d records)", err, len(run))
+			}
+			if run[0].Transcript != nil {
+				t.Error("Expected GetRun to omit transcripts")
+			}
+
+			got, err := s.GetRecord(run[0].ID)
+			if err != nil {
+				t.Fatalf("Failed to get record: %v", err)
+			}
+			if got.Name != "Sum1to100" || got.Output != "5050" {
+				t.Errorf("Unexpected record: %+v", got)
+			}
+			if got.Transcript == nil {
+				t.Fatal("Expected transcript to be loaded")
+			}
+			if got.Transcript.Prompt != transcript.Prompt || got.Transcript.Stdout != transcript.Stdout ||
+				got.Transcript.Stderr != transcript.Stderr || !reflect.DeepEqual(got.Transcript.Argv, transcript.Argv) {
+				t.Errorf("Transcript mismatch: %+v", got.Transcript)
+			}
+			if len(got.Transcript.Events) != 1 || string(got.Transcript.Events[0]) != `{"type":"result"}` {
+				t.Errorf("Unexpected events: %s", got.Transcript.Events)
+			}
+
+			if _, err := s.GetRecord(run[0].ID + 1000); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTranscriptBlobsAreDeduplicated(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "ripley.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		rec := BenchmarkRecord{
+			Name:      "Sum1to100",
+			Passed:    true,
+			Timestamp: time.Now(),
+			Transcript: &Transcript{
+				Prompt: "Calculate the sum of integers from 1 to 100.",
+				Argv:   []string{"claude"},
+				Stdout: "5050",
+			},
+		}
+		if err := s.InsertRecord(rec); err != nil {
+			t.Fatalf("Failed to insert record: %v", err)
+		}
+	}
+
+	var blobs, transcripts int
+	s.db.QueryRow(`SELECT COUNT(*) FROM transcript_blobs`).Scan(&blobs)
+	s.db.QueryRow(`SELECT COUNT(*) FROM transcripts`).Scan(&transcripts)
+
+	// One blob for the prompt and one for "5050"; empty stderr is not stored
+	if blobs != 2 {
+		t.Errorf("Expected 2 blobs, got %d", blobs)
+	}
+	if transcripts != 3 {
+		t.Errorf("Expected 3 transcripts, got %d", transcripts)
+	}
+}
+
+func TestBlobCompressionRoundTrip(t *testing.T) {
+	data := []byte("The answer is 5050. The answer is 5050. The answer is 5050.")
+
+	compressed, err := compressBlob(data)
+	if err != nil {
+		t.Fatalf("compressBlob failed: %v", err)
+	}
+	got, err := decompressBlob(compressed)
+	if err != nil {
+		t.Fatalf("decompressBlob failed: %v", err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("Round trip mismatch: %q", got)
+	}
+
+	if blobHash(nil) != "" {
+		t.Error("Expected empty hash for empty data")
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"time"
 
+	"github.com/cryptopatrick/ripley/internal/alert"
+	"github.com/cryptopatrick/ripley/internal/analysis"
 	"github.com/cryptopatrick/ripley/internal/checker"
 	"github.com/cryptopatrick/ripley/internal/config"
+	"github.com/cryptopatrick/ripley/internal/health"
+	"github.com/cryptopatrick/ripley/internal/hooks"
+	"github.com/cryptopatrick/ripley/internal/logging"
+	"github.com/cryptopatrick/ripley/internal/metrics"
+	"github.com/cryptopatrick/ripley/internal/notify"
 	"github.com/cryptopatrick/ripley/internal/storage"
 )
 
@@ -16,56 +27,452 @@ func main() {
 	var cfg *config.Config
 	configPath := "config.yaml"
 
+	loaded := false
 	if _, err := os.Stat(configPath); err == nil {
 		cfg, err = config.Load(configPath)
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
-		fmt.Printf("Loaded configuration from %s\n", configPath)
+		loaded = true
 	} else {
 		cfg = config.LoadWithDefaults()
-		fmt.Println("Using default configuration (config.yaml not found)")
+	}
+
+	// Everything logged from here on, including by the log package, goes
+	// through the configured logger
+	logger, logFile, err := logging.New(cfg)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
+	slog.SetDefault(logger)
+
+	if loaded {
+		slog.Info("Loaded configuration", "path", configPath)
+	} else {
+		slog.Info("Using default configuration, config.yaml not found")
 	}
 
 	interval, err := cfg.GetInterval()
 	if err != nil {
-		log.Fatalf("Invalid interval configuration: %v", err)
+		fatal("Invalid interval configuration", err)
+	}
+
+	backupInterval, err := cfg.GetBackupInterval()
+	if err != nil {
+		fatal("Invalid backup configuration", err)
+	}
+
+	slos, err := analysis.NewSLOEvaluator(cfg)
+	if err != nil {
+		fatal("Invalid SLO configuration", err)
+	}
+
+	flakyDetector := analysis.NewFlakyDetector(cfg)
+
+	rules, err := analysis.NewRules(cfg)
+	if err != nil {
+		fatal("Invalid alert rules", err)
+	}
+
+	notifier, err := notify.FromConfig(cfg)
+	if err != nil {
+		fatal("Invalid notify configuration", err)
+	}
+
+	// Hooks run on the same alert and incident events as notifications
+	runner, err := hooks.NewRunner(cfg)
+	if err != nil {
+		fatal("Invalid hooks configuration", err)
+	}
+	notifier = append(notifier, runner)
+
+	loc, err := cfg.GetLocation()
+	if err != nil {
+		fatal("Invalid analysis configuration", err)
+	}
+	digester, err := notify.NewDigester(cfg, loc, time.Now())
+	if err != nil {
+		fatal("Invalid notify configuration", err)
 	}
 
 	// Initialize storage
-	db, err := storage.New(cfg.Daemon.DBPath)
+	store, err := storage.Open(cfg.Daemon.DBPath)
+	if err != nil {
+		fatal("Failed to initialize database", err)
+	}
+	defer store.Close()
+
+	db, err := storage.NewSpool(store, cfg.Spool.Path)
+	if err != nil {
+		fatal("Failed to initialize spool", err)
+	}
+
+	alerts, err := alert.NewManager(cfg, db, notifier)
+	if err != nil {
+		fatal("Invalid alerts configuration", err)
+	}
+
+	monitor, err := health.NewMonitor(cfg, db, interval)
+	if err != nil {
+		fatal("Invalid health configuration", err)
+	}
+	heartbeat, err := health.NewHeartbeat(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		fatal("Invalid health configuration", err)
+	}
+
+	daemonMetrics := metrics.NewDaemon(cfg.Claude.Model, checker.BenchmarkNames(), db)
+	if cfg.Server.Listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", daemonMetrics.Registry.Handler())
+		mux.Handle("/healthz", monitor.HealthHandler())
+		mux.Handle("/readyz", monitor.ReadyHandler())
+		if err := serve(cfg.Server.Listen, mux); err != nil {
+			fatal("Failed to start HTTP server", err)
+		}
+		slog.Info("Serving metrics and health checks", "url", listenURL(cfg.Server.Listen))
 	}
-	defer db.Close()
 
-	fmt.Printf("Ripley daemon started with %s...\n", cfg.Claude.Model)
-	fmt.Printf("Database: %s | Interval: %v\n\n", cfg.Daemon.DBPath, interval)
+	slog.Info("Ripley daemon started", "model", cfg.Claude.Model, "database", storage.Redact(cfg.Daemon.DBPath), "interval", interval.String())
 
+	var lastBackup time.Time
 	for {
-		fmt.Println("=== Running Claude Code Liveness & Effort Check ===")
-		results := checker.RunBenchmarks(db)
-		checker.PrintResults(results)
+		slog.Info("Running Claude Code liveness and effort check")
+		cycleStart := time.Now()
+		monitor.CycleStarted()
+		results := checker.RunBenchmarks(db, cfg.Claude.Model, checker.NewEffortScorer(cfg))
+		daemonMetrics.ObserveResults(results, time.Now())
+
+		checker.LogResults(slog.Default(), results)
+
+		// Every record of the cycle carries its run ID
+		cycleLog := slog.Default()
+		if len(results) > 0 {
+			cycleLog = cycleLog.With("run_id", results[0].RunID)
+		}
+
+		// Notifications give up rather than hold up the next cycle
+		notifyCtx, cancelNotify := context.WithTimeout(context.Background(), interval)
+
+		// Quarantined benchmarks still run but are left out of alerts
+		quarantined, err := analysis.Quarantined(db, cfg.Claude.Model)
+		if err != nil {
+			cycleLog.Error("Failed to load quarantined benchmarks", "error", err)
+			quarantined = make(map[string]bool)
+		}
+
+		// Label the cycle before the stats, which leave outages out
+		classifier := analysis.CycleClassifier{OutageShare: *cfg.Cycles.OutageShare, Quarantined: quarantined}
+		var cycle storage.Cycle
+		if len(results) > 0 {
+			cycle, err = analysis.ClassifyRun(db, classifier, results[0].RunID)
+			if err != nil {
+				cycleLog.Error("Failed to classify cycle", "error", err)
+				cycle = storage.Cycle{}
+			} else if cycle.Label != storage.CycleHealthy {
+				cycleLog.Warn("Cycle not healthy", "label", cycle.Label, "summary", analysis.DescribeCycle(cycle))
+			}
+		}
+
+		// Reassess flakiness now that the cycle is labeled
+		flaky, err := analysis.UpdateFlakiness(db, cfg.Claude.Model, flakyDetector, checker.BenchmarkNames(), time.Now())
+		if err != nil {
+			cycleLog.Error("Failed to assess flakiness", "error", err)
+		}
+		for _, f := range flaky {
+			level := slog.LevelInfo
+			if f.Flaky {
+				level = slog.LevelWarn
+			}
+			cycleLog.Log(context.Background(), level, "Flakiness assessed", "benchmark", f.Benchmark,
+				"flaky", f.Flaky, "quarantined", f.Quarantined, "summary", analysis.DescribeFlakiness(f))
+			if f.Quarantined {
+				quarantined[f.Benchmark] = true
+			} else {
+				delete(quarantined, f.Benchmark)
+			}
+		}
+		active := analysis.Unquarantined(checker.BenchmarkNames(), quarantined)
 
-		// Show rolling statistics
-		fmt.Printf("\n=== Rolling Statistics (Last %d Runs) ===\n", cfg.Monitoring.RollingWindow)
+		// Log rolling statistics
+		quotes := make(map[string]string)
+		for _, r := range results {
+			quotes[r.Name] = r.Quote
+		}
+		stats := make(map[string]*notify.Stats)
 		for _, b := range checker.Benchmarks {
-			avgTokens, avgDuration, passRate, err := db.GetRollingStats(b.Name, cfg.Monitoring.RollingWindow)
+			avgTokens, avgDuration, passRate, err := db.GetRollingStats(cfg.Claude.Model, b.Name, cfg.Monitoring.RollingWindow)
 			if err != nil {
-				log.Printf("Error getting stats for %s: %v", b.Name, err)
+				cycleLog.Error("Failed to get rolling statistics", "benchmark", b.Name, "error", err)
 				continue
 			}
 
-			status := "✓"
-			if passRate < cfg.Monitoring.WarningThreshold {
-				status = "⚠"
+			daemonMetrics.ObserveStats(b.Name, avgTokens, avgDuration, passRate)
+			stats[b.Name] = &notify.Stats{
+				Window:      cfg.Monitoring.RollingWindow,
+				AvgTokens:   avgTokens,
+				AvgDuration: avgDuration,
+				PassRate:    passRate,
+			}
+			if previous, ok, err := analysis.PreviousPassRate(db, cfg.Claude.Model, b.Name, cfg.Monitoring.RollingWindow); err != nil {
+				cycleLog.Error("Failed to get previous pass rate", "benchmark", b.Name, "error", err)
+			} else if ok {
+				stats[b.Name].PreviousPassRate = &previous
+			}
+
+			// Outages excluded
+			level := slog.LevelInfo
+			if passRate < cfg.Monitoring.WarningThreshold && !quarantined[b.Name] {
+				level = slog.LevelWarn
+			}
+			cycleLog.Log(context.Background(), level, "Rolling statistics",
+				"benchmark", b.Name,
+				"window", cfg.Monitoring.RollingWindow,
+				"avg_tokens", avgTokens,
+				"avg_duration_seconds", avgDuration,
+				"pass_rate", passRate,
+				"quarantined", quarantined[b.Name])
+		}
+
+		// Evaluate the alert rules against the stored history
+		ruleResults, err := analysis.EvaluateRules(db, cfg.Claude.Model, rules, checker.BenchmarkNames(), quarantined, time.Now())
+		if err != nil {
+			cycleLog.Error("Failed to evaluate alert rules", "error", err)
+		}
+		var signals []alert.Signal
+		for _, res := range ruleResults {
+			summary := analysis.DescribeRuleResult(res)
+			if res.Firing {
+				cycleLog.Warn("Alert rule firing", "rule", res.Rule.Name, "benchmark", res.Benchmark, "value", res.Value, "summary", summary)
+			}
+			scope := res.Benchmark
+			if scope == "" {
+				scope = storage.ScopeSuite
+			}
+			signals = append(signals, alert.Signal{
+				Rule:      res.Rule.Name,
+				Benchmark: res.Benchmark,
+				Firing:    res.Firing,
+				Event: notify.Event{
+					Kind:      notify.KindDegraded,
+					Severity:  res.Rule.Severity,
+					Summary:   summary,
+					Tags:      []string{"benchmark", scope},
+					Labels:    res.Rule.Labels,
+					Benchmark: res.Benchmark,
+					Model:     cfg.Claude.Model,
+					Quote:     quotes[res.Benchmark],
+					Stats:     stats[res.Benchmark],
+				},
+			})
+		}
+
+		// Look for statistically significant shifts in the stored history
+		detector := analysis.RegressionDetector{
+			Confidence: *cfg.Regression.Confidence,
+			MinSegment: cfg.Regression.MinSegment,
+			Bootstraps: cfg.Regression.Bootstraps,
+			Seed:       1,
+		}
+		changes, err := analysis.DetectRegressions(db, cfg.Claude.Model, detector, active, cfg.Regression.Window, time.Now())
+		if err != nil {
+			cycleLog.Error("Failed to detect regressions", "error", err)
+		}
+		for _, cp := range changes {
+			cycleLog.Warn("Regression detected", "benchmark", cp.Benchmark, "metric", cp.Metric, "summary", analysis.DescribeChangePoint(cp))
+		}
+
+		// Flag new answer forms and changes of the dominant answer
+		drift, err := analysis.DetectAnswerDrift(db, cfg.Claude.Model, analysis.DriftDetector{Window: cfg.Drift.Window},
+			active, cfg.Drift.History, time.Now())
+		if err != nil {
+			cycleLog.Error("Failed to detect answer drift", "error", err)
+		}
+		for _, d := range drift {
+			cycleLog.Warn("Answer drift detected", "benchmark", d.Benchmark, "drift", d.Kind, "summary", analysis.DescribeDrift(d))
+		}
+
+		// Open incidents for sustained failures and resolve recovered ones
+		if len(results) > 0 {
+			tracker := analysis.IncidentTracker{
+				ConsecutiveFailures: *cfg.Incidents.ConsecutiveFailures,
+				SuiteFailureRate:    *cfg.Incidents.SuiteFailureRate,
+				Quarantined:         quarantined,
+			}
+			incidents, err := tracker.Update(db, results[0].RunID)
+			if err != nil {
+				cycleLog.Error("Failed to track incidents", "error", err)
+			}
+			for _, c := range incidents {
+				level, msg := slog.LevelWarn, "Incident opened"
+				event := notify.Event{Kind: notify.KindIncidentOpened, Severity: notify.SeverityCritical}
+				if c.Resolved {
+					// Resolutions go wherever the incident went
+					level, msg = slog.LevelInfo, "Incident resolved"
+					event = notify.Event{Kind: notify.KindIncidentResolved, Severity: notify.SeverityCritical}
+				}
+				event.Summary = analysis.DescribeIncident(c.Incident, time.Now())
+				cycleLog.Log(context.Background(), level, msg,
+					"incident_id", c.Incident.ID, "scope", c.Incident.Scope, "summary", event.Summary)
+
+				event.Tags = []string{"incident", c.Incident.Scope}
+				event.Model = cfg.Claude.Model
+				event.Incident = notify.NewIncident(c.Incident)
+				if c.Incident.Scope != storage.ScopeSuite {
+					event.Benchmark = c.Incident.Scope
+				}
+				send(notifyCtx, notifier, event)
+			}
+		}
+
+		// Warn while an SLO burns its error budget too fast
+		slos.Quarantined = quarantined
+		statuses, err := slos.Evaluate(db, cfg.Claude.Model, time.Now())
+		if err != nil {
+			cycleLog.Error("Failed to evaluate SLOs", "error", err)
+		}
+		for _, st := range statuses {
+			signal := alert.Signal{
+				Rule: "slo/" + st.Name,
+				Event: notify.Event{
+					Kind:      notify.KindSLOBurn,
+					Severity:  notify.SeverityWarning,
+					Summary:   fmt.Sprintf("SLO %s: error budget burn rates are within their thresholds, %.0f%% of budget left", st.Name, st.BudgetRemaining()*100),
+					Tags:      []string{"slo", st.Name},
+					Benchmark: st.Benchmark,
+					Model:     cfg.Claude.Model,
+				},
+			}
+			for i, burn := range st.Firing() {
+				summary := analysis.DescribeBurnAlert(st, burn)
+				cycleLog.Warn("SLO burning error budget", "slo", st.Name, "benchmark", st.Benchmark, "severity", burn.Severity, "summary", summary)
+
+				// The fastest alert firing describes the objective
+				if i == 0 {
+					signal.Firing = true
+					signal.Event.Summary = summary
+				}
+				if burn.Severity == "page" {
+					signal.Event.Severity = notify.SeverityCritical
+				}
+			}
+			signals = append(signals, signal)
+		}
+
+		// Notify alerts that started, resolved or started flapping
+		sent, err := alerts.Process(notifyCtx, signals, time.Now())
+		if err != nil {
+			cycleLog.Error("Notification failed", "error", err)
+		}
+		for _, e := range sent {
+			cycleLog.Info("Notification sent", "title", notify.Title(e), "kind", e.Kind, "severity", e.Severity, "benchmark", e.Benchmark, "summary", e.Summary)
+		}
+
+		// Email the daily and weekly digests that are due
+		if sent, err := digester.Send(notifyCtx, db, time.Now()); err != nil {
+			cycleLog.Error("Digest failed", "error", err)
+		} else if sent > 0 {
+			cycleLog.Info("Sent digests", "count", sent)
+		}
+
+		// Hand the cycle to shell hooks
+		if len(results) > 0 {
+			runner.Cycle(hooks.NewCycle(results, cycle, time.Now()))
+		}
+		daemonMetrics.ObserveCycle(cycle.Label, time.Since(cycleStart), time.Now())
+		if cfg.Metrics.Textfile != "" {
+			if err := daemonMetrics.Registry.WriteFile(cfg.Metrics.Textfile, cfg.Metrics.Format); err != nil {
+				cycleLog.Error("Failed to write metrics textfile", "path", cfg.Metrics.Textfile, "error", err)
+			}
+		}
+
+		// The cycle fails, for health checks and the heartbeat, if nothing
+		// was stored
+		failure := ""
+		if pending := db.Pending(); pending > 0 {
+			cycleLog.Warn("Results spooled, waiting for the database", "pending", pending, "path", cfg.Spool.Path)
+			failure = fmt.Sprintf("%d results spooled, the database is not accepting writes", pending)
+		}
+		beat := health.Beat{Time: time.Now(), Label: cycle.Label, Total: len(results), Duration: time.Since(cycleStart).Seconds()}
+		if len(results) > 0 {
+			beat.RunID = results[0].RunID
+		} else {
+			failure = "no benchmarks ran"
+		}
+		for _, r := range results {
+			if !r.Passed {
+				beat.Failed++
+			}
+		}
+		beat.OK, beat.Failure = failure == "", failure
+		monitor.CycleFinished(beat.RunID, failure)
+		if len(results) == 0 {
+			cycleLog.Warn("Cycle failed", "summary", failure)
+		}
+		cycleLog.Info("Cycle finished", "label", cycle.Label, "duration_seconds", beat.Duration)
+
+		if heartbeat != nil {
+			if err := heartbeat.Ping(notifyCtx, beat); err != nil {
+				cycleLog.Error("Heartbeat failed", "error", err)
 			}
+		}
+		cancelNotify()
 
-			fmt.Printf("%s %s | Avg Tokens: %.1f | Avg Duration: %.2fs | Pass Rate: %.0f%%\n",
-				status, b.Name, avgTokens, avgDuration, passRate*100)
+		if backupInterval > 0 && time.Since(lastBackup) >= backupInterval {
+			path, err := storage.BackupRotated(store, cfg.Backup.Dir, *cfg.Backup.Keep, time.Now())
+			if err != nil {
+				slog.Error("Backup failed", "error", err)
+			} else {
+				slog.Info("Database backed up", "path", path)
+			}
+			lastBackup = time.Now()
 		}
-		fmt.Println()
 
 		time.Sleep(interval)
 	}
 }
+
+// listenURL returns the URL to reach a listen address at, with localhost
+// for an address without a host such as ":9120".
+func listenURL(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		addr = net.JoinHostPort("localhost", port)
+	}
+	return "http://" + addr
+}
+
+// serve listens on addr and serves handler in the background. Listening
+// happens before it returns, so an address in use fails at startup.
+func serve(addr string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(ln); err != nil {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// send delivers e to every configured destination before ctx is done,
+// logging failures rather than holding up the next cycle.
+func send(ctx context.Context, n notify.Notifier, e notify.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if err := n.Notify(ctx, e); err != nil {
+		slog.Error("Notification failed", "kind", e.Kind, "error", err)
+	}
+}
+
+// fatal logs a startup error and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}